@@ -7,8 +7,11 @@ import (
 	"osp/internal/database"
 	"osp/internal/models"
 	"osp/internal/routes"
+	"osp/internal/services"
+	"time"
 
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -32,20 +35,36 @@ func main() {
 	}
 	asynqClient := asynq.NewClient(redisOpt)
 	defer asynqClient.Close()
+	asynqInspector := asynq.NewInspector(redisOpt)
+	defer asynqInspector.Close()
+	redisClient := redisOpt.MakeRedisClient().(redis.UniversalClient)
+	defer redisClient.Close()
 
 	// Start asynq worker
 	asynqServer := asynq.NewServer(redisOpt, asynq.Config{
 		Concurrency: 5,
 		Queues: map[string]int{
-			"insights": 1,
+			"insights":   1,
+			"sentiment":  1,
+			"embeddings": 1,
+		},
+		// Wait as long as the LLM provider asks before retrying rate-limited jobs.
+		RetryDelayFunc: func(n int, err error, task *asynq.Task) time.Duration {
+			if delay, ok := services.RetryAfter(err); ok {
+				return delay
+			}
+			return asynq.DefaultRetryDelayFunc(n, err, task)
 		},
 	})
 
 	mux := asynq.NewServeMux()
 	jobSystem := &models.JobSystem{
-		Client: asynqClient,
-		Server: asynqServer,
-		Mux:    mux,
+		Client:        asynqClient,
+		Inspector:     asynqInspector,
+		Server:        asynqServer,
+		Mux:           mux,
+		PeriodicTasks: &models.PeriodicTasks{},
+		Redis:         redisClient,
 	}
 
 	// Create Gin router
@@ -57,6 +76,21 @@ func main() {
 		}
 	}()
 
+	// Enqueue periodic tasks, re-reading their schedules at every sync
+	periodicTaskManager, err := asynq.NewPeriodicTaskManager(asynq.PeriodicTaskManagerOpts{
+		RedisConnOpt:               redisOpt,
+		PeriodicTaskConfigProvider: jobSystem.PeriodicTasks,
+		SyncInterval:               cfg.InsightScheduleSyncInterval,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create periodic task manager: %v", err)
+	}
+	go func() {
+		if err := periodicTaskManager.Run(); err != nil {
+			log.Printf("periodic task manager stopped: %v", err)
+		}
+	}()
+
 	// Start server
 	if err := router.Run(":" + cfg.Port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
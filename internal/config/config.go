@@ -1,7 +1,14 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"osp/internal/models"
 
 	"github.com/joho/godotenv"
 )
@@ -12,17 +19,255 @@ type Config struct {
 	DBUri       string
 	RedisUri    string
 	GitHubToken string
+
+	// InsightBatchConcurrency is the number of insight batches summarized in parallel
+	InsightBatchConcurrency int
+	// InsightTokenBudgets is the number of input tokens of an insight batch request, by model
+	InsightTokenBudgets map[string]int
+	// LLMPrices is the price of each model in USD per million tokens
+	LLMPrices map[string]models.ModelPrice
+	// LLMTokenBudget caps the LLM tokens used across all surveys; 0 is unlimited
+	LLMTokenBudget models.TokenBudget
+	// LLMCacheTTL is how long identical LLM requests are served from the cache; 0 disables it
+	LLMCacheTTL time.Duration
+	// LLMLogRetention is how long chat completion logs are kept; 0 keeps them forever
+	LLMLogRetention time.Duration
+	// PIIRedactionTerms are redacted from the textbox answers of surveys with redaction enabled
+	PIIRedactionTerms []string
+	// SentimentAnalyzer scores textbox answers: "llm" with the lexicon as fallback, or "lexicon" only
+	SentimentAnalyzer string
+	// EmbeddingProvider embeds textbox answers for semantic search: "github" or the offline "local" hashing
+	EmbeddingProvider string
+	// EmbeddingModel is the GitHub Models embedding model
+	EmbeddingModel string
+	// InsightAnalysisLanguage is the language textbox answers are translated into before
+	// insight batching, e.g. "en"; empty leaves them untranslated
+	InsightAnalysisLanguage string
+	// ReportBrandName and ReportBrandColor brand the exported insight reports
+	ReportBrandName  string
+	ReportBrandColor string
+	// InsightScheduleSyncInterval is how often changed insight schedules are picked up
+	InsightScheduleSyncInterval time.Duration
+}
+
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// defaultLLMPrices are used for models missing from LLM_PRICES
+var defaultLLMPrices = map[string]models.ModelPrice{
+	"openai/gpt-4o-mini": {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+	"openai/gpt-4o":      {InputPerMillion: 2.50, OutputPerMillion: 10.00},
 }
 
 func LoadConfig() (*Config, error) {
 	// Load .env file if it exists
 	_ = godotenv.Load()
 
+	insightBatchConcurrency, err := intEnv("INSIGHT_BATCH_CONCURRENCY", 4)
+	if err != nil {
+		return nil, err
+	}
+
+	insightTokenBudgets, err := budgetsEnv("INSIGHT_TOKEN_BUDGETS")
+	if err != nil {
+		return nil, err
+	}
+
+	llmPrices, err := pricesEnv("LLM_PRICES")
+	if err != nil {
+		return nil, err
+	}
+
+	dailyTokenBudget, err := limitEnv("LLM_DAILY_TOKEN_BUDGET")
+	if err != nil {
+		return nil, err
+	}
+	monthlyTokenBudget, err := limitEnv("LLM_MONTHLY_TOKEN_BUDGET")
+	if err != nil {
+		return nil, err
+	}
+
+	llmCacheTTL, err := durationEnv("LLM_CACHE_TTL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	llmLogRetention, err := durationEnv("LLM_LOG_RETENTION", 90*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	// Token budgets are read from the logs, so they must outlive the longest budget
+	// period; survey budgets can be set at any time, so this holds without global ones.
+	if llmLogRetention > 0 && llmLogRetention < 31*24*time.Hour {
+		return nil, fmt.Errorf("LLM_LOG_RETENTION must be 0 or at least 744h (31 days), as monthly token budgets are counted from the logs, got %q", os.Getenv("LLM_LOG_RETENTION"))
+	}
+
+	sentimentAnalyzer := os.Getenv("SENTIMENT_ANALYZER")
+	switch sentimentAnalyzer {
+	case "":
+		sentimentAnalyzer = "llm"
+	case "llm", "lexicon":
+	default:
+		return nil, fmt.Errorf("SENTIMENT_ANALYZER must be llm or lexicon, got %q", sentimentAnalyzer)
+	}
+
+	embeddingProvider := os.Getenv("EMBEDDING_PROVIDER")
+	switch embeddingProvider {
+	case "":
+		embeddingProvider = "github"
+	case "github", "local":
+	default:
+		return nil, fmt.Errorf("EMBEDDING_PROVIDER must be github or local, got %q", embeddingProvider)
+	}
+	embeddingModel := os.Getenv("EMBEDDING_MODEL")
+	if embeddingModel == "" {
+		embeddingModel = "openai/text-embedding-3-small"
+	}
+
+	insightAnalysisLanguage := os.Getenv("INSIGHT_ANALYSIS_LANGUAGE")
+	switch insightAnalysisLanguage {
+	case "", "en", "yue", "zh", "ja", "ko":
+	default:
+		return nil, fmt.Errorf("INSIGHT_ANALYSIS_LANGUAGE must be en, yue, zh, ja or ko, got %q", insightAnalysisLanguage)
+	}
+
+	reportBrandName := os.Getenv("REPORT_BRAND_NAME")
+	if reportBrandName == "" {
+		reportBrandName = "OSP"
+	}
+	reportBrandColor := os.Getenv("REPORT_BRAND_COLOR")
+	if reportBrandColor == "" {
+		reportBrandColor = "#1f6feb"
+	}
+	if !hexColorPattern.MatchString(reportBrandColor) {
+		return nil, fmt.Errorf("REPORT_BRAND_COLOR must be a #rrggbb colour, got %q", reportBrandColor)
+	}
+
+	insightScheduleSyncInterval, err := durationEnv("INSIGHT_SCHEDULE_SYNC_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if insightScheduleSyncInterval == 0 {
+		return nil, fmt.Errorf("INSIGHT_SCHEDULE_SYNC_INTERVAL must be positive")
+	}
+
 	return &Config{
 		Port:        os.Getenv("PORT"),
 		RootToken:   os.Getenv("ROOT_TOKEN"),
 		DBUri:       os.Getenv("MONGODB_URI"),
 		RedisUri:    os.Getenv("REDIS_URI"),
 		GitHubToken: os.Getenv("GITHUB_TOKEN"),
+
+		InsightBatchConcurrency: insightBatchConcurrency,
+		InsightTokenBudgets:     insightTokenBudgets,
+		LLMPrices:               llmPrices,
+		LLMTokenBudget: models.TokenBudget{
+			Daily:   dailyTokenBudget,
+			Monthly: monthlyTokenBudget,
+		},
+		LLMCacheTTL:       llmCacheTTL,
+		LLMLogRetention:   llmLogRetention,
+		PIIRedactionTerms: listEnv("PII_REDACTION_TERMS"),
+		SentimentAnalyzer: sentimentAnalyzer,
+		EmbeddingProvider: embeddingProvider,
+		EmbeddingModel:    embeddingModel,
+
+		InsightAnalysisLanguage: insightAnalysisLanguage,
+		ReportBrandName:         reportBrandName,
+		ReportBrandColor:        reportBrandColor,
+
+		InsightScheduleSyncInterval: insightScheduleSyncInterval,
 	}, nil
 }
+
+// intEnv reads a positive integer environment variable, falling back to def when unset
+func intEnv(key string, def int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %q", key, value)
+	}
+	return n, nil
+}
+
+// durationEnv reads a non-negative duration such as "12h", falling back to def when unset
+func durationEnv(key string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative duration, got %q", key, value)
+	}
+	return d, nil
+}
+
+// limitEnv reads a non-negative integer environment variable, where unset or 0 means unlimited
+func limitEnv(key string) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", key, value)
+	}
+	return n, nil
+}
+
+// listEnv reads a comma-separated list, skipping empty items
+func listEnv(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// budgetsEnv reads a comma-separated list of model=tokens pairs,
+// e.g. "openai/gpt-4o-mini=16000,openai/gpt-4o=16000"
+func budgetsEnv(key string) (map[string]int, error) {
+	budgets := make(map[string]int)
+	value := os.Getenv(key)
+	if value == "" {
+		return budgets, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		model, tokens, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		n, err := strconv.Atoi(tokens)
+		if !ok || model == "" || err != nil || n < 1 {
+			return nil, fmt.Errorf("%s must be a list of model=tokens pairs, got %q", key, pair)
+		}
+		budgets[model] = n
+	}
+	return budgets, nil
+}
+
+// pricesEnv reads a comma-separated list of model=input:output pairs in USD per
+// million tokens, e.g. "openai/gpt-4o-mini=0.15:0.60", on top of the default prices
+func pricesEnv(key string) (map[string]models.ModelPrice, error) {
+	prices := make(map[string]models.ModelPrice, len(defaultLLMPrices))
+	for model, price := range defaultLLMPrices {
+		prices[model] = price
+	}
+	value := os.Getenv(key)
+	if value == "" {
+		return prices, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		model, rates, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		input, output, ok2 := strings.Cut(rates, ":")
+		in, err1 := strconv.ParseFloat(input, 64)
+		out, err2 := strconv.ParseFloat(output, 64)
+		if !ok || !ok2 || model == "" || err1 != nil || err2 != nil || in < 0 || out < 0 {
+			return nil, fmt.Errorf("%s must be a list of model=input:output pairs, got %q", key, pair)
+		}
+		prices[model] = models.ModelPrice{InputPerMillion: in, OutputPerMillion: out}
+	}
+	return prices, nil
+}
@@ -0,0 +1,98 @@
+package embedding
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"sort"
+
+	"osp/internal/models"
+	"osp/internal/textanalytics"
+)
+
+// hashDimensions is the size of the vectors of the hashing embedder
+const hashDimensions = 256
+
+// Hash embeds texts offline by hashing their tokens into a fixed number of dimensions.
+// It is deterministic and needs no network, so it stands in for a model in tests and
+// local development; texts sharing words end up close, synonyms do not.
+type Hash struct{}
+
+func (Hash) Model() string {
+	return "local-hash-256"
+}
+
+// Embed returns one L2-normalized vector per text; texts without tokens get a zero
+// vector. Nothing is sent anywhere, so opts is ignored.
+func (Hash) Embed(ctx context.Context, texts []string, opts *models.ChatCompletionOptions) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float64, hashDimensions)
+		for _, token := range textanalytics.Tokenize(text) {
+			h := fnv.New32a()
+			h.Write([]byte(token))
+			sum := h.Sum32()
+			// The top bit picks the sign so colliding tokens tend to cancel out.
+			sign := 1.0
+			if sum&(1<<31) != 0 {
+				sign = -1
+			}
+			v[sum%hashDimensions] += sign
+		}
+		vectors[i] = normalize(v)
+	}
+	return vectors, nil
+}
+
+func normalize(v []float64) []float32 {
+	norm := 0.0
+	for _, x := range v {
+		norm += x * x
+	}
+	norm = math.Sqrt(norm)
+	result := make([]float32, len(v))
+	if norm == 0 {
+		return result
+	}
+	for i, x := range v {
+		result[i] = float32(x / norm)
+	}
+	return result
+}
+
+// Cosine returns the cosine similarity of two vectors, or 0 if their sizes differ or
+// either is a zero vector
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+// Match is the index of a candidate vector and its similarity to the query
+type Match struct {
+	Index int
+	Score float64
+}
+
+// Nearest returns the n candidates most similar to query, most similar first.
+// Candidates with no positive similarity are left out.
+func Nearest(query []float32, candidates [][]float32, n int) []Match {
+	var matches []Match
+	for i, candidate := range candidates {
+		if score := Cosine(query, candidate); score > 0 {
+			matches = append(matches, Match{Index: i, Score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	return matches[:min(n, len(matches))]
+}
@@ -0,0 +1,51 @@
+package embedding
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHash_Embed(t *testing.T) {
+	vectors, err := Hash{}.Embed(context.Background(), []string{
+		"Onboarding was confusing",
+		"The onboarding process was confusing",
+		"Great pizza at lunch",
+		"!!!",
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, vectors, 4)
+	for _, v := range vectors {
+		assert.Len(t, v, hashDimensions)
+	}
+	again, _ := Hash{}.Embed(context.Background(), []string{"Onboarding was confusing"}, nil)
+	assert.Equal(t, vectors[0], again[0], "embeddings are deterministic")
+	assert.InDelta(t, 1, Cosine(vectors[0], vectors[0]), 1e-6)
+	assert.Greater(t, Cosine(vectors[0], vectors[1]), Cosine(vectors[0], vectors[2]))
+	assert.Zero(t, Cosine(vectors[0], vectors[3]), "a text without tokens matches nothing")
+}
+
+func TestCosine(t *testing.T) {
+	assert.InDelta(t, 0, Cosine([]float32{1, 0}, []float32{0, 1}), 1e-9)
+	assert.InDelta(t, -1, Cosine([]float32{1, 0}, []float32{-2, 0}), 1e-9)
+	assert.Zero(t, Cosine([]float32{1, 0}, []float32{1, 0, 0}))
+}
+
+func TestNearest(t *testing.T) {
+	query := []float32{1, 0}
+	candidates := [][]float32{{0, 1}, {1, 1}, {1, 0}, {-1, 0}}
+
+	assert.Equal(t, []int{2, 1}, indexes(Nearest(query, candidates, 5)))
+	assert.Equal(t, []int{2}, indexes(Nearest(query, candidates, 1)))
+	assert.Empty(t, Nearest(query, nil, 5))
+}
+
+func indexes(matches []Match) []int {
+	result := make([]int, len(matches))
+	for i, match := range matches {
+		result[i] = match.Index
+	}
+	return result
+}
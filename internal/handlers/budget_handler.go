@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type BudgetHandler struct {
+	budgetService services.IBudgetService
+}
+
+func NewBudgetHandler(budgetService services.IBudgetService) *BudgetHandler {
+	return &BudgetHandler{
+		budgetService: budgetService,
+	}
+}
+
+func (h *BudgetHandler) GetBudget(c *gin.Context) {
+	var req models.GetBudgetRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetBudgetResponse{
+			Error: "Invalid query parameters",
+		})
+		return
+	}
+	var surveyID *bson.ObjectID
+	if req.SurveyID != nil {
+		id, err := bson.ObjectIDFromHex(*req.SurveyID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, &models.GetBudgetResponse{
+				Error: "Invalid survey ID",
+			})
+			return
+		}
+		surveyID = &id
+	}
+
+	budgets, err := h.budgetService.GetBudget(c.Request.Context(), surveyID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, &models.GetBudgetResponse{
+			Error: "Survey not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.GetBudgetResponse{
+			Error: "Failed to retrieve token budget",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.GetBudgetResponse{
+		Data: budgets,
+	})
+}
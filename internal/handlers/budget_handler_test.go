@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"osp/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockBudgetService is a mock implementation of IBudgetService
+type MockBudgetService struct {
+	mock.Mock
+}
+
+func (m *MockBudgetService) Allow(ctx context.Context, surveyID *bson.ObjectID) error {
+	args := m.Called(ctx, surveyID)
+	return args.Error(0)
+}
+
+func (m *MockBudgetService) GetBudget(ctx context.Context, surveyID *bson.ObjectID) ([]models.BudgetUsage, error) {
+	args := m.Called(ctx, surveyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.BudgetUsage), args.Error(1)
+}
+
+func TestGetBudget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockBudgetService)
+		handler := NewBudgetHandler(mockService)
+		router := gin.Default()
+		router.GET("/budget", handler.GetBudget)
+
+		surveyID := bson.NewObjectID()
+		mockService.On("GetBudget", mock.Anything, &surveyID).Return([]models.BudgetUsage{
+			{Scope: models.BudgetScopeSurvey, Period: models.BudgetPeriodDaily, Limit: 5000, Used: 1200, Remaining: 3800},
+		}, nil)
+
+		req, _ := http.NewRequest("GET", "/budget?surveyId="+surveyID.Hex(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"remaining":3800`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("SurveyNotFound", func(t *testing.T) {
+		mockService := new(MockBudgetService)
+		handler := NewBudgetHandler(mockService)
+		router := gin.Default()
+		router.GET("/budget", handler.GetBudget)
+
+		mockService.On("GetBudget", mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments)
+
+		req, _ := http.NewRequest("GET", "/budget?surveyId="+bson.NewObjectID().Hex(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type ChatCompletionLogHandler struct {
+	logService services.IChatCompletionLogService
+}
+
+func NewChatCompletionLogHandler(logService services.IChatCompletionLogService) *ChatCompletionLogHandler {
+	return &ChatCompletionLogHandler{
+		logService: logService,
+	}
+}
+
+func (h *ChatCompletionLogHandler) GetLogs(c *gin.Context) {
+	var req models.GetChatCompletionLogsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetChatCompletionLogsResponse{
+			Error: "Invalid query parameters",
+		})
+		return
+	}
+
+	filter := models.ChatCompletionLogFilter{
+		Reference: req.Reference,
+		Model:     req.Model,
+		From:      req.From,
+	}
+	if req.To != nil {
+		// The to date is inclusive.
+		to := req.To.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	if req.InsightID != nil {
+		insightID, err := bson.ObjectIDFromHex(*req.InsightID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, &models.GetChatCompletionLogsResponse{
+				Error: "Invalid insight ID",
+			})
+			return
+		}
+		filter.InsightID = &insightID
+	}
+	switch req.Status {
+	case "success":
+		filter.Status = models.ChatCompletionSucceeded
+	case "error":
+		filter.Status = models.ChatCompletionFailed
+	}
+
+	logs, total, err := h.logService.GetLogs(c.Request.Context(), filter, req.Offset, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.GetChatCompletionLogsResponse{
+			Error: "Failed to retrieve LLM logs",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.GetChatCompletionLogsResponse{
+		Data:  logs,
+		Total: total,
+	})
+}
+
+func (h *ChatCompletionLogHandler) GetLog(c *gin.Context) {
+	var uriReq models.GetChatCompletionLogRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetChatCompletionLogResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	id, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetChatCompletionLogResponse{
+			Error: "Invalid LLM log ID",
+		})
+		return
+	}
+	entry, err := h.logService.GetLog(c.Request.Context(), id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, &models.GetChatCompletionLogResponse{
+			Error: "LLM log not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.GetChatCompletionLogResponse{
+			Error: "Failed to retrieve LLM log",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.GetChatCompletionLogResponse{
+		Data: entry,
+	})
+}
+
+func (h *ChatCompletionLogHandler) ReplayLog(c *gin.Context) {
+	var uriReq models.GetChatCompletionLogRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.ReplayChatCompletionResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	id, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.ReplayChatCompletionResponse{
+			Error: "Invalid LLM log ID",
+		})
+		return
+	}
+	var req models.ReplayChatCompletionRequest
+	// The body is optional: without one the request is re-sent as logged.
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, &models.ReplayChatCompletionResponse{
+				Error: err.Error(),
+			})
+			return
+		}
+	}
+
+	replay, err := h.logService.ReplayLog(c.Request.Context(), id, &req)
+	var llmErr *services.LLMError
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		c.JSON(http.StatusNotFound, &models.ReplayChatCompletionResponse{
+			Error: "LLM log not found",
+		})
+		return
+	case errors.Is(err, services.ErrLogNotReplayable):
+		c.JSON(http.StatusBadRequest, &models.ReplayChatCompletionResponse{
+			Error: err.Error(),
+		})
+		return
+	case errors.As(err, &llmErr) && llmErr.Kind == services.LLMErrorBudgetExceeded:
+		c.JSON(http.StatusConflict, &models.ReplayChatCompletionResponse{
+			Error: err.Error(),
+		})
+		return
+	case errors.As(err, &llmErr):
+		c.JSON(http.StatusBadGateway, &models.ReplayChatCompletionResponse{
+			Error: err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, &models.ReplayChatCompletionResponse{
+			Error: "Failed to replay LLM request",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.ReplayChatCompletionResponse{
+		Data: replay,
+	})
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockChatCompletionLogService is a mock implementation of IChatCompletionLogService
+type MockChatCompletionLogService struct {
+	mock.Mock
+}
+
+func (m *MockChatCompletionLogService) GetLogs(ctx context.Context, filter models.ChatCompletionLogFilter, offset, limit int64) ([]*models.ChatCompletionRequestLog, int64, error) {
+	args := m.Called(ctx, filter, offset, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*models.ChatCompletionRequestLog), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockChatCompletionLogService) GetLog(ctx context.Context, id bson.ObjectID) (*models.ChatCompletionRequestLog, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChatCompletionRequestLog), args.Error(1)
+}
+
+func (m *MockChatCompletionLogService) ReplayLog(ctx context.Context, id bson.ObjectID, req *models.ReplayChatCompletionRequest) (*models.ChatCompletionReplay, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChatCompletionReplay), args.Error(1)
+}
+
+func TestGetChatCompletionLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockChatCompletionLogService)
+		handler := NewChatCompletionLogHandler(mockService)
+		router := gin.Default()
+		router.GET("/llm-logs", handler.GetLogs)
+
+		insightID := bson.NewObjectID()
+		mockService.On("GetLogs", mock.Anything, mock.MatchedBy(func(filter models.ChatCompletionLogFilter) bool {
+			return filter.Reference == "batch:3" && *filter.InsightID == insightID && filter.Model == "openai/gpt-4o-mini" &&
+				filter.Status == models.ChatCompletionFailed && filter.To != nil && filter.To.Format("2006-01-02") == "2026-03-02"
+		}), int64(0), int64(10)).Return([]*models.ChatCompletionRequestLog{{ID: bson.NewObjectID()}}, int64(1), nil)
+
+		req, _ := http.NewRequest("GET", "/llm-logs?reference=batch:3&insightId="+insightID.Hex()+"&model=openai/gpt-4o-mini&status=error&to=2026-03-01", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"total":1`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidStatus", func(t *testing.T) {
+		mockService := new(MockChatCompletionLogService)
+		handler := NewChatCompletionLogHandler(mockService)
+		router := gin.Default()
+		router.GET("/llm-logs", handler.GetLogs)
+
+		req, _ := http.NewRequest("GET", "/llm-logs?status=broken", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGetChatCompletionLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService := new(MockChatCompletionLogService)
+		handler := NewChatCompletionLogHandler(mockService)
+		router := gin.Default()
+		router.GET("/llm-logs/:id", handler.GetLog)
+
+		mockService.On("GetLog", mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments)
+
+		req, _ := http.NewRequest("GET", "/llm-logs/"+bson.NewObjectID().Hex(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestReplayChatCompletionLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockChatCompletionLogService)
+		handler := NewChatCompletionLogHandler(mockService)
+		router := gin.Default()
+		router.POST("/llm-logs/:id/replay", handler.ReplayLog)
+
+		id := bson.NewObjectID()
+		mockService.On("ReplayLog", mock.Anything, id, mock.MatchedBy(func(req *models.ReplayChatCompletionRequest) bool {
+			return req.Model != nil && *req.Model == "openai/gpt-4o"
+		})).Return(&models.ChatCompletionReplay{SourceID: id, Content: "Replayed"}, nil)
+
+		req, _ := http.NewRequest("POST", "/llm-logs/"+id.Hex()+"/replay", bytes.NewBufferString(`{"model":"openai/gpt-4o"}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("WithoutBody", func(t *testing.T) {
+		mockService := new(MockChatCompletionLogService)
+		handler := NewChatCompletionLogHandler(mockService)
+		router := gin.Default()
+		router.POST("/llm-logs/:id/replay", handler.ReplayLog)
+
+		mockService.On("ReplayLog", mock.Anything, mock.Anything, &models.ReplayChatCompletionRequest{}).Return(&models.ChatCompletionReplay{}, nil)
+
+		req, _ := http.NewRequest("POST", "/llm-logs/"+bson.NewObjectID().Hex()+"/replay", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("ProviderError", func(t *testing.T) {
+		mockService := new(MockChatCompletionLogService)
+		handler := NewChatCompletionLogHandler(mockService)
+		router := gin.Default()
+		router.POST("/llm-logs/:id/replay", handler.ReplayLog)
+
+		mockService.On("ReplayLog", mock.Anything, mock.Anything, mock.Anything).Return(nil, &services.LLMError{Kind: services.LLMErrorInvalidRequest, StatusCode: 400})
+
+		req, _ := http.NewRequest("POST", "/llm-logs/"+bson.NewObjectID().Hex()+"/replay", bytes.NewBufferString(`{"messages":[{"role":"user","content":"Hi"}]}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+	})
+
+	t.Run("EmbeddingsLog", func(t *testing.T) {
+		mockService := new(MockChatCompletionLogService)
+		handler := NewChatCompletionLogHandler(mockService)
+		router := gin.Default()
+		router.POST("/llm-logs/:id/replay", handler.ReplayLog)
+
+		mockService.On("ReplayLog", mock.Anything, mock.Anything, mock.Anything).Return(nil, services.ErrLogNotReplayable)
+
+		req, _ := http.NewRequest("POST", "/llm-logs/"+bson.NewObjectID().Hex()+"/replay", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
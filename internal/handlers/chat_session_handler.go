@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type ChatSessionHandler struct {
+	sessionService services.IChatSessionService
+}
+
+func NewChatSessionHandler(sessionService services.IChatSessionService) *ChatSessionHandler {
+	return &ChatSessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+func (h *ChatSessionHandler) CreateSession(c *gin.Context) {
+	var req models.CreateChatSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.CreateChatSessionResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	session, err := h.sessionService.CreateSession(c.Request.Context(), &req)
+	if errors.Is(err, services.ErrInvalidChatSession) {
+		c.JSON(http.StatusBadRequest, &models.CreateChatSessionResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.CreateChatSessionResponse{
+			Error: "Failed to create chat session",
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, &models.CreateChatSessionResponse{
+		Data: session,
+	})
+}
+
+func (h *ChatSessionHandler) GetSessions(c *gin.Context) {
+	var req models.GetChatSessionsRequest
+	if err := c.BindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetChatSessionsResponse{
+			Error: "Invalid query parameters",
+		})
+		return
+	}
+	var surveyID *bson.ObjectID
+	if req.SurveyID != nil {
+		id, err := bson.ObjectIDFromHex(*req.SurveyID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, &models.GetChatSessionsResponse{
+				Error: "Invalid survey ID",
+			})
+			return
+		}
+		surveyID = &id
+	}
+	sessions, err := h.sessionService.GetSessions(c.Request.Context(), req.Offset, req.Limit, surveyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.GetChatSessionsResponse{
+			Error: "Failed to retrieve chat sessions",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.GetChatSessionsResponse{
+		Data: sessions,
+	})
+}
+
+func (h *ChatSessionHandler) GetSession(c *gin.Context) {
+	var uriReq models.GetChatSessionRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetChatSessionResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	id, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetChatSessionResponse{
+			Error: "Invalid chat session ID",
+		})
+		return
+	}
+	session, err := h.sessionService.GetSession(c.Request.Context(), id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, &models.GetChatSessionResponse{
+			Error: "Chat session not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.GetChatSessionResponse{
+			Error: "Failed to retrieve chat session",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.GetChatSessionResponse{
+		Data: session,
+	})
+}
+
+func (h *ChatSessionHandler) Ask(c *gin.Context) {
+	var uriReq models.GetChatSessionRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.AskChatSessionResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	id, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.AskChatSessionResponse{
+			Error: "Invalid chat session ID",
+		})
+		return
+	}
+	var req models.AskChatSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.AskChatSessionResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	reply, err := h.sessionService.Ask(c.Request.Context(), id, &req)
+	var llmErr *services.LLMError
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		c.JSON(http.StatusNotFound, &models.AskChatSessionResponse{
+			Error: "Chat session not found",
+		})
+		return
+	case errors.Is(err, services.ErrInvalidChatSession):
+		c.JSON(http.StatusBadRequest, &models.AskChatSessionResponse{
+			Error: err.Error(),
+		})
+		return
+	case errors.As(err, &llmErr) && llmErr.Kind == services.LLMErrorBudgetExceeded:
+		c.JSON(http.StatusConflict, &models.AskChatSessionResponse{
+			Error: err.Error(),
+		})
+		return
+	case errors.As(err, &llmErr):
+		c.JSON(http.StatusBadGateway, &models.AskChatSessionResponse{
+			Error: err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, &models.AskChatSessionResponse{
+			Error: "Failed to answer question",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.AskChatSessionResponse{
+		Data: reply,
+	})
+}
+
+func (h *ChatSessionHandler) DeleteSession(c *gin.Context) {
+	var uriReq models.GetChatSessionRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.DeleteChatSessionResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	id, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.DeleteChatSessionResponse{
+			Error: "Invalid chat session ID",
+		})
+		return
+	}
+	err = h.sessionService.DeleteSession(c.Request.Context(), id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, &models.DeleteChatSessionResponse{
+			Error: "Chat session not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.DeleteChatSessionResponse{
+			Error: "Failed to delete chat session",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.DeleteChatSessionResponse{})
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockChatSessionService is a mock implementation of IChatSessionService
+type MockChatSessionService struct {
+	mock.Mock
+}
+
+func (m *MockChatSessionService) CreateSession(ctx context.Context, req *models.CreateChatSessionRequest) (*models.ChatSession, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChatSession), args.Error(1)
+}
+
+func (m *MockChatSessionService) GetSessions(ctx context.Context, offset, limit int64, surveyID *bson.ObjectID) ([]*models.ChatSession, error) {
+	args := m.Called(ctx, offset, limit, surveyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ChatSession), args.Error(1)
+}
+
+func (m *MockChatSessionService) GetSession(ctx context.Context, id bson.ObjectID) (*models.ChatSession, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChatSession), args.Error(1)
+}
+
+func (m *MockChatSessionService) Ask(ctx context.Context, id bson.ObjectID, req *models.AskChatSessionRequest) (*models.ChatSessionMessage, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChatSessionMessage), args.Error(1)
+}
+
+func (m *MockChatSessionService) DeleteSession(ctx context.Context, id bson.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestCreateChatSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockChatSessionService)
+		handler := NewChatSessionHandler(mockService)
+		router := gin.Default()
+		router.POST("/chat-sessions", handler.CreateSession)
+
+		surveyID := bson.NewObjectID()
+		mockService.On("CreateSession", mock.Anything, mock.MatchedBy(func(req *models.CreateChatSessionRequest) bool {
+			return req.SurveyID == surveyID && req.InsightID == nil
+		})).Return(&models.ChatSession{ID: bson.NewObjectID(), SurveyID: surveyID}, nil)
+
+		req, _ := http.NewRequest("POST", "/chat-sessions", bytes.NewBufferString(`{"survey_id":"`+surveyID.Hex()+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidRequest", func(t *testing.T) {
+		mockService := new(MockChatSessionService)
+		handler := NewChatSessionHandler(mockService)
+		router := gin.Default()
+		router.POST("/chat-sessions", handler.CreateSession)
+
+		mockService.On("CreateSession", mock.Anything, mock.Anything).
+			Return(nil, fmt.Errorf("%w: survey not found", services.ErrInvalidChatSession))
+
+		req, _ := http.NewRequest("POST", "/chat-sessions", bytes.NewBufferString(`{"survey_id":"`+bson.NewObjectID().Hex()+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAskChatSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setup := func() (*MockChatSessionService, *gin.Engine) {
+		mockService := new(MockChatSessionService)
+		handler := NewChatSessionHandler(mockService)
+		router := gin.Default()
+		router.POST("/chat-sessions/:id/messages", handler.Ask)
+		return mockService, router
+	}
+	ask := func(router *gin.Engine, id bson.ObjectID, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/chat-sessions/"+id.Hex()+"/messages", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockService, router := setup()
+		id := bson.NewObjectID()
+		mockService.On("Ask", mock.Anything, id, &models.AskChatSessionRequest{Content: "Why?"}).
+			Return(&models.ChatSessionMessage{Role: models.ChatSessionAssistant, Content: "Because [1]."}, nil)
+
+		w := ask(router, id, `{"content":"Why?"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Because [1].")
+	})
+
+	t.Run("MissingContent", func(t *testing.T) {
+		mockService, router := setup()
+
+		w := ask(router, bson.NewObjectID(), `{}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "Ask")
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService, router := setup()
+		mockService.On("Ask", mock.Anything, mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments)
+
+		w := ask(router, bson.NewObjectID(), `{"content":"Why?"}`)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("BudgetExceeded", func(t *testing.T) {
+		mockService, router := setup()
+		mockService.On("Ask", mock.Anything, mock.Anything, mock.Anything).Return(nil, &services.LLMError{Kind: services.LLMErrorBudgetExceeded})
+
+		w := ask(router, bson.NewObjectID(), `{"content":"Why?"}`)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
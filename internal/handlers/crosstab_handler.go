@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type CrossTabHandler struct {
+	crossTabService services.ICrossTabService
+}
+
+func NewCrossTabHandler(crossTabService services.ICrossTabService) *CrossTabHandler {
+	return &CrossTabHandler{
+		crossTabService: crossTabService,
+	}
+}
+
+func (h *CrossTabHandler) CrossTabulate(c *gin.Context) {
+	var uriReq models.GetSurveyRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.CrossTabulationResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	surveyID, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.CrossTabulationResponse{
+			Error: "Invalid survey ID",
+		})
+		return
+	}
+	var req models.CrossTabulationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.CrossTabulationResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	crossTab, err := h.crossTabService.CrossTabulate(c.Request.Context(), surveyID, &req)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusNotFound, &models.CrossTabulationResponse{
+				Error: "Survey not found",
+			})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidCrossTabulation) {
+			c.JSON(http.StatusBadRequest, &models.CrossTabulationResponse{
+				Error: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, &models.CrossTabulationResponse{
+			Error: "Failed to cross-tabulate survey",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.CrossTabulationResponse{
+		Data: crossTab,
+	})
+}
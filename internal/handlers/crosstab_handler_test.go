@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockCrossTabService is a mock implementation of ICrossTabService
+type MockCrossTabService struct {
+	mock.Mock
+}
+
+func (m *MockCrossTabService) CrossTabulate(ctx context.Context, surveyID bson.ObjectID, req *models.CrossTabulationRequest) (*models.CrossTabulation, error) {
+	args := m.Called(ctx, surveyID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CrossTabulation), args.Error(1)
+}
+
+func TestCrossTabulate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockCrossTabService)
+		handler := NewCrossTabHandler(mockService)
+		router := gin.Default()
+		router.GET("/surveys/:id/crosstab", handler.CrossTabulate)
+
+		surveyID := bson.NewObjectID()
+		mockService.On("CrossTabulate", mock.Anything, surveyID, mock.MatchedBy(func(req *models.CrossTabulationRequest) bool {
+			return req.RowQuestionID == "a" && req.ColumnQuestionID == "b" && req.Narrate
+		})).Return(&models.CrossTabulation{SurveyID: surveyID}, nil)
+
+		req, _ := http.NewRequest("GET", "/surveys/"+surveyID.Hex()+"/crosstab?rowQuestionId=a&columnQuestionId=b&narrate=true", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("MissingQuestion", func(t *testing.T) {
+		mockService := new(MockCrossTabService)
+		handler := NewCrossTabHandler(mockService)
+		router := gin.Default()
+		router.GET("/surveys/:id/crosstab", handler.CrossTabulate)
+
+		req, _ := http.NewRequest("GET", "/surveys/"+bson.NewObjectID().Hex()+"/crosstab?rowQuestionId=a", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "CrossTabulate")
+	})
+
+	t.Run("InvalidRequest", func(t *testing.T) {
+		mockService := new(MockCrossTabService)
+		handler := NewCrossTabHandler(mockService)
+		router := gin.Default()
+		router.GET("/surveys/:id/crosstab", handler.CrossTabulate)
+
+		mockService.On("CrossTabulate", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, fmt.Errorf("%w: invalid question ID: a", services.ErrInvalidCrossTabulation))
+
+		req, _ := http.NewRequest("GET", "/surveys/"+bson.NewObjectID().Hex()+"/crosstab?rowQuestionId=a&columnQuestionId=b", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("SurveyNotFound", func(t *testing.T) {
+		mockService := new(MockCrossTabService)
+		handler := NewCrossTabHandler(mockService)
+		router := gin.Default()
+		router.GET("/surveys/:id/crosstab", handler.CrossTabulate)
+
+		mockService.On("CrossTabulate", mock.Anything, mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments)
+
+		req, _ := http.NewRequest("GET", "/surveys/"+bson.NewObjectID().Hex()+"/crosstab?rowQuestionId=a&columnQuestionId=b", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("ServiceError", func(t *testing.T) {
+		mockService := new(MockCrossTabService)
+		handler := NewCrossTabHandler(mockService)
+		router := gin.Default()
+		router.GET("/surveys/:id/crosstab", handler.CrossTabulate)
+
+		mockService.On("CrossTabulate", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		req, _ := http.NewRequest("GET", "/surveys/"+bson.NewObjectID().Hex()+"/crosstab?rowQuestionId=a&columnQuestionId=b", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
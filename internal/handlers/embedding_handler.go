@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type EmbeddingHandler struct {
+	embeddingService services.IEmbeddingService
+}
+
+func NewEmbeddingHandler(embeddingService services.IEmbeddingService) *EmbeddingHandler {
+	return &EmbeddingHandler{
+		embeddingService: embeddingService,
+	}
+}
+
+func (h *EmbeddingHandler) Search(c *gin.Context) {
+	var uriReq models.GetSurveyRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.SemanticSearchResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	surveyID, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.SemanticSearchResponse{
+			Error: "Invalid survey ID",
+		})
+		return
+	}
+	var req models.SemanticSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.SemanticSearchResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	search, err := h.embeddingService.Search(c.Request.Context(), surveyID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSemanticSearch) {
+			c.JSON(http.StatusBadRequest, &models.SemanticSearchResponse{
+				Error: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, &models.SemanticSearchResponse{
+			Error: "Failed to search answers",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.SemanticSearchResponse{
+		Data: search,
+	})
+}
+
+func (h *EmbeddingHandler) ReindexSurvey(c *gin.Context) {
+	var uriReq models.GetSurveyRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.ReindexEmbeddingsResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	surveyID, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.ReindexEmbeddingsResponse{
+			Error: "Invalid survey ID",
+		})
+		return
+	}
+
+	reindex, err := h.embeddingService.ReindexSurvey(c.Request.Context(), surveyID)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSemanticSearch) {
+			c.JSON(http.StatusNotFound, &models.ReindexEmbeddingsResponse{
+				Error: "Survey not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, &models.ReindexEmbeddingsResponse{
+			Error: "Failed to enqueue embeddings",
+		})
+		return
+	}
+	c.JSON(http.StatusAccepted, &models.ReindexEmbeddingsResponse{
+		Data: reindex,
+	})
+}
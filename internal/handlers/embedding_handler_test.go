@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// MockEmbeddingService is a mock implementation of IEmbeddingService
+type MockEmbeddingService struct {
+	mock.Mock
+}
+
+func (m *MockEmbeddingService) EnqueueSubmission(submissionID bson.ObjectID) error {
+	args := m.Called(submissionID)
+	return args.Error(0)
+}
+
+func (m *MockEmbeddingService) EmbedSubmission(ctx context.Context, submissionID bson.ObjectID) error {
+	args := m.Called(ctx, submissionID)
+	return args.Error(0)
+}
+
+func (m *MockEmbeddingService) DeleteSubmission(ctx context.Context, submissionID bson.ObjectID) error {
+	args := m.Called(ctx, submissionID)
+	return args.Error(0)
+}
+
+func (m *MockEmbeddingService) ReindexSurvey(ctx context.Context, surveyID bson.ObjectID) (*models.ReindexEmbeddings, error) {
+	args := m.Called(ctx, surveyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReindexEmbeddings), args.Error(1)
+}
+
+func (m *MockEmbeddingService) Search(ctx context.Context, surveyID bson.ObjectID, req *models.SemanticSearchRequest) (*models.SemanticSearch, error) {
+	args := m.Called(ctx, surveyID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SemanticSearch), args.Error(1)
+}
+
+func (m *MockEmbeddingService) RegisterHandlers(mux *asynq.ServeMux) {
+	m.Called(mux)
+}
+
+func TestSearchAnswers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setup := func() (*MockEmbeddingService, *gin.Engine) {
+		mockService := new(MockEmbeddingService)
+		handler := NewEmbeddingHandler(mockService)
+		router := gin.Default()
+		router.GET("/surveys/:id/search", handler.Search)
+		return mockService, router
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockService, router := setup()
+		surveyID := bson.NewObjectID()
+		mockService.On("Search", mock.Anything, surveyID, mock.MatchedBy(func(req *models.SemanticSearchRequest) bool {
+			return req.Query == "onboarding" && req.Limit == 10 && req.QuestionID == nil
+		})).Return(&models.SemanticSearch{SurveyID: surveyID, Query: "onboarding"}, nil)
+
+		req, _ := http.NewRequest("GET", "/surveys/"+surveyID.Hex()+"/search?q=onboarding", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("MissingQuery", func(t *testing.T) {
+		mockService, router := setup()
+
+		req, _ := http.NewRequest("GET", "/surveys/"+bson.NewObjectID().Hex()+"/search", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "Search")
+	})
+
+	t.Run("InvalidRequest", func(t *testing.T) {
+		mockService, router := setup()
+		mockService.On("Search", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, fmt.Errorf("%w: survey not found", services.ErrInvalidSemanticSearch))
+
+		req, _ := http.NewRequest("GET", "/surveys/"+bson.NewObjectID().Hex()+"/search?q=onboarding", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("ServiceError", func(t *testing.T) {
+		mockService, router := setup()
+		mockService.On("Search", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("provider down"))
+
+		req, _ := http.NewRequest("GET", "/surveys/"+bson.NewObjectID().Hex()+"/search?q=onboarding", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestReindexEmbeddings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockEmbeddingService)
+	handler := NewEmbeddingHandler(mockService)
+	router := gin.Default()
+	router.POST("/surveys/:id/embeddings", handler.ReindexSurvey)
+
+	surveyID := bson.NewObjectID()
+	mockService.On("ReindexSurvey", mock.Anything, surveyID).Return(&models.ReindexEmbeddings{SurveyID: surveyID, Enqueued: 3}, nil)
+
+	req, _ := http.NewRequest("POST", "/surveys/"+surveyID.Hex()+"/embeddings", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"enqueued":3`)
+}
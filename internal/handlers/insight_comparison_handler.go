@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type InsightComparisonHandler struct {
+	comparisonService services.IInsightComparisonService
+}
+
+func NewInsightComparisonHandler(comparisonService services.IInsightComparisonService) *InsightComparisonHandler {
+	return &InsightComparisonHandler{
+		comparisonService: comparisonService,
+	}
+}
+
+func (h *InsightComparisonHandler) CreateComparison(c *gin.Context) {
+	var req models.CreateInsightComparisonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.CreateInsightComparisonResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	comparison, err := h.comparisonService.CreateComparison(c.Request.Context(), &req)
+	if errors.Is(err, services.ErrInvalidInsightComparison) {
+		c.JSON(http.StatusBadRequest, &models.CreateInsightComparisonResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.CreateInsightComparisonResponse{
+			Error: "Failed to create insight comparison",
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, &models.CreateInsightComparisonResponse{
+		Data: comparison,
+	})
+}
+
+func (h *InsightComparisonHandler) GetComparisons(c *gin.Context) {
+	var req models.GetInsightComparisonsRequest
+	if err := c.BindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightComparisonsResponse{
+			Error: "Invalid query parameters",
+		})
+		return
+	}
+	comparisons, err := h.comparisonService.GetComparisons(c.Request.Context(), req.Offset, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.GetInsightComparisonsResponse{
+			Error: "Failed to retrieve insight comparisons",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.GetInsightComparisonsResponse{
+		Data: comparisons,
+	})
+}
+
+func (h *InsightComparisonHandler) GetComparison(c *gin.Context) {
+	var req models.GetInsightComparisonRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightComparisonResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	id, err := bson.ObjectIDFromHex(req.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightComparisonResponse{
+			Error: "Invalid insight comparison ID",
+		})
+		return
+	}
+	comparison, err := h.comparisonService.GetComparison(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.GetInsightComparisonResponse{
+			Error: "Failed to retrieve insight comparison",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.GetInsightComparisonResponse{
+		Data: comparison,
+	})
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// MockInsightComparisonService is a mock implementation of IInsightComparisonService
+type MockInsightComparisonService struct {
+	mock.Mock
+}
+
+func (m *MockInsightComparisonService) CreateComparison(ctx context.Context, req *models.CreateInsightComparisonRequest) (*models.InsightComparison, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InsightComparison), args.Error(1)
+}
+
+func (m *MockInsightComparisonService) GetComparisons(ctx context.Context, offset, limit int64) ([]*models.InsightComparison, error) {
+	args := m.Called(ctx, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.InsightComparison), args.Error(1)
+}
+
+func (m *MockInsightComparisonService) GetComparison(ctx context.Context, id bson.ObjectID) (*models.InsightComparison, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InsightComparison), args.Error(1)
+}
+
+func TestCreateInsightComparison(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reqBody := models.CreateInsightComparisonRequest{
+		BaseInsightID:   bson.NewObjectID(),
+		TargetInsightID: bson.NewObjectID(),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockInsightComparisonService)
+		handler := NewInsightComparisonHandler(mockService)
+		router := gin.Default()
+		router.POST("/insight-comparisons", handler.CreateComparison)
+
+		mockService.On("CreateComparison", mock.Anything, mock.MatchedBy(func(req *models.CreateInsightComparisonRequest) bool {
+			return req.BaseInsightID == reqBody.BaseInsightID && req.TargetInsightID == reqBody.TargetInsightID
+		})).Return(&models.InsightComparison{ID: bson.NewObjectID()}, nil)
+
+		body, _ := json.Marshal(reqBody)
+		req, _ := http.NewRequest("POST", "/insight-comparisons", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidComparison", func(t *testing.T) {
+		mockService := new(MockInsightComparisonService)
+		handler := NewInsightComparisonHandler(mockService)
+		router := gin.Default()
+		router.POST("/insight-comparisons", handler.CreateComparison)
+
+		mockService.On("CreateComparison", mock.Anything, mock.Anything).
+			Return(nil, fmt.Errorf("%w: both insights must be completed", services.ErrInvalidInsightComparison))
+
+		body, _ := json.Marshal(reqBody)
+		req, _ := http.NewRequest("POST", "/insight-comparisons", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("ServiceError", func(t *testing.T) {
+		mockService := new(MockInsightComparisonService)
+		handler := NewInsightComparisonHandler(mockService)
+		router := gin.Default()
+		router.POST("/insight-comparisons", handler.CreateComparison)
+
+		mockService.On("CreateComparison", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		body, _ := json.Marshal(reqBody)
+		req, _ := http.NewRequest("POST", "/insight-comparisons", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetInsightComparison(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockInsightComparisonService)
+		handler := NewInsightComparisonHandler(mockService)
+		router := gin.Default()
+		router.GET("/insight-comparisons/:id", handler.GetComparison)
+
+		id := bson.NewObjectID()
+		mockService.On("GetComparison", mock.Anything, id).Return(&models.InsightComparison{ID: id}, nil)
+
+		req, _ := http.NewRequest("GET", "/insight-comparisons/"+id.Hex(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		mockService := new(MockInsightComparisonService)
+		handler := NewInsightComparisonHandler(mockService)
+		router := gin.Default()
+		router.GET("/insight-comparisons/:id", handler.GetComparison)
+
+		req, _ := http.NewRequest("GET", "/insight-comparisons/invalid", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"osp/internal/models"
+	"osp/internal/report"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type InsightExportHandler struct {
+	exportService services.IInsightExportService
+}
+
+func NewInsightExportHandler(exportService services.IInsightExportService) *InsightExportHandler {
+	return &InsightExportHandler{
+		exportService: exportService,
+	}
+}
+
+func (h *InsightExportHandler) ExportInsight(c *gin.Context) {
+	var uriReq models.GetInsightRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.ExportInsightResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	id, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.ExportInsightResponse{
+			Error: "Invalid insight ID",
+		})
+		return
+	}
+	var req models.ExportInsightRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.ExportInsightResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	export, err := h.exportService.ExportInsight(c.Request.Context(), id, report.Format(req.Format))
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		c.JSON(http.StatusNotFound, &models.ExportInsightResponse{
+			Error: "Insight not found",
+		})
+		return
+	case errors.Is(err, services.ErrInsightInProgress):
+		c.JSON(http.StatusConflict, &models.ExportInsightResponse{
+			Error: err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, &models.ExportInsightResponse{
+			Error: "Failed to export insight",
+		})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.Filename))
+	c.Data(http.StatusOK, export.ContentType, export.Content)
+}
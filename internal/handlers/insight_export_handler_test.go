@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"osp/internal/models"
+	"osp/internal/report"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockInsightExportService is a mock implementation of IInsightExportService
+type MockInsightExportService struct {
+	mock.Mock
+}
+
+func (m *MockInsightExportService) ExportInsight(ctx context.Context, id bson.ObjectID, format report.Format) (*models.InsightExport, error) {
+	args := m.Called(ctx, id, format)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InsightExport), args.Error(1)
+}
+
+func TestExportInsight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setup := func() (*MockInsightExportService, *gin.Engine) {
+		mockService := new(MockInsightExportService)
+		handler := NewInsightExportHandler(mockService)
+		router := gin.Default()
+		router.GET("/insights/:id/export", handler.ExportInsight)
+		return mockService, router
+	}
+	export := func(router *gin.Engine, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockService, router := setup()
+		id := bson.NewObjectID()
+		mockService.On("ExportInsight", mock.Anything, id, report.PDF).Return(&models.InsightExport{
+			Filename:    "insight-" + id.Hex() + ".pdf",
+			ContentType: "application/pdf",
+			Content:     []byte("%PDF-1.4"),
+		}, nil)
+
+		w := export(router, "/insights/"+id.Hex()+"/export?format=pdf")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="insight-`+id.Hex()+`.pdf"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "%PDF-1.4", w.Body.String())
+	})
+
+	t.Run("DefaultsToMarkdown", func(t *testing.T) {
+		mockService, router := setup()
+		id := bson.NewObjectID()
+		mockService.On("ExportInsight", mock.Anything, id, report.Markdown).Return(&models.InsightExport{ContentType: "text/markdown; charset=utf-8"}, nil)
+
+		w := export(router, "/insights/"+id.Hex()+"/export")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		mockService, router := setup()
+
+		w := export(router, "/insights/"+bson.NewObjectID().Hex()+"/export?format=docx")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ExportInsight")
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService, router := setup()
+		mockService.On("ExportInsight", mock.Anything, mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments)
+
+		w := export(router, "/insights/"+bson.NewObjectID().Hex()+"/export")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InProgress", func(t *testing.T) {
+		mockService, router := setup()
+		mockService.On("ExportInsight", mock.Anything, mock.Anything, mock.Anything).Return(nil, services.ErrInsightInProgress)
+
+		w := export(router, "/insights/"+bson.NewObjectID().Hex()+"/export?format=html")
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"osp/internal/models"
 	"osp/internal/services"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type InsightHandler struct {
@@ -30,6 +34,18 @@ func (h *InsightHandler) CreateInsight(c *gin.Context) {
 	}
 
 	insight, err := h.insightService.CreateInsight(c.Request.Context(), &req)
+	if errors.Is(err, services.ErrInvalidInsightFilter) {
+		c.JSON(http.StatusBadRequest, &models.CreateInsightResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrInsightOverBudget) {
+		c.JSON(http.StatusConflict, &models.CreateInsightResponse{
+			Error: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		fmt.Println("Error creating insight:", err)
 		c.JSON(http.StatusInternalServerError, &models.CreateInsightResponse{
@@ -83,10 +99,20 @@ func (h *InsightHandler) GetInsight(c *gin.Context) {
 		})
 		return
 	}
+	if err := c.BindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightResponse{
+			Error: "Invalid query parameters",
+		})
+		return
+	}
 	var err error
 	var insightID bson.ObjectID
 	insightID, err = bson.ObjectIDFromHex(req.ID)
-	insight, err := h.insightService.GetInsight(c.Request.Context(), insightID)
+	getInsight := h.insightService.GetInsight
+	if req.Unredacted {
+		getInsight = h.insightService.GetUnredactedInsight
+	}
+	insight, err := getInsight(c.Request.Context(), insightID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, &models.GetInsightResponse{
 			Error: "Failed to retrieve insight",
@@ -97,3 +123,180 @@ func (h *InsightHandler) GetInsight(c *gin.Context) {
 		Data: insight,
 	})
 }
+
+func (h *InsightHandler) RetryInsight(c *gin.Context) {
+	h.requeueInsight(c, h.insightService.RetryInsight)
+}
+
+func (h *InsightHandler) RegenerateInsight(c *gin.Context) {
+	h.requeueInsight(c, h.insightService.RegenerateInsight)
+}
+
+// requeueInsight shares the request handling of the retry and regenerate endpoints
+func (h *InsightHandler) requeueInsight(c *gin.Context, requeue func(ctx context.Context, id bson.ObjectID) (*models.Insight, error)) {
+	var req models.GetInsightRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	insightID, err := bson.ObjectIDFromHex(req.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightResponse{
+			Error: "Invalid insight ID",
+		})
+		return
+	}
+	insight, err := requeue(c.Request.Context(), insightID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusAccepted, &models.GetInsightResponse{
+			Data: insight,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		c.JSON(http.StatusNotFound, &models.GetInsightResponse{
+			Error: "Insight not found",
+		})
+	case errors.Is(err, services.ErrInsightInProgress), errors.Is(err, services.ErrInsightNothingToRetry):
+		c.JSON(http.StatusConflict, &models.GetInsightResponse{
+			Error: err.Error(),
+		})
+	default:
+		fmt.Println("Error requeueing insight:", err)
+		c.JSON(http.StatusInternalServerError, &models.GetInsightResponse{
+			Error: "Failed to requeue insight",
+		})
+	}
+}
+
+func (h *InsightHandler) CancelInsight(c *gin.Context) {
+	var req models.GetInsightRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	insightID, err := bson.ObjectIDFromHex(req.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightResponse{
+			Error: "Invalid insight ID",
+		})
+		return
+	}
+	insight, err := h.insightService.CancelInsight(c.Request.Context(), insightID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, &models.GetInsightResponse{
+			Data: insight,
+		})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		c.JSON(http.StatusNotFound, &models.GetInsightResponse{
+			Error: "Insight not found",
+		})
+	case errors.Is(err, services.ErrInsightNotCancellable):
+		c.JSON(http.StatusConflict, &models.GetInsightResponse{
+			Error: err.Error(),
+		})
+	default:
+		fmt.Println("Error cancelling insight:", err)
+		c.JSON(http.StatusInternalServerError, &models.GetInsightResponse{
+			Error: "Failed to cancel insight",
+		})
+	}
+}
+
+func (h *InsightHandler) DeleteInsight(c *gin.Context) {
+	var uriReq models.DeleteInsightRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.DeleteInsightResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	id, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.DeleteInsightResponse{
+			Error: "Invalid insight ID",
+		})
+		return
+	}
+	err = h.insightService.DeleteInsight(c.Request.Context(), id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, &models.DeleteInsightResponse{
+			Error: "Insight not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.DeleteInsightResponse{
+			Error: "Failed to delete insight",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.DeleteInsightResponse{
+		Error: "",
+	})
+}
+
+// insightEventKeepAlive is how often a comment is sent on an idle event stream so
+// proxies do not close it.
+const insightEventKeepAlive = 15 * time.Second
+
+// WatchInsight streams the progress of an insight as Server-Sent Events: a "snapshot"
+// event with the current insight, then "status" and "batch" events until the insight
+// reaches a final status.
+func (h *InsightHandler) WatchInsight(c *gin.Context) {
+	var req models.WatchInsightRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	insightID, err := bson.ObjectIDFromHex(req.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightResponse{
+			Error: "Invalid insight ID",
+		})
+		return
+	}
+	insight, events, err := h.insightService.WatchInsight(c.Request.Context(), insightID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, &models.GetInsightResponse{
+			Error: "Insight not found",
+		})
+		return
+	}
+	if err != nil {
+		fmt.Println("Error watching insight:", err)
+		c.JSON(http.StatusInternalServerError, &models.GetInsightResponse{
+			Error: "Failed to watch insight",
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("snapshot", insight)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(insightEventKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(c.Writer, ": keep-alive\n\n")
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			c.SSEvent(string(event.Type), event)
+		}
+		c.Writer.Flush()
+	}
+}
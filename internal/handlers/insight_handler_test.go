@@ -5,17 +5,20 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"osp/internal/models"
+	"osp/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hibiken/asynq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 // MockInsightService is a mock implementation of IInsightService
@@ -47,8 +50,53 @@ func (m *MockInsightService) GetInsight(ctx context.Context, id bson.ObjectID) (
 	return args.Get(0).(*models.Insight), args.Error(1)
 }
 
-func (m *MockInsightService) ProcessInsight(insightID bson.ObjectID) error {
-	args := m.Called(insightID)
+func (m *MockInsightService) GetUnredactedInsight(ctx context.Context, id bson.ObjectID) (*models.Insight, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Insight), args.Error(1)
+}
+
+func (m *MockInsightService) RetryInsight(ctx context.Context, id bson.ObjectID) (*models.Insight, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Insight), args.Error(1)
+}
+
+func (m *MockInsightService) RegenerateInsight(ctx context.Context, id bson.ObjectID) (*models.Insight, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Insight), args.Error(1)
+}
+
+func (m *MockInsightService) CancelInsight(ctx context.Context, id bson.ObjectID) (*models.Insight, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Insight), args.Error(1)
+}
+
+func (m *MockInsightService) DeleteInsight(ctx context.Context, id bson.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockInsightService) WatchInsight(ctx context.Context, id bson.ObjectID) (*models.Insight, <-chan *models.InsightEvent, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*models.Insight), args.Get(1).(<-chan *models.InsightEvent), args.Error(2)
+}
+
+func (m *MockInsightService) ProcessInsight(ctx context.Context, insightID bson.ObjectID) error {
+	args := m.Called(ctx, insightID)
 	return args.Error(0)
 }
 
@@ -113,6 +161,52 @@ func TestCreateInsight(t *testing.T) {
 	})
 }
 
+func TestCreateInsight_InvalidFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockInsightService)
+	handler := NewInsightHandler(mockService)
+	router := gin.Default()
+	router.POST("/insights", handler.CreateInsight)
+
+	reqBody := models.CreateInsightRequest{
+		SurveyID:    bson.NewObjectID(),
+		ContextType: models.CourseFeedbackContext,
+		Filter:      &models.SubmissionFilter{Metadata: map[string]string{"$where": "1"}},
+	}
+	mockService.On("CreateInsight", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: invalid metadata key", services.ErrInvalidInsightFilter))
+
+	body, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest("POST", "/insights", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateInsight_OverBudget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockInsightService)
+	handler := NewInsightHandler(mockService)
+	router := gin.Default()
+	router.POST("/insights", handler.CreateInsight)
+
+	reqBody := models.CreateInsightRequest{
+		SurveyID:    bson.NewObjectID(),
+		ContextType: models.CourseFeedbackContext,
+	}
+	mockService.On("CreateInsight", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: estimated 5000 tokens, 1000 remaining", services.ErrInsightOverBudget))
+
+	body, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest("POST", "/insights", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "1000 remaining")
+}
+
 func TestGetInsights(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -136,3 +230,247 @@ func TestGetInsights(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 }
+
+func TestGetInsight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	insightID := bson.NewObjectID()
+	setup := func() (*MockInsightService, *gin.Engine) {
+		mockService := new(MockInsightService)
+		handler := NewInsightHandler(mockService)
+		router := gin.Default()
+		router.GET("/insights/:id", handler.GetInsight)
+		return mockService, router
+	}
+
+	t.Run("Redacted", func(t *testing.T) {
+		mockService, router := setup()
+		mockService.On("GetInsight", mock.Anything, insightID).Return(&models.Insight{ID: insightID, Analysis: "Contact [EMAIL_1]"}, nil)
+
+		req, _ := http.NewRequest("GET", "/insights/"+insightID.Hex(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "[EMAIL_1]")
+		mockService.AssertNotCalled(t, "GetUnredactedInsight", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unredacted", func(t *testing.T) {
+		mockService, router := setup()
+		mockService.On("GetUnredactedInsight", mock.Anything, insightID).Return(&models.Insight{ID: insightID, Analysis: "Contact a@b.io"}, nil)
+
+		req, _ := http.NewRequest("GET", "/insights/"+insightID.Hex()+"?unredacted=true", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "a@b.io")
+		mockService.AssertNotCalled(t, "GetInsight", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRetryInsight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockInsightService)
+		handler := NewInsightHandler(mockService)
+		router := gin.Default()
+		router.POST("/insights/:id/retry", handler.RetryInsight)
+
+		insightID := bson.NewObjectID()
+		mockService.On("RetryInsight", mock.Anything, insightID).Return(&models.Insight{ID: insightID, Status: models.InsightPending}, nil)
+
+		req, _ := http.NewRequest("POST", "/insights/"+insightID.Hex()+"/retry", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("NothingToRetry", func(t *testing.T) {
+		mockService := new(MockInsightService)
+		handler := NewInsightHandler(mockService)
+		router := gin.Default()
+		router.POST("/insights/:id/retry", handler.RetryInsight)
+
+		mockService.On("RetryInsight", mock.Anything, mock.Anything).Return(nil, services.ErrInsightNothingToRetry)
+
+		req, _ := http.NewRequest("POST", "/insights/"+bson.NewObjectID().Hex()+"/retry", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService := new(MockInsightService)
+		handler := NewInsightHandler(mockService)
+		router := gin.Default()
+		router.POST("/insights/:id/retry", handler.RetryInsight)
+
+		mockService.On("RetryInsight", mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments)
+
+		req, _ := http.NewRequest("POST", "/insights/"+bson.NewObjectID().Hex()+"/retry", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestRegenerateInsight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockInsightService)
+		handler := NewInsightHandler(mockService)
+		router := gin.Default()
+		router.POST("/insights/:id/regenerate", handler.RegenerateInsight)
+
+		insightID := bson.NewObjectID()
+		mockService.On("RegenerateInsight", mock.Anything, insightID).Return(&models.Insight{ID: insightID}, nil)
+
+		req, _ := http.NewRequest("POST", "/insights/"+insightID.Hex()+"/regenerate", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InProgress", func(t *testing.T) {
+		mockService := new(MockInsightService)
+		handler := NewInsightHandler(mockService)
+		router := gin.Default()
+		router.POST("/insights/:id/regenerate", handler.RegenerateInsight)
+
+		mockService.On("RegenerateInsight", mock.Anything, mock.Anything).Return(nil, services.ErrInsightInProgress)
+
+		req, _ := http.NewRequest("POST", "/insights/"+bson.NewObjectID().Hex()+"/regenerate", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestCancelInsight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockInsightService)
+		handler := NewInsightHandler(mockService)
+		router := gin.Default()
+		router.POST("/insights/:id/cancel", handler.CancelInsight)
+
+		insightID := bson.NewObjectID()
+		mockService.On("CancelInsight", mock.Anything, insightID).Return(&models.Insight{ID: insightID, Status: models.InsightCancelled}, nil)
+
+		req, _ := http.NewRequest("POST", "/insights/"+insightID.Hex()+"/cancel", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("NotCancellable", func(t *testing.T) {
+		mockService := new(MockInsightService)
+		handler := NewInsightHandler(mockService)
+		router := gin.Default()
+		router.POST("/insights/:id/cancel", handler.CancelInsight)
+
+		mockService.On("CancelInsight", mock.Anything, mock.Anything).Return(nil, services.ErrInsightNotCancellable)
+
+		req, _ := http.NewRequest("POST", "/insights/"+bson.NewObjectID().Hex()+"/cancel", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestDeleteInsight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockInsightService)
+		handler := NewInsightHandler(mockService)
+		router := gin.Default()
+		router.DELETE("/insights/:id", handler.DeleteInsight)
+
+		insightID := bson.NewObjectID()
+		mockService.On("DeleteInsight", mock.Anything, insightID).Return(nil)
+
+		req, _ := http.NewRequest("DELETE", "/insights/"+insightID.Hex(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService := new(MockInsightService)
+		handler := NewInsightHandler(mockService)
+		router := gin.Default()
+		router.DELETE("/insights/:id", handler.DeleteInsight)
+
+		mockService.On("DeleteInsight", mock.Anything, mock.Anything).Return(mongo.ErrNoDocuments)
+
+		req, _ := http.NewRequest("DELETE", "/insights/"+bson.NewObjectID().Hex(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestWatchInsight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockInsightService)
+		handler := NewInsightHandler(mockService)
+		router := gin.Default()
+		router.GET("/insights/:id/events", handler.WatchInsight)
+
+		insightID := bson.NewObjectID()
+		events := make(chan *models.InsightEvent, 2)
+		events <- &models.InsightEvent{Type: models.InsightEventBatch, InsightID: insightID, BatchNumber: 1}
+		events <- &models.InsightEvent{Type: models.InsightEventStatus, InsightID: insightID, Status: models.InsightCompleted}
+		close(events)
+		mockService.On("WatchInsight", mock.Anything, insightID).
+			Return(&models.Insight{ID: insightID, Status: models.InsightProcessing}, (<-chan *models.InsightEvent)(events), nil)
+
+		req, _ := http.NewRequest("GET", "/insights/"+insightID.Hex()+"/events", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		body := w.Body.String()
+		assert.Contains(t, body, "event:snapshot")
+		assert.Contains(t, body, "event:batch")
+		assert.Contains(t, body, "event:status")
+		assert.Contains(t, body, `"status":"COMPLETED"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService := new(MockInsightService)
+		handler := NewInsightHandler(mockService)
+		router := gin.Default()
+		router.GET("/insights/:id/events", handler.WatchInsight)
+
+		mockService.On("WatchInsight", mock.Anything, mock.Anything).Return(nil, nil, mongo.ErrNoDocuments)
+
+		req, _ := http.NewRequest("GET", "/insights/"+bson.NewObjectID().Hex()+"/events", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type InsightScheduleHandler struct {
+	scheduleService services.IInsightScheduleService
+}
+
+func NewInsightScheduleHandler(scheduleService services.IInsightScheduleService) *InsightScheduleHandler {
+	return &InsightScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+// isInvalidSchedule reports whether err is caused by the schedule settings sent
+func isInvalidSchedule(err error) bool {
+	return errors.Is(err, services.ErrInvalidInsightSchedule) || errors.Is(err, services.ErrInvalidInsightFilter)
+}
+
+func (h *InsightScheduleHandler) CreateSchedule(c *gin.Context) {
+	var req models.CreateInsightScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.CreateInsightScheduleResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	schedule, err := h.scheduleService.CreateSchedule(c.Request.Context(), &req)
+	if isInvalidSchedule(err) {
+		c.JSON(http.StatusBadRequest, &models.CreateInsightScheduleResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.CreateInsightScheduleResponse{
+			Error: "Failed to create insight schedule",
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, &models.CreateInsightScheduleResponse{
+		Data: schedule,
+	})
+}
+
+func (h *InsightScheduleHandler) GetSchedules(c *gin.Context) {
+	var req models.GetInsightSchedulesRequest
+	if err := c.BindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightSchedulesResponse{
+			Error: "Invalid query parameters",
+		})
+		return
+	}
+	var surveyID *bson.ObjectID
+	if req.SurveyID != nil {
+		id, err := bson.ObjectIDFromHex(*req.SurveyID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, &models.GetInsightSchedulesResponse{
+				Error: "Invalid survey ID",
+			})
+			return
+		}
+		surveyID = &id
+	}
+	schedules, err := h.scheduleService.GetSchedules(c.Request.Context(), req.Offset, req.Limit, surveyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.GetInsightSchedulesResponse{
+			Error: "Failed to retrieve insight schedules",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.GetInsightSchedulesResponse{
+		Data: schedules,
+	})
+}
+
+func (h *InsightScheduleHandler) GetSchedule(c *gin.Context) {
+	var uriReq models.GetInsightScheduleRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightScheduleResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	id, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightScheduleResponse{
+			Error: "Invalid insight schedule ID",
+		})
+		return
+	}
+	schedule, err := h.scheduleService.GetSchedule(c.Request.Context(), id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, &models.GetInsightScheduleResponse{
+			Error: "Insight schedule not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.GetInsightScheduleResponse{
+			Error: "Failed to retrieve insight schedule",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.GetInsightScheduleResponse{
+		Data: schedule,
+	})
+}
+
+func (h *InsightScheduleHandler) UpdateSchedule(c *gin.Context) {
+	var uriReq models.GetInsightScheduleRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.UpdateInsightScheduleResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	id, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.UpdateInsightScheduleResponse{
+			Error: "Invalid insight schedule ID",
+		})
+		return
+	}
+	var req models.InsightScheduleInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.UpdateInsightScheduleResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	schedule, err := h.scheduleService.UpdateSchedule(c.Request.Context(), id, &req)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, &models.UpdateInsightScheduleResponse{
+			Error: "Insight schedule not found",
+		})
+		return
+	}
+	if isInvalidSchedule(err) {
+		c.JSON(http.StatusBadRequest, &models.UpdateInsightScheduleResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.UpdateInsightScheduleResponse{
+			Error: "Failed to update insight schedule",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.UpdateInsightScheduleResponse{
+		Data: schedule,
+	})
+}
+
+func (h *InsightScheduleHandler) DeleteSchedule(c *gin.Context) {
+	var uriReq models.GetInsightScheduleRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.DeleteInsightScheduleResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	id, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.DeleteInsightScheduleResponse{
+			Error: "Invalid insight schedule ID",
+		})
+		return
+	}
+	err = h.scheduleService.DeleteSchedule(c.Request.Context(), id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, &models.DeleteInsightScheduleResponse{
+			Error: "Insight schedule not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.DeleteInsightScheduleResponse{
+			Error: "Failed to delete insight schedule",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.DeleteInsightScheduleResponse{})
+}
+
+func (h *InsightScheduleHandler) GetRuns(c *gin.Context) {
+	var uriReq models.GetInsightScheduleRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightScheduleRunsResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	id, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightScheduleRunsResponse{
+			Error: "Invalid insight schedule ID",
+		})
+		return
+	}
+	var req models.GetInsightScheduleRunsRequest
+	if err := c.BindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetInsightScheduleRunsResponse{
+			Error: "Invalid query parameters",
+		})
+		return
+	}
+	runs, err := h.scheduleService.GetRuns(c.Request.Context(), id, req.Offset, req.Limit)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, &models.GetInsightScheduleRunsResponse{
+			Error: "Insight schedule not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.GetInsightScheduleRunsResponse{
+			Error: "Failed to retrieve insight schedule runs",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.GetInsightScheduleRunsResponse{
+		Data: runs,
+	})
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MockInsightScheduleService is a mock implementation of IInsightScheduleService
+type MockInsightScheduleService struct {
+	mock.Mock
+}
+
+func (m *MockInsightScheduleService) CreateSchedule(ctx context.Context, req *models.CreateInsightScheduleRequest) (*models.InsightSchedule, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InsightSchedule), args.Error(1)
+}
+
+func (m *MockInsightScheduleService) GetSchedules(ctx context.Context, offset, limit int64, surveyID *bson.ObjectID) ([]*models.InsightSchedule, error) {
+	args := m.Called(ctx, offset, limit, surveyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.InsightSchedule), args.Error(1)
+}
+
+func (m *MockInsightScheduleService) GetSchedule(ctx context.Context, id bson.ObjectID) (*models.InsightSchedule, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InsightSchedule), args.Error(1)
+}
+
+func (m *MockInsightScheduleService) UpdateSchedule(ctx context.Context, id bson.ObjectID, req *models.InsightScheduleInput) (*models.InsightSchedule, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InsightSchedule), args.Error(1)
+}
+
+func (m *MockInsightScheduleService) DeleteSchedule(ctx context.Context, id bson.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockInsightScheduleService) GetRuns(ctx context.Context, id bson.ObjectID, offset, limit int64) ([]*models.InsightScheduleRun, error) {
+	args := m.Called(ctx, id, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.InsightScheduleRun), args.Error(1)
+}
+
+func TestCreateInsightSchedule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setup := func() (*MockInsightScheduleService, *gin.Engine) {
+		mockService := new(MockInsightScheduleService)
+		handler := NewInsightScheduleHandler(mockService)
+		router := gin.Default()
+		router.POST("/insight-schedules", handler.CreateSchedule)
+		return mockService, router
+	}
+	create := func(router *gin.Engine, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/insight-schedules", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	surveyID := bson.NewObjectID()
+	body := `{"survey_id":"` + surveyID.Hex() + `","context_type":"EMPLOYEE_ENGAGEMENT","cron":"0 9 * * MON","only_new_submissions":true}`
+
+	t.Run("Success", func(t *testing.T) {
+		mockService, router := setup()
+		mockService.On("CreateSchedule", mock.Anything, mock.MatchedBy(func(req *models.CreateInsightScheduleRequest) bool {
+			return req.SurveyID == surveyID && req.Cron == "0 9 * * MON" && req.OnlyNewSubmissions && req.Enabled == nil
+		})).Return(&models.InsightSchedule{ID: bson.NewObjectID(), SurveyID: surveyID, Enabled: true}, nil)
+
+		w := create(router, body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("MissingCron", func(t *testing.T) {
+		mockService, router := setup()
+
+		w := create(router, `{"survey_id":"`+surveyID.Hex()+`","context_type":"EMPLOYEE_ENGAGEMENT"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "CreateSchedule")
+	})
+
+	t.Run("InvalidSchedule", func(t *testing.T) {
+		mockService, router := setup()
+		mockService.On("CreateSchedule", mock.Anything, mock.Anything).
+			Return(nil, fmt.Errorf("%w: invalid cron expression", services.ErrInvalidInsightSchedule))
+
+		w := create(router, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid cron expression")
+	})
+
+	t.Run("InvalidFilter", func(t *testing.T) {
+		mockService, router := setup()
+		mockService.On("CreateSchedule", mock.Anything, mock.Anything).
+			Return(nil, fmt.Errorf("%w: from must be before to", services.ErrInvalidInsightFilter))
+
+		w := create(router, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestUpdateInsightSchedule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setup := func() (*MockInsightScheduleService, *gin.Engine) {
+		mockService := new(MockInsightScheduleService)
+		handler := NewInsightScheduleHandler(mockService)
+		router := gin.Default()
+		router.PUT("/insight-schedules/:id", handler.UpdateSchedule)
+		return mockService, router
+	}
+	update := func(router *gin.Engine, id bson.ObjectID) *httptest.ResponseRecorder {
+		body := `{"context_type":"EMPLOYEE_ENGAGEMENT","cron":"@weekly","enabled":false}`
+		req, _ := http.NewRequest("PUT", "/insight-schedules/"+id.Hex(), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockService, router := setup()
+		id := bson.NewObjectID()
+		mockService.On("UpdateSchedule", mock.Anything, id, mock.MatchedBy(func(req *models.InsightScheduleInput) bool {
+			return req.Cron == "@weekly" && req.Enabled != nil && !*req.Enabled
+		})).Return(&models.InsightSchedule{ID: id, Cron: "@weekly"}, nil)
+
+		w := update(router, id)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"cron":"@weekly"`)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService, router := setup()
+		mockService.On("UpdateSchedule", mock.Anything, mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments)
+
+		w := update(router, bson.NewObjectID())
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestGetInsightScheduleRuns(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setup := func() (*MockInsightScheduleService, *gin.Engine) {
+		mockService := new(MockInsightScheduleService)
+		handler := NewInsightScheduleHandler(mockService)
+		router := gin.Default()
+		router.GET("/insight-schedules/:id/runs", handler.GetRuns)
+		return mockService, router
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockService, router := setup()
+		id := bson.NewObjectID()
+		mockService.On("GetRuns", mock.Anything, id, int64(0), int64(5)).
+			Return([]*models.InsightScheduleRun{{ID: bson.NewObjectID(), ScheduleID: id, Status: models.InsightScheduleRunSkipped}}, nil)
+
+		req, _ := http.NewRequest("GET", "/insight-schedules/"+id.Hex()+"/runs?limit=5", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"SKIPPED"`)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		mockService, router := setup()
+
+		req, _ := http.NewRequest("GET", "/insight-schedules/nope/runs", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetRuns")
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService, router := setup()
+		mockService.On("GetRuns", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments)
+
+		req, _ := http.NewRequest("GET", "/insight-schedules/"+bson.NewObjectID().Hex()+"/runs", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestDeleteInsightSchedule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockInsightScheduleService)
+	handler := NewInsightScheduleHandler(mockService)
+	router := gin.Default()
+	router.DELETE("/insight-schedules/:id", handler.DeleteSchedule)
+
+	id := bson.NewObjectID()
+	mockService.On("DeleteSchedule", mock.Anything, id).Return(nil)
+	mockService.On("DeleteSchedule", mock.Anything, mock.Anything).Return(mongo.ErrNoDocuments)
+
+	req, _ := http.NewRequest("DELETE", "/insight-schedules/"+id.Hex(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("DELETE", "/insight-schedules/"+bson.NewObjectID().Hex(), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"osp/internal/models"
 	"osp/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type SubmissionHandler struct {
@@ -58,7 +60,21 @@ func (h *SubmissionHandler) GetSubmissions(c *gin.Context) {
 		}
 		surveyID = &id
 	}
-	submissions, err := h.submissionService.GetSubmissions(c.Request.Context(), req.Offset, req.Limit, surveyID)
+	var sentiment *models.SentimentFilter
+	if req.Sentiment != "" {
+		sentiment = &models.SentimentFilter{Label: models.SentimentLabel(req.Sentiment)}
+		if req.QuestionID != nil {
+			id, err := bson.ObjectIDFromHex(*req.QuestionID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, &models.GetSubmissionsResponse{
+					Error: "Invalid question ID",
+				})
+				return
+			}
+			sentiment.QuestionID = &id
+		}
+	}
+	submissions, err := h.submissionService.GetSubmissions(c.Request.Context(), req.Offset, req.Limit, surveyID, sentiment)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, &models.GetSubmissionsResponse{
 			Error: "Failed to retrieve submissions",
@@ -70,6 +86,40 @@ func (h *SubmissionHandler) GetSubmissions(c *gin.Context) {
 	})
 }
 
+// GetSubmission returns a single submission, e.g. one cited by an insight
+func (h *SubmissionHandler) GetSubmission(c *gin.Context) {
+	var uriReq models.GetSubmissionRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetSubmissionResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	id, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetSubmissionResponse{
+			Error: "Invalid submission ID",
+		})
+		return
+	}
+	submission, err := h.submissionService.GetSubmission(c.Request.Context(), id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, &models.GetSubmissionResponse{
+			Error: "Submission not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.GetSubmissionResponse{
+			Error: "Failed to retrieve submission",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.GetSubmissionResponse{
+		Data: submission,
+	})
+}
+
 func (h *SubmissionHandler) DeleteSubmission(c *gin.Context) {
 	var uriReq models.DeleteSubmissionRequest
 	if err := c.ShouldBindUri(&uriReq); err != nil {
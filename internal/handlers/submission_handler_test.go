@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 // MockSubmissionService is a mock implementation of ISubmissionService
@@ -38,8 +39,8 @@ func (m *MockSubmissionService) GetAllSubmissions(ctx context.Context, surveyID
 	return args.Get(0).([]*models.Submission), args.Error(1)
 }
 
-func (m *MockSubmissionService) GetSubmissions(ctx context.Context, offset int64, limit int64, surveyID *bson.ObjectID) ([]*models.Submission, error) {
-	args := m.Called(ctx, offset, limit, surveyID)
+func (m *MockSubmissionService) GetSubmissions(ctx context.Context, offset int64, limit int64, surveyID *bson.ObjectID, sentiment *models.SentimentFilter) ([]*models.Submission, error) {
+	args := m.Called(ctx, offset, limit, surveyID, sentiment)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -54,6 +55,14 @@ func (m *MockSubmissionService) GetBySurveyID(ctx context.Context, surveyID bson
 	return args.Get(0).([]*models.Submission), args.Error(1)
 }
 
+func (m *MockSubmissionService) GetSubmission(ctx context.Context, id bson.ObjectID) (*models.Submission, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Submission), args.Error(1)
+}
+
 func (m *MockSubmissionService) Delete(ctx context.Context, id bson.ObjectID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -131,3 +140,90 @@ func TestCreateSubmission(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
 }
+
+func TestGetSubmission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockSubmissionService)
+		handler := NewSubmissionHandler(mockService)
+		router := gin.Default()
+		router.GET("/submissions/:id", handler.GetSubmission)
+
+		submission := &models.Submission{ID: bson.NewObjectID()}
+		mockService.On("GetSubmission", mock.Anything, submission.ID).Return(submission, nil)
+
+		req, _ := http.NewRequest("GET", "/submissions/"+submission.ID.Hex(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService := new(MockSubmissionService)
+		handler := NewSubmissionHandler(mockService)
+		router := gin.Default()
+		router.GET("/submissions/:id", handler.GetSubmission)
+
+		id := bson.NewObjectID()
+		mockService.On("GetSubmission", mock.Anything, id).Return(nil, mongo.ErrNoDocuments)
+
+		req, _ := http.NewRequest("GET", "/submissions/"+id.Hex(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		mockService := new(MockSubmissionService)
+		handler := NewSubmissionHandler(mockService)
+		router := gin.Default()
+		router.GET("/submissions/:id", handler.GetSubmission)
+
+		req, _ := http.NewRequest("GET", "/submissions/invalid", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGetSubmissions_Sentiment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockSubmissionService)
+		handler := NewSubmissionHandler(mockService)
+		router := gin.Default()
+		router.GET("/submissions", handler.GetSubmissions)
+
+		questionID := bson.NewObjectID()
+		mockService.On("GetSubmissions", mock.Anything, int64(0), int64(10), (*bson.ObjectID)(nil), &models.SentimentFilter{
+			Label:      models.SentimentNegative,
+			QuestionID: &questionID,
+		}).Return([]*models.Submission{}, nil)
+
+		req, _ := http.NewRequest("GET", "/submissions?sentiment=NEGATIVE&questionId="+questionID.Hex(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidLabel", func(t *testing.T) {
+		mockService := new(MockSubmissionService)
+		handler := NewSubmissionHandler(mockService)
+		router := gin.Default()
+		router.GET("/submissions", handler.GetSubmissions)
+
+		req, _ := http.NewRequest("GET", "/submissions?sentiment=ANGRY", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
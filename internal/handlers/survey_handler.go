@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"osp/internal/models"
 	"osp/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type SurveyHandler struct {
@@ -147,3 +149,83 @@ func (h *SurveyHandler) DeleteSurvey(c *gin.Context) {
 	}
 	c.Status(http.StatusNoContent)
 }
+
+func (h *SurveyHandler) SetTokenBudget(c *gin.Context) {
+	var uriReq models.SetSurveyTokenBudgetRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.SetSurveyTokenBudgetResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	surveyID, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.SetSurveyTokenBudgetResponse{
+			Error: "Invalid survey ID",
+		})
+		return
+	}
+	var budget models.TokenBudget
+	if err := c.ShouldBindJSON(&budget); err != nil {
+		c.JSON(http.StatusBadRequest, &models.SetSurveyTokenBudgetResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	survey, err := h.surveyService.SetTokenBudget(c.Request.Context(), surveyID, &budget)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, &models.SetSurveyTokenBudgetResponse{
+			Error: "Survey not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.SetSurveyTokenBudgetResponse{
+			Error: "Failed to update token budget",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.SetSurveyTokenBudgetResponse{
+		Data: survey,
+	})
+}
+
+func (h *SurveyHandler) SetRedaction(c *gin.Context) {
+	var uriReq models.SetSurveyRedactionRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.SetSurveyRedactionResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	surveyID, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.SetSurveyRedactionResponse{
+			Error: "Invalid survey ID",
+		})
+		return
+	}
+	var settings models.RedactionSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, &models.SetSurveyRedactionResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	survey, err := h.surveyService.SetRedaction(c.Request.Context(), surveyID, &settings)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, &models.SetSurveyRedactionResponse{
+			Error: "Survey not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.SetSurveyRedactionResponse{
+			Error: "Failed to update redaction settings",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.SetSurveyRedactionResponse{
+		Data: survey,
+	})
+}
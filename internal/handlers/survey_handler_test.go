@@ -7,6 +7,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"osp/internal/models"
@@ -15,6 +16,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 // MockSurveyService is a mock implementation of ISurveyService
@@ -59,6 +61,22 @@ func (m *MockSurveyService) DeleteSurvey(ctx context.Context, id bson.ObjectID)
 	return args.Error(0)
 }
 
+func (m *MockSurveyService) SetTokenBudget(ctx context.Context, id bson.ObjectID, budget *models.TokenBudget) (*models.Survey, error) {
+	args := m.Called(ctx, id, budget)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Survey), args.Error(1)
+}
+
+func (m *MockSurveyService) SetRedaction(ctx context.Context, id bson.ObjectID, settings *models.RedactionSettings) (*models.Survey, error) {
+	args := m.Called(ctx, id, settings)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Survey), args.Error(1)
+}
+
 func TestCreateSurvey(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -262,3 +280,100 @@ func TestDeleteSurvey(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
 }
+
+func TestSetSurveyTokenBudget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockSurveyService)
+		handler := NewSurveyHandler(mockService)
+		router := gin.Default()
+		router.PUT("/surveys/:id/token-budget", handler.SetTokenBudget)
+		surveyID := bson.NewObjectID()
+		budget := &models.TokenBudget{Daily: 5000, Monthly: 100000}
+		mockService.On("SetTokenBudget", mock.Anything, surveyID, budget).Return(&models.Survey{ID: surveyID, TokenBudget: budget}, nil)
+		req, _ := http.NewRequest("PUT", "/surveys/"+surveyID.Hex()+"/token-budget", bytes.NewBufferString(`{"daily":5000,"monthly":100000}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("NegativeLimit", func(t *testing.T) {
+		mockService := new(MockSurveyService)
+		handler := NewSurveyHandler(mockService)
+		router := gin.Default()
+		router.PUT("/surveys/:id/token-budget", handler.SetTokenBudget)
+		req, _ := http.NewRequest("PUT", "/surveys/"+bson.NewObjectID().Hex()+"/token-budget", bytes.NewBufferString(`{"daily":-1}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService := new(MockSurveyService)
+		handler := NewSurveyHandler(mockService)
+		router := gin.Default()
+		router.PUT("/surveys/:id/token-budget", handler.SetTokenBudget)
+		mockService.On("SetTokenBudget", mock.Anything, mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments)
+		req, _ := http.NewRequest("PUT", "/surveys/"+bson.NewObjectID().Hex()+"/token-budget", bytes.NewBufferString(`{"daily":5000}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSetSurveyRedaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockSurveyService)
+		handler := NewSurveyHandler(mockService)
+		router := gin.Default()
+		router.PUT("/surveys/:id/redaction", handler.SetRedaction)
+		surveyID := bson.NewObjectID()
+		settings := &models.RedactionSettings{Enabled: true, Terms: []string{"Dr Lee"}}
+		mockService.On("SetRedaction", mock.Anything, surveyID, settings).Return(&models.Survey{ID: surveyID, Redaction: settings}, nil)
+		req, _ := http.NewRequest("PUT", "/surveys/"+surveyID.Hex()+"/redaction", bytes.NewBufferString(`{"enabled":true,"terms":["Dr Lee"]}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("TermTooLong", func(t *testing.T) {
+		mockService := new(MockSurveyService)
+		handler := NewSurveyHandler(mockService)
+		router := gin.Default()
+		router.PUT("/surveys/:id/redaction", handler.SetRedaction)
+		body := `{"enabled":true,"terms":["` + strings.Repeat("a", 101) + `"]}`
+		req, _ := http.NewRequest("PUT", "/surveys/"+bson.NewObjectID().Hex()+"/redaction", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "SetRedaction", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		mockService := new(MockSurveyService)
+		handler := NewSurveyHandler(mockService)
+		router := gin.Default()
+		router.PUT("/surveys/:id/redaction", handler.SetRedaction)
+		req, _ := http.NewRequest("PUT", "/surveys/invalid/redaction", bytes.NewBufferString(`{"enabled":true}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService := new(MockSurveyService)
+		handler := NewSurveyHandler(mockService)
+		router := gin.Default()
+		router.PUT("/surveys/:id/redaction", handler.SetRedaction)
+		mockService.On("SetRedaction", mock.Anything, mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments)
+		req, _ := http.NewRequest("PUT", "/surveys/"+bson.NewObjectID().Hex()+"/redaction", bytes.NewBufferString(`{"enabled":false}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type TextAnalyticsHandler struct {
+	textAnalyticsService services.ITextAnalyticsService
+}
+
+func NewTextAnalyticsHandler(textAnalyticsService services.ITextAnalyticsService) *TextAnalyticsHandler {
+	return &TextAnalyticsHandler{
+		textAnalyticsService: textAnalyticsService,
+	}
+}
+
+func (h *TextAnalyticsHandler) GetTextAnalytics(c *gin.Context) {
+	var uriReq models.GetTextAnalyticsRequest
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetTextAnalyticsResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	surveyID, err := bson.ObjectIDFromHex(uriReq.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetTextAnalyticsResponse{
+			Error: "Invalid survey ID",
+		})
+		return
+	}
+	questionID, err := bson.ObjectIDFromHex(uriReq.QuestionID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetTextAnalyticsResponse{
+			Error: "Invalid question ID",
+		})
+		return
+	}
+	var req models.TextAnalyticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetTextAnalyticsResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	analytics, err := h.textAnalyticsService.AnalyzeQuestion(c.Request.Context(), surveyID, questionID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTextAnalytics) {
+			c.JSON(http.StatusBadRequest, &models.GetTextAnalyticsResponse{
+				Error: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, &models.GetTextAnalyticsResponse{
+			Error: "Failed to analyze answers",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.GetTextAnalyticsResponse{
+		Data: analytics,
+	})
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type UsageHandler struct {
+	usageService services.IUsageService
+}
+
+func NewUsageHandler(usageService services.IUsageService) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+	}
+}
+
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	var req models.GetUsageRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, &models.GetUsageResponse{
+			Error: "Invalid query parameters",
+		})
+		return
+	}
+
+	var filter models.UsageFilter
+	if req.From != nil {
+		filter.From = *req.From
+	}
+	if req.To != nil {
+		// The to date is inclusive.
+		filter.To = req.To.AddDate(0, 0, 1)
+	}
+	if req.SurveyID != nil {
+		surveyID, err := bson.ObjectIDFromHex(*req.SurveyID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, &models.GetUsageResponse{
+				Error: "Invalid survey ID",
+			})
+			return
+		}
+		filter.SurveyID = &surveyID
+	}
+
+	report, err := h.usageService.GetUsage(c.Request.Context(), filter)
+	if errors.Is(err, services.ErrInvalidUsageRange) {
+		c.JSON(http.StatusBadRequest, &models.GetUsageResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.GetUsageResponse{
+			Error: "Failed to retrieve usage",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, &models.GetUsageResponse{
+		Data: report,
+	})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"osp/internal/models"
+	"osp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// MockUsageService is a mock implementation of IUsageService
+type MockUsageService struct {
+	mock.Mock
+}
+
+func (m *MockUsageService) GetUsage(ctx context.Context, filter models.UsageFilter) (*models.UsageReport, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UsageReport), args.Error(1)
+}
+
+func TestGetUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockUsageService)
+		handler := NewUsageHandler(mockService)
+		router := gin.Default()
+		router.GET("/usage", handler.GetUsage)
+
+		surveyID := bson.NewObjectID()
+		mockService.On("GetUsage", mock.Anything, mock.MatchedBy(func(filter models.UsageFilter) bool {
+			return filter.From.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) &&
+				filter.To.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) &&
+				filter.SurveyID != nil && *filter.SurveyID == surveyID
+		})).Return(&models.UsageReport{}, nil)
+
+		req, _ := http.NewRequest("GET", "/usage?from=2026-03-01&to=2026-03-31&surveyId="+surveyID.Hex(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		mockService := new(MockUsageService)
+		handler := NewUsageHandler(mockService)
+		router := gin.Default()
+		router.GET("/usage", handler.GetUsage)
+
+		req, _ := http.NewRequest("GET", "/usage?from=yesterday", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetUsage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("InvalidRange", func(t *testing.T) {
+		mockService := new(MockUsageService)
+		handler := NewUsageHandler(mockService)
+		router := gin.Default()
+		router.GET("/usage", handler.GetUsage)
+
+		mockService.On("GetUsage", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: from must be before to", services.ErrInvalidUsageRange))
+
+		req, _ := http.NewRequest("GET", "/usage?from=2026-03-31&to=2026-03-01", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("ServiceError", func(t *testing.T) {
+		mockService := new(MockUsageService)
+		handler := NewUsageHandler(mockService)
+		router := gin.Default()
+		router.GET("/usage", handler.GetUsage)
+
+		mockService.On("GetUsage", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		req, _ := http.NewRequest("GET", "/usage", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
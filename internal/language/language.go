@@ -0,0 +1,96 @@
+package language
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Language codes, as in BCP 47
+const (
+	English      = "en"
+	Cantonese    = "yue"
+	Chinese      = "zh" // written Chinese without Cantonese particles, usually Mandarin
+	Japanese     = "ja"
+	Korean       = "ko"
+	Undetermined = "und"
+)
+
+var names = map[string]string{
+	English:   "English",
+	Cantonese: "Cantonese",
+	Chinese:   "Chinese",
+	Japanese:  "Japanese",
+	Korean:    "Korean",
+}
+
+// Name returns the English name of a language code, or the code itself if unknown
+func Name(code string) string {
+	if name, ok := names[code]; ok {
+		return name
+	}
+	return code
+}
+
+// cantoneseMarkers are characters of written Cantonese that standard written Chinese
+// does not use; one is enough to tell the two apart. Cantonese characters that also
+// occur in common standard Chinese words are left out: 係 (關係), 俾 (俾使), 乜 (a
+// surname), 畀 (畀予) and 囉 (囉嗦).
+const cantoneseMarkers = "嘅咗唔喺冇佢哋啲嘢嚟睇咁嗰咩啱搵喎㗎嘞噉嚿攰"
+
+// englishWords are common function words; Latin text without any is left undetermined
+var englishWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "but": true, "of": true,
+	"to": true, "in": true, "on": true, "for": true, "with": true, "is": true, "are": true,
+	"was": true, "were": true, "be": true, "it": true, "this": true, "that": true, "i": true,
+	"we": true, "you": true, "they": true, "my": true, "our": true, "not": true, "no": true,
+	"very": true, "too": true, "more": true, "have": true, "has": true, "at": true, "so": true,
+}
+
+// shortText is the number of Latin words up to which text without English function
+// words still counts as English, e.g. "Great course"
+const shortText = 3
+
+// Detect guesses the language of text from its scripts. Han text counts as Cantonese
+// if it has a Cantonese particle, Latin text as English if it has an English function
+// word or is short; mixed text goes to the script with more content.
+func Detect(text string) string {
+	var han, kana, hangul int
+	cantonese := false
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Han, r):
+			han++
+			if strings.ContainsRune(cantoneseMarkers, r) {
+				cantonese = true
+			}
+		}
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.Is(unicode.Latin, r) && r != '\''
+	})
+
+	switch {
+	case kana > 0 && kana+han >= len(words):
+		return Japanese
+	case hangul > 0 && hangul >= han && hangul >= len(words):
+		return Korean
+	case han > 0 && han >= len(words) && cantonese:
+		return Cantonese
+	case han > 0 && han >= len(words):
+		return Chinese
+	case len(words) == 0:
+		return Undetermined
+	case len(words) <= shortText:
+		return English
+	}
+	for _, word := range words {
+		if englishWords[word] {
+			return English
+		}
+	}
+	return Undetermined
+}
@@ -0,0 +1,37 @@
+package language
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	cases := map[string]string{
+		"The onboarding was too slow": English,
+		"Great course":                English,
+		"我哋嘅經理好好，但係加班太多":              Cantonese,
+		"佢唔理我哋":                       Cantonese,
+		"我们的经理很好，但是加班太多":              Chinese,
+		"經理很好":                        Chinese,
+		// Characters of Cantonese that standard Chinese words also use are no markers.
+		"我們和經理的關係很好，係數也不錯":                     Chinese,
+		"囉嗦的會議太多了":                             Chinese,
+		"マネージャーはとても親切です":                       Japanese,
+		"팀 분위기가 좋아요":                           Korean,
+		"Muy buena gestión del equipo siempre": Undetermined,
+		"":                                     Undetermined,
+		"1234 !!":                              Undetermined,
+		// Mixed text goes to the script with more content.
+		"OT 太多，成日都要留到好夜，唔開心":                      Cantonese,
+		"The 經理 is great but the shifts are long": English,
+	}
+	for text, want := range cases {
+		assert.Equal(t, want, Detect(text), text)
+	}
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "Cantonese", Name(Cantonese))
+	assert.Equal(t, "fr", Name("fr"))
+}
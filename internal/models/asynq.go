@@ -1,9 +1,37 @@
 package models
 
-import "github.com/hibiken/asynq"
+import (
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+)
 
 type JobSystem struct {
-	Client *asynq.Client
-	Server *asynq.Server
-	Mux    *asynq.ServeMux
+	Client        *asynq.Client
+	Inspector     *asynq.Inspector
+	Server        *asynq.Server
+	Mux           *asynq.ServeMux
+	PeriodicTasks *PeriodicTasks        // read by the periodic task manager
+	Redis         redis.UniversalClient // shared connection for pub/sub
+}
+
+// PeriodicTasks collects the providers of the tasks enqueued on a cron schedule
+type PeriodicTasks struct {
+	providers []asynq.PeriodicTaskConfigProvider
+}
+
+func (p *PeriodicTasks) Register(provider asynq.PeriodicTaskConfigProvider) {
+	p.providers = append(p.providers, provider)
+}
+
+// GetConfigs implements asynq.PeriodicTaskConfigProvider
+func (p *PeriodicTasks) GetConfigs() ([]*asynq.PeriodicTaskConfig, error) {
+	var configs []*asynq.PeriodicTaskConfig
+	for _, provider := range p.providers {
+		c, err := provider.GetConfigs()
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, c...)
+	}
+	return configs, nil
 }
@@ -0,0 +1,51 @@
+package models
+
+/* Main models */
+
+// TokenBudget caps the LLM tokens used per UTC day and calendar month; 0 means unlimited
+type TokenBudget struct {
+	Daily   int `bson:"daily" json:"daily" binding:"min=0"`
+	Monthly int `bson:"monthly" json:"monthly" binding:"min=0"`
+}
+
+type BudgetScope string
+
+const (
+	BudgetScopeGlobal BudgetScope = "GLOBAL"
+	BudgetScopeSurvey BudgetScope = "SURVEY"
+)
+
+type BudgetPeriod string
+
+const (
+	BudgetPeriodDaily   BudgetPeriod = "DAILY"
+	BudgetPeriodMonthly BudgetPeriod = "MONTHLY"
+)
+
+// BudgetUsage is the state of one configured budget in the current period
+type BudgetUsage struct {
+	Scope     BudgetScope  `json:"scope"`
+	Period    BudgetPeriod `json:"period"`
+	Limit     int          `json:"limit"`
+	Used      int          `json:"used"`
+	Remaining int          `json:"remaining"`
+}
+
+/* Request models */
+type GetBudgetRequest struct {
+	SurveyID *string `form:"surveyId"`
+}
+
+type GetBudgetResponse struct {
+	Data  []BudgetUsage `json:"data"`
+	Error string        `json:"error,omitempty"`
+}
+
+type SetSurveyTokenBudgetRequest struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+type SetSurveyTokenBudgetResponse struct {
+	Data  *Survey `json:"data"`
+	Error string  `json:"error,omitempty"`
+}
@@ -8,17 +8,63 @@ import (
 
 /* Main models */
 type ChatCompletionRequestLog struct {
-	ID        bson.ObjectID           `bson:"_id" json:"id"`
-	Request   ChatCompletionRequest   `bson:"request" json:"request"`
-	Response  *ChatCompletionResponse `bson:"response,omitempty" json:"response,omitempty"`
+	ID       bson.ObjectID           `bson:"_id" json:"id"`
+	Request  ChatCompletionRequest   `bson:"request" json:"request"`
+	Response *ChatCompletionResponse `bson:"response,omitempty" json:"response,omitempty"`
+	// Input holds the texts of an embeddings request, whose Request only names the model
+	Input     []string                `bson:"input,omitempty" json:"input,omitempty"`
 	Reference *string                 `bson:"reference" json:"reference"`
-	CreatedAt time.Time               `bson:"created_at" json:"created_at"`
+	InsightID *bson.ObjectID          `bson:"insight_id,omitempty" json:"insight_id,omitempty"`
+	SurveyID  *bson.ObjectID          `bson:"survey_id,omitempty" json:"survey_id,omitempty"`
+	Model     string                  `bson:"model" json:"model"`
+	Status    ChatCompletionLogStatus `bson:"status" json:"status"`
+	Usage     *ChatCompletionUsage    `bson:"usage,omitempty" json:"usage,omitempty"`
+	LatencyMs int64                   `bson:"latency_ms,omitempty" json:"latency_ms,omitempty"` // duration of all attempts
+	Cost      float64                 `bson:"cost,omitempty" json:"cost,omitempty"`             // USD, from the configured price table
+	Cached    bool                    `bson:"cached,omitempty" json:"cached,omitempty"`         // served from the response cache
+	// Error, HTTPStatus and RawBody describe why a FAILED request failed
+	Error      string                  `bson:"error,omitempty" json:"error,omitempty"`
+	HTTPStatus int                     `bson:"http_status,omitempty" json:"http_status,omitempty"`
+	RawBody    string                  `bson:"raw_body,omitempty" json:"raw_body,omitempty"` // truncated provider response
+	Attempts   []ChatCompletionAttempt `bson:"attempts,omitempty" json:"attempts,omitempty"`
+	CreatedAt  time.Time               `bson:"created_at" json:"created_at"`
+}
+
+type ChatCompletionLogStatus string
+
+const (
+	ChatCompletionPending   ChatCompletionLogStatus = "PENDING" // request in flight, or the process died
+	ChatCompletionSucceeded ChatCompletionLogStatus = "SUCCEEDED"
+	ChatCompletionFailed    ChatCompletionLogStatus = "FAILED"
+)
+
+// ChatCompletionAttempt is one call to the provider; requests are retried on transient errors
+type ChatCompletionAttempt struct {
+	Number     int    `bson:"number" json:"number"`
+	HTTPStatus int    `bson:"http_status,omitempty" json:"http_status,omitempty"` // 0 when no response was received
+	Error      string `bson:"error,omitempty" json:"error,omitempty"`
+	DurationMs int64  `bson:"duration_ms" json:"duration_ms"`
+}
+
+// ChatCompletionOptions attributes a request to what it was made for
+type ChatCompletionOptions struct {
+	Reference string
+	InsightID *bson.ObjectID
+	SurveyID  *bson.ObjectID
+	// BypassCache sends the request even if an identical one is cached
+	BypassCache bool
+}
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
 }
 
 // LLM request/response structures type Message struct { Role string `json:"role"` Content string `json:"content"` }
 type ChatCompletionMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string `json:"role" binding:"required,oneof=system user assistant"`
+	Content string `json:"content" binding:"required"`
 }
 
 type ChatCompletionRequest struct {
@@ -33,6 +79,69 @@ type ChatCompletionChoice struct {
 	Message ChatCompletionMessage `json:"message"`
 }
 
+type ChatCompletionUsage struct {
+	PromptTokens     int `bson:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int `bson:"completion_tokens" json:"completion_tokens"`
+	TotalTokens      int `bson:"total_tokens" json:"total_tokens"`
+}
+
 type ChatCompletionResponse struct {
 	Choices []ChatCompletionChoice `json:"choices"`
+	Usage   *ChatCompletionUsage   `json:"usage,omitempty"`
+}
+
+// ChatCompletionReplay is the result of re-sending a logged request
+type ChatCompletionReplay struct {
+	SourceID bson.ObjectID         `json:"source_id"`
+	Request  ChatCompletionRequest `json:"request"`
+	Content  string                `json:"content"`
+}
+
+// ChatCompletionLogFilter selects chat completion logs; zero fields match everything
+type ChatCompletionLogFilter struct {
+	Reference string // substring of the reference, e.g. an insight ID or "batch:3"
+	InsightID *bson.ObjectID
+	Model     string
+	From      *time.Time
+	To        *time.Time
+	Status    ChatCompletionLogStatus
+}
+
+/* Request models */
+type GetChatCompletionLogsRequest struct {
+	Offset    int64      `form:"offset,default=0"`
+	Limit     int64      `form:"limit,default=10"`
+	Reference string     `form:"reference"`
+	InsightID *string    `form:"insightId"`
+	Model     string     `form:"model"`
+	From      *time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	To        *time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+	Status    string     `form:"status" binding:"omitempty,oneof=success error"`
+}
+
+type GetChatCompletionLogsResponse struct {
+	Data  []*ChatCompletionRequestLog `json:"data"`
+	Total int64                       `json:"total"`
+	Error string                      `json:"error,omitempty"`
+}
+
+type GetChatCompletionLogRequest struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+type GetChatCompletionLogResponse struct {
+	Data  *ChatCompletionRequestLog `json:"data"`
+	Error string                    `json:"error,omitempty"`
+}
+
+// ReplayChatCompletionRequest overrides parts of a logged request before re-sending it
+type ReplayChatCompletionRequest struct {
+	Model        *string                 `json:"model"`
+	SystemPrompt *string                 `json:"system_prompt"`                     // replaces the system message
+	Messages     []ChatCompletionMessage `json:"messages" binding:"omitempty,dive"` // replaces all messages
+}
+
+type ReplayChatCompletionResponse struct {
+	Data  *ChatCompletionReplay `json:"data"`
+	Error string                `json:"error,omitempty"`
 }
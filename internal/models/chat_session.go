@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+/* Main models */
+
+// ChatSession is a conversation about the responses of a survey. With an insight, the
+// questions are answered from its batches and summaries; otherwise from the submissions.
+type ChatSession struct {
+	ID        bson.ObjectID        `bson:"_id" json:"id"`
+	SurveyID  bson.ObjectID        `bson:"survey_id" json:"survey_id"`
+	InsightID *bson.ObjectID       `bson:"insight_id,omitempty" json:"insight_id,omitempty"`
+	Messages  []ChatSessionMessage `bson:"messages" json:"messages"`
+	CreatedAt time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+type ChatSessionMessage struct {
+	Role    ChatSessionRole `bson:"role" json:"role"`
+	Content string          `bson:"content" json:"content"`
+	// Citations are the answers an assistant message cites as [n]; InvalidCitations are
+	// cited numbers that match no answer given to the model.
+	Citations        []Citation `bson:"citations,omitempty" json:"citations,omitempty"`
+	InvalidCitations []int      `bson:"invalid_citations,omitempty" json:"invalid_citations,omitempty"`
+	CreatedAt        time.Time  `bson:"created_at" json:"created_at"`
+}
+
+type ChatSessionRole string
+
+const (
+	ChatSessionUser      ChatSessionRole = "user"
+	ChatSessionAssistant ChatSessionRole = "assistant"
+)
+
+/* Request models */
+type CreateChatSessionRequest struct {
+	SurveyID  bson.ObjectID  `json:"survey_id" binding:"required"`
+	InsightID *bson.ObjectID `json:"insight_id"`
+}
+
+type CreateChatSessionResponse struct {
+	Data  *ChatSession `json:"data"`
+	Error string       `json:"error,omitempty"`
+}
+
+type GetChatSessionsRequest struct {
+	Offset   int64   `form:"offset,default=0"`
+	Limit    int64   `form:"limit,default=10"`
+	SurveyID *string `form:"surveyId"`
+}
+
+type GetChatSessionsResponse struct {
+	Data  []*ChatSession `json:"data"`
+	Error string         `json:"error,omitempty"`
+}
+
+type GetChatSessionRequest struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+type GetChatSessionResponse struct {
+	Data  *ChatSession `json:"data"`
+	Error string       `json:"error,omitempty"`
+}
+
+type AskChatSessionRequest struct {
+	Content string `json:"content" binding:"required,max=2000"`
+}
+
+// AskChatSessionResponse returns the assistant's reply; the question and reply are
+// both appended to the session.
+type AskChatSessionResponse struct {
+	Data  *ChatSessionMessage `json:"data"`
+	Error string              `json:"error,omitempty"`
+}
+
+type DeleteChatSessionResponse struct {
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"osp/internal/stats"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+/* Main models */
+type CrossTabulation struct {
+	SurveyID          bson.ObjectID         `json:"survey_id"`
+	RowQuestion       Question              `json:"row_question"`
+	ColumnQuestion    Question              `json:"column_question"`
+	RowLabels         []string              `json:"row_labels"`
+	ColumnLabels      []string              `json:"column_labels"`
+	Counts            [][]int               `json:"counts"`
+	RowTotals         []int                 `json:"row_totals"`
+	ColumnTotals      []int                 `json:"column_totals"`
+	Total             int                   `json:"total"`
+	RowPercentages    [][]float64           `json:"row_percentages"`
+	ColumnPercentages [][]float64           `json:"column_percentages"`
+	ChiSquare         stats.ChiSquareResult `json:"chi_square"`
+	Narrative         *InsightBatch         `json:"narrative,omitempty"`
+}
+
+/* Request models */
+type CrossTabulationRequest struct {
+	RowQuestionID    string       `form:"rowQuestionId" binding:"required"`
+	ColumnQuestionID string       `form:"columnQuestionId" binding:"required"`
+	Narrate          bool         `form:"narrate"`
+	ContextType      *ContextType `form:"contextType" binding:"omitempty,oneof=COURSE_FEEDBACK PRODUCT_SATISFACTION EMPLOYEE_ENGAGEMENT EVENT_FEEDBACK"`
+}
+
+type CrossTabulationResponse struct {
+	Data  *CrossTabulation `json:"data"`
+	Error string           `json:"error,omitempty"`
+}
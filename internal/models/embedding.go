@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+/* Main models */
+
+// AnswerEmbedding is the vector of a TEXTBOX answer, computed in the background for
+// semantic search. Vectors of different models are not comparable, so each is stored
+// with the model that produced it.
+type AnswerEmbedding struct {
+	ID           bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	SurveyID     bson.ObjectID `bson:"survey_id" json:"survey_id"`
+	SubmissionID bson.ObjectID `bson:"submission_id" json:"submission_id"`
+	QuestionID   bson.ObjectID `bson:"question_id" json:"question_id"`
+	Answer       string        `bson:"answer" json:"answer"`
+	Model        string        `bson:"model" json:"model"`
+	Vector       []float32     `bson:"vector" json:"-"`
+	CreatedAt    time.Time     `bson:"created_at" json:"created_at"`
+}
+
+// SemanticSearch lists the answers of a survey most similar to a query
+type SemanticSearch struct {
+	SurveyID bson.ObjectID          `json:"survey_id"`
+	Query    string                 `json:"query"`
+	Model    string                 `json:"model"`
+	Results  []SemanticSearchResult `json:"results"`
+}
+
+type SemanticSearchResult struct {
+	SubmissionID bson.ObjectID `json:"submission_id"`
+	QuestionID   bson.ObjectID `json:"question_id"`
+	Answer       string        `json:"answer"`
+	Score        float64       `json:"score"` // cosine similarity to the query
+}
+
+// ReindexEmbeddings reports the submissions queued to be embedded again
+type ReindexEmbeddings struct {
+	SurveyID bson.ObjectID `json:"survey_id"`
+	Enqueued int           `json:"enqueued"`
+}
+
+/* Request models */
+type SemanticSearchRequest struct {
+	Query      string  `form:"q" binding:"required,max=500"`
+	QuestionID *string `form:"questionId"`
+	Limit      int     `form:"limit,default=10" binding:"min=1,max=50"`
+}
+
+type SemanticSearchResponse struct {
+	Data  *SemanticSearch `json:"data"`
+	Error string          `json:"error,omitempty"`
+}
+
+type ReindexEmbeddingsResponse struct {
+	Data  *ReindexEmbeddings `json:"data"`
+	Error string             `json:"error,omitempty"`
+}
@@ -8,15 +8,31 @@ import (
 
 /* Main models */
 type Insight struct {
-	ID          bson.ObjectID  `bson:"_id" json:"id"`
-	SurveyID    bson.ObjectID  `bson:"survey_id" json:"survey_id"`
-	ContextType ContextType    `bson:"context_type" json:"context_type"`
-	Status      InsightStatus  `bson:"status" json:"status"`
-	Analysis    string         `bson:"analysis" json:"analysis"`
-	Batches     []InsightBatch `bson:"batches" json:"batches"`
-	CreatedAt   time.Time      `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time      `bson:"updated_at" json:"updated_at"`
-	CompletedAt *time.Time     `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	ID              bson.ObjectID     `bson:"_id" json:"id"`
+	SurveyID        bson.ObjectID     `bson:"survey_id" json:"survey_id"`
+	ContextType     ContextType       `bson:"context_type" json:"context_type"`
+	Status          InsightStatus     `bson:"status" json:"status"`
+	Filter          *SubmissionFilter `bson:"filter,omitempty" json:"filter,omitempty"`
+	TaskID          string            `bson:"task_id,omitempty" json:"task_id,omitempty"`
+	SubmissionCount int               `bson:"submission_count" json:"submission_count"`
+	Analysis        string            `bson:"analysis" json:"analysis"`
+	Summaries       []InsightSummary  `bson:"summaries,omitempty" json:"summaries,omitempty"`
+	ErrorLog        *string           `bson:"error_log,omitempty" json:"error_log,omitempty"`
+	BatchSizing     *BatchSizing      `bson:"batch_sizing,omitempty" json:"batch_sizing,omitempty"`
+	EstimatedTokens int               `bson:"estimated_tokens,omitempty" json:"estimated_tokens,omitempty"` // pre-flight estimate of the LLM tokens used
+	BypassCache     bool              `bson:"bypass_cache,omitempty" json:"bypass_cache,omitempty"`
+	// ExcludeFlaggedAnswers leaves answers flagged as prompt injection out of the LLM requests
+	ExcludeFlaggedAnswers bool `bson:"exclude_flagged_answers,omitempty" json:"exclude_flagged_answers,omitempty"`
+	// AnalysisLanguage is the language textual answers are translated into before they
+	// are summarized, e.g. "en"; empty leaves them untranslated
+	AnalysisLanguage string         `bson:"analysis_language,omitempty" json:"analysis_language,omitempty"`
+	Batches          []InsightBatch `bson:"batches" json:"batches"`
+	// Redactions maps the placeholders in the batches back to the redacted values; it
+	// is never returned by the API.
+	Redactions  []RedactionEntry `bson:"redactions,omitempty" json:"-"`
+	CreatedAt   time.Time        `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time        `bson:"updated_at" json:"updated_at"`
+	CompletedAt *time.Time       `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
 }
 
 type InsightBatch struct {
@@ -24,8 +40,82 @@ type InsightBatch struct {
 	Question         Question        `bson:"question" json:"question"`
 	AggregatedAnswer *map[string]int `bson:"aggregated_answer,omitempty" json:"aggregated_answer,omitempty"`
 	TextualAnswers   *[]string       `bson:"textual_answers,omitempty" json:"textual_answers,omitempty"`
-	Summary          *string         `bson:"summary,omitempty" json:"summary,omitempty"`
-	ErrorLog         *string         `bson:"error_log,omitempty" json:"error_log,omitempty"`
+	// SubmissionIDs holds the submission of each textual answer, at the same index
+	SubmissionIDs []bson.ObjectID `bson:"submission_ids,omitempty" json:"submission_ids,omitempty"`
+	// OriginalAnswers holds the untranslated text of each textual answer, at the same
+	// index; it is empty for answers that were not translated
+	OriginalAnswers []string `bson:"original_answers,omitempty" json:"original_answers,omitempty"`
+	// PendingTranslations holds the indexes of the textual answers the insight job still
+	// translates into the analysis language before summarizing the batch
+	PendingTranslations []int `bson:"pending_translations,omitempty" json:"pending_translations,omitempty"`
+	// Languages counts the textual answers of the batch by detected language, e.g. "yue"
+	Languages      map[string]int  `bson:"languages,omitempty" json:"languages,omitempty"`
+	TokenCount     int             `bson:"token_count,omitempty" json:"token_count,omitempty"` // estimated tokens of the textual answers
+	Redactions     map[string]int  `bson:"redactions,omitempty" json:"redactions,omitempty"`   // redacted values by kind, e.g. EMAIL
+	FlaggedAnswers []FlaggedAnswer `bson:"flagged_answers,omitempty" json:"flagged_answers,omitempty"`
+	// Sentiment counts the scored answers of the batch; unscored answers are left out
+	Sentiment *SentimentDistribution `bson:"sentiment,omitempty" json:"sentiment,omitempty"`
+	// TextAnalytics holds keywords and topics of the textual answers, given to the LLM as hints
+	TextAnalytics *TextAnalytics `bson:"text_analytics,omitempty" json:"text_analytics,omitempty"`
+	Summary       *string        `bson:"summary,omitempty" json:"summary,omitempty"`
+	// Citations are the answers the summary cites as [n]; InvalidCitations are cited
+	// numbers that match no answer of the batch.
+	Citations        []Citation `bson:"citations,omitempty" json:"citations,omitempty"`
+	InvalidCitations []int      `bson:"invalid_citations,omitempty" json:"invalid_citations,omitempty"`
+	ErrorLog         *string    `bson:"error_log,omitempty" json:"error_log,omitempty"`
+	Attempts         int        `bson:"attempts" json:"attempts"`
+}
+
+// InsightSummary is an intermediate summary of the hierarchical reduce. Batches roll up
+// into question summaries, questions into section summaries and the top level into the
+// analysis; ParentID links a summary to the one it rolls up into.
+type InsightSummary struct {
+	ID           string              `bson:"id" json:"id"`
+	Level        InsightSummaryLevel `bson:"level" json:"level"`
+	ParentID     string              `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Title        string              `bson:"title" json:"title"`
+	QuestionID   *bson.ObjectID      `bson:"question_id,omitempty" json:"question_id,omitempty"`
+	BatchNumbers []int               `bson:"batch_numbers,omitempty" json:"batch_numbers,omitempty"`
+	Summary      string              `bson:"summary" json:"summary"`
+}
+
+// Citation is an answer quoted as evidence by a batch summary
+type Citation struct {
+	Number       int           `bson:"number" json:"number"` // as cited in the summary, from 1
+	SubmissionID bson.ObjectID `bson:"submission_id" json:"submission_id"`
+	Quote        string        `bson:"quote" json:"quote"`
+	Original     string        `bson:"original,omitempty" json:"original,omitempty"` // the quote before translation
+	Link         string        `bson:"link" json:"link"`                             // admin API path of the submission
+}
+
+// FlaggedAnswer is a textual answer that looks like an attempt to steer the LLM
+type FlaggedAnswer struct {
+	Answer   string   `bson:"answer" json:"answer"`
+	Rules    []string `bson:"rules" json:"rules"`       // heuristics that matched, e.g. ignore_instructions
+	Excluded bool     `bson:"excluded" json:"excluded"` // left out of the batch's textual answers
+}
+
+// RedactionEntry is a placeholder that replaced personal data in the textual answers
+type RedactionEntry struct {
+	Placeholder string `bson:"placeholder" json:"placeholder"`
+	Kind        string `bson:"kind" json:"kind"`
+	Value       string `bson:"value" json:"value"`
+}
+
+type InsightSummaryLevel string
+
+const (
+	InsightSummaryQuestion InsightSummaryLevel = "QUESTION"
+	InsightSummarySection  InsightSummaryLevel = "SECTION"
+)
+
+// BatchSizing records how textual answers were split into batches, so the split can be reproduced
+type BatchSizing struct {
+	Model          string `bson:"model" json:"model"`
+	Tokenizer      string `bson:"tokenizer" json:"tokenizer"`
+	TokenBudget    int    `bson:"token_budget" json:"token_budget"`       // input tokens per batch request
+	PromptOverhead int    `bson:"prompt_overhead" json:"prompt_overhead"` // tokens of the prompt around the answers
+	AnswerBudget   int    `bson:"answer_budget" json:"answer_budget"`     // tokens left for the answers of a batch
 }
 
 type ContextType string
@@ -43,13 +133,28 @@ const (
 	InsightPending    InsightStatus = "PENDING"
 	InsightProcessing InsightStatus = "PROCESSING"
 	InsightCompleted  InsightStatus = "COMPLETED"
+	InsightPartial    InsightStatus = "PARTIAL" // completed, but some batches failed
 	InsightFailed     InsightStatus = "FAILED"
+	InsightCancelled  InsightStatus = "CANCELLED"
 )
 
 /* Request models */
 type CreateInsightRequest struct {
-	SurveyID    bson.ObjectID `json:"survey_id" binding:"required"`
-	ContextType ContextType   `json:"context_type" binding:"required,oneof=COURSE_FEEDBACK PRODUCT_SATISFACTION EMPLOYEE_ENGAGEMENT EVENT_FEEDBACK"`
+	SurveyID    bson.ObjectID     `json:"survey_id" binding:"required"`
+	ContextType ContextType       `json:"context_type" binding:"required,oneof=COURSE_FEEDBACK PRODUCT_SATISFACTION EMPLOYEE_ENGAGEMENT EVENT_FEEDBACK"`
+	Filter      *SubmissionFilter `json:"filter"`
+	// ConfirmOverBudget starts the insight even if its estimate exceeds the remaining
+	// token budget; LLM requests still stop once the budget is exhausted.
+	ConfirmOverBudget bool `json:"confirm_over_budget"`
+	// BypassCache sends every LLM request of the insight even if an identical one is cached
+	BypassCache bool `json:"bypass_cache"`
+	// ExcludeFlaggedAnswers leaves answers flagged as prompt injection out of the insight
+	ExcludeFlaggedAnswers bool `json:"exclude_flagged_answers"`
+	// AnalysisLanguage overrides the configured language answers are translated into
+	AnalysisLanguage string `json:"analysis_language" binding:"omitempty,oneof=en yue zh ja ko"`
+	// ID is the ID of the new insight, set by insight schedules so a retried run can
+	// find the insight it created; zero assigns a new one
+	ID bson.ObjectID `json:"-"`
 }
 
 type CreateInsightResponse struct {
@@ -70,9 +175,20 @@ type GetInsightsResponse struct {
 
 type GetInsightRequest struct {
 	ID string `uri:"id" binding:"required"`
+	// Unredacted puts the personal data redacted from the answers back in place of
+	// its placeholders
+	Unredacted bool `form:"unredacted"`
 }
 
 type GetInsightResponse struct {
 	Data  *Insight `json:"data"`
 	Error string   `json:"error,omitempty"`
 }
+
+type DeleteInsightRequest struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+type DeleteInsightResponse struct {
+	Error string `json:"error,omitempty"`
+}
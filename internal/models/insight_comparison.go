@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"osp/internal/stats"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+/* Main models */
+type InsightComparison struct {
+	ID              bson.ObjectID        `bson:"_id" json:"id"`
+	BaseInsightID   bson.ObjectID        `bson:"base_insight_id" json:"base_insight_id"`
+	TargetInsightID bson.ObjectID        `bson:"target_insight_id" json:"target_insight_id"`
+	BaseSurveyID    bson.ObjectID        `bson:"base_survey_id" json:"base_survey_id"`
+	TargetSurveyID  bson.ObjectID        `bson:"target_survey_id" json:"target_survey_id"`
+	ContextType     ContextType          `bson:"context_type" json:"context_type"`
+	Questions       []QuestionComparison `bson:"questions" json:"questions"`
+	Report          string               `bson:"report" json:"report"`
+	ErrorLog        *string              `bson:"error_log,omitempty" json:"error_log,omitempty"`
+	CreatedAt       time.Time            `bson:"created_at" json:"created_at"`
+}
+
+// QuestionComparison holds the differences for one question between the base and target insights.
+// A nil question ID means the question only exists on the other side.
+type QuestionComparison struct {
+	Text             string         `bson:"text" json:"text"`
+	Type             QuestionType   `bson:"type" json:"type"`
+	BaseQuestionID   *bson.ObjectID `bson:"base_question_id,omitempty" json:"base_question_id,omitempty"`
+	TargetQuestionID *bson.ObjectID `bson:"target_question_id,omitempty" json:"target_question_id,omitempty"`
+	BaseResponses    int            `bson:"base_responses" json:"base_responses"`
+	TargetResponses  int            `bson:"target_responses" json:"target_responses"`
+	Options          []OptionDelta  `bson:"options,omitempty" json:"options,omitempty"`
+	BaseStatistics   *stats.Summary `bson:"base_statistics,omitempty" json:"base_statistics,omitempty"`
+	TargetStatistics *stats.Summary `bson:"target_statistics,omitempty" json:"target_statistics,omitempty"`
+	MeanDelta        *float64       `bson:"mean_delta,omitempty" json:"mean_delta,omitempty"`
+	BaseSummary      *string        `bson:"base_summary,omitempty" json:"base_summary,omitempty"`
+	TargetSummary    *string        `bson:"target_summary,omitempty" json:"target_summary,omitempty"`
+}
+
+// OptionDelta compares how often an answer was chosen; percentages are 0-100.
+type OptionDelta struct {
+	Option           string  `bson:"option" json:"option"`
+	BaseCount        int     `bson:"base_count" json:"base_count"`
+	TargetCount      int     `bson:"target_count" json:"target_count"`
+	BasePercentage   float64 `bson:"base_percentage" json:"base_percentage"`
+	TargetPercentage float64 `bson:"target_percentage" json:"target_percentage"`
+	PercentageDelta  float64 `bson:"percentage_delta" json:"percentage_delta"`
+}
+
+/* Request models */
+type CreateInsightComparisonRequest struct {
+	BaseInsightID   bson.ObjectID `json:"base_insight_id" binding:"required"`
+	TargetInsightID bson.ObjectID `json:"target_insight_id" binding:"required"`
+}
+
+type CreateInsightComparisonResponse struct {
+	Data  *InsightComparison `json:"data"`
+	Error string             `json:"error,omitempty"`
+}
+
+type GetInsightComparisonsRequest struct {
+	Offset int64 `form:"offset,default=0"`
+	Limit  int64 `form:"limit,default=10"`
+}
+
+type GetInsightComparisonsResponse struct {
+	Data  []*InsightComparison `json:"data"`
+	Error string               `json:"error,omitempty"`
+}
+
+type GetInsightComparisonRequest struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+type GetInsightComparisonResponse struct {
+	Data  *InsightComparison `json:"data"`
+	Error string             `json:"error,omitempty"`
+}
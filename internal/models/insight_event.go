@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// InsightEvent is a progress notification of an insight, published while it is processed
+type InsightEvent struct {
+	Type             InsightEventType `json:"type"`
+	InsightID        bson.ObjectID    `json:"insight_id"`
+	Status           InsightStatus    `json:"status,omitempty"`
+	BatchNumber      int              `json:"batch_number,omitempty"`
+	Summary          *string          `json:"summary,omitempty"`
+	ErrorLog         *string          `json:"error_log,omitempty"`
+	ProcessedBatches int              `json:"processed_batches,omitempty"`
+	TotalBatches     int              `json:"total_batches,omitempty"`
+	Timestamp        time.Time        `json:"timestamp"`
+}
+
+type InsightEventType string
+
+const (
+	InsightEventStatus InsightEventType = "status" // the insight changed status
+	InsightEventBatch  InsightEventType = "batch"  // a batch was summarized or failed
+)
+
+/* Request models */
+
+type WatchInsightRequest struct {
+	ID string `uri:"id" binding:"required"`
+}
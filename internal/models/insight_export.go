@@ -0,0 +1,20 @@
+package models
+
+/* Main models */
+
+// InsightExport is an insight rendered as a downloadable report
+type InsightExport struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+/* Request models */
+type ExportInsightRequest struct {
+	Format string `form:"format,default=md" binding:"oneof=md html pdf"`
+}
+
+// ExportInsightResponse is only returned on errors; reports are sent as files
+type ExportInsightResponse struct {
+	Error string `json:"error,omitempty"`
+}
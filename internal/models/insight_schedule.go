@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+/* Main models */
+
+// InsightSchedule creates an insight of a survey on a cron schedule
+type InsightSchedule struct {
+	ID          bson.ObjectID `bson:"_id" json:"id"`
+	SurveyID    bson.ObjectID `bson:"survey_id" json:"survey_id"`
+	ContextType ContextType   `bson:"context_type" json:"context_type"`
+	// Cron is a standard five-field expression or a descriptor such as @weekly; a
+	// CRON_TZ=Area/City prefix sets its time zone, which defaults to UTC
+	Cron   string            `bson:"cron" json:"cron"`
+	Filter *SubmissionFilter `bson:"filter,omitempty" json:"filter,omitempty"`
+	// OnlyNewSubmissions limits every run to the submissions since the previous run
+	OnlyNewSubmissions    bool   `bson:"only_new_submissions" json:"only_new_submissions"`
+	ExcludeFlaggedAnswers bool   `bson:"exclude_flagged_answers,omitempty" json:"exclude_flagged_answers,omitempty"`
+	AnalysisLanguage      string `bson:"analysis_language,omitempty" json:"analysis_language,omitempty"`
+	Enabled               bool   `bson:"enabled" json:"enabled"`
+	// LastRunAt is the end of the submission window of the last run that did not fail
+	LastRunAt *time.Time `bson:"last_run_at,omitempty" json:"last_run_at,omitempty"`
+	NextRunAt *time.Time `bson:"-" json:"next_run_at,omitempty"` // computed from Cron when read
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// InsightScheduleRun records one execution of a schedule
+type InsightScheduleRun struct {
+	ID         bson.ObjectID `bson:"_id" json:"id"`
+	ScheduleID bson.ObjectID `bson:"schedule_id" json:"schedule_id"`
+	// TaskID is the asynq task of the run; a retried task resumes the run it recorded
+	TaskID    string                   `bson:"task_id,omitempty" json:"task_id,omitempty"`
+	Status    InsightScheduleRunStatus `bson:"status" json:"status"`
+	InsightID *bson.ObjectID           `bson:"insight_id,omitempty" json:"insight_id,omitempty"` // assigned before the insight is created
+	// From and To bound the submissions of the run; From is unset without a lower bound
+	From      *time.Time `bson:"from,omitempty" json:"from,omitempty"`
+	To        time.Time  `bson:"to" json:"to"`
+	Error     string     `bson:"error,omitempty" json:"error,omitempty"`
+	StartedAt time.Time  `bson:"started_at" json:"started_at"`
+}
+
+type InsightScheduleRunStatus string
+
+const (
+	InsightScheduleRunPending InsightScheduleRunStatus = "PENDING" // creating the insight
+	InsightScheduleRunCreated InsightScheduleRunStatus = "CREATED"
+	InsightScheduleRunSkipped InsightScheduleRunStatus = "SKIPPED" // no submissions in the window
+	InsightScheduleRunFailed  InsightScheduleRunStatus = "FAILED"
+)
+
+/* Request models */
+type CreateInsightScheduleRequest struct {
+	SurveyID bson.ObjectID `json:"survey_id" binding:"required"`
+	InsightScheduleInput
+}
+
+// InsightScheduleInput holds the settings of a schedule that can be changed
+type InsightScheduleInput struct {
+	ContextType           ContextType       `json:"context_type" binding:"required,oneof=COURSE_FEEDBACK PRODUCT_SATISFACTION EMPLOYEE_ENGAGEMENT EVENT_FEEDBACK"`
+	Cron                  string            `json:"cron" binding:"required,max=100"`
+	Filter                *SubmissionFilter `json:"filter"`
+	OnlyNewSubmissions    bool              `json:"only_new_submissions"`
+	ExcludeFlaggedAnswers bool              `json:"exclude_flagged_answers"`
+	AnalysisLanguage      string            `json:"analysis_language" binding:"omitempty,oneof=en yue zh ja ko"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+type CreateInsightScheduleResponse struct {
+	Data  *InsightSchedule `json:"data"`
+	Error string           `json:"error,omitempty"`
+}
+
+type GetInsightSchedulesRequest struct {
+	Offset   int64   `form:"offset,default=0"`
+	Limit    int64   `form:"limit,default=10"`
+	SurveyID *string `form:"surveyId"`
+}
+
+type GetInsightSchedulesResponse struct {
+	Data  []*InsightSchedule `json:"data"`
+	Error string             `json:"error,omitempty"`
+}
+
+type GetInsightScheduleRequest struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+type GetInsightScheduleResponse struct {
+	Data  *InsightSchedule `json:"data"`
+	Error string           `json:"error,omitempty"`
+}
+
+type UpdateInsightScheduleResponse struct {
+	Data  *InsightSchedule `json:"data"`
+	Error string           `json:"error,omitempty"`
+}
+
+type DeleteInsightScheduleResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+type GetInsightScheduleRunsRequest struct {
+	Offset int64 `form:"offset,default=0"`
+	Limit  int64 `form:"limit,default=10"`
+}
+
+type GetInsightScheduleRunsResponse struct {
+	Data  []*InsightScheduleRun `json:"data"`
+	Error string                `json:"error,omitempty"`
+}
@@ -11,6 +11,7 @@ type Submission struct {
 	ID        bson.ObjectID        `bson:"_id" json:"id"`
 	SurveyID  bson.ObjectID        `bson:"survey_id" json:"survey_id" binding:"required"`
 	Responses []SubmissionResponse `bson:"responses" json:"responses" binding:"required"`
+	Metadata  map[string]string    `bson:"metadata,omitempty" json:"metadata,omitempty"`
 	CreatedAt time.Time            `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time            `bson:"updated_at" json:"updated_at"`
 }
@@ -18,12 +19,61 @@ type Submission struct {
 type SubmissionResponse struct {
 	QuestionID bson.ObjectID `bson:"question_id" json:"question_id" binding:"required"`
 	Answer     string        `bson:"answer" json:"answer" binding:"required"`
+	// Sentiment is scored in the background for TEXTBOX answers
+	Sentiment *Sentiment `bson:"sentiment,omitempty" json:"sentiment,omitempty"`
+}
+
+type Sentiment struct {
+	Label    SentimentLabel `bson:"label" json:"label"`
+	Score    float64        `bson:"score" json:"score"`       // from -1 (negative) to 1 (positive)
+	Analyzer string         `bson:"analyzer" json:"analyzer"` // e.g. llm or lexicon-v1
+}
+
+type SentimentLabel string
+
+const (
+	SentimentPositive SentimentLabel = "POSITIVE"
+	SentimentNeutral  SentimentLabel = "NEUTRAL"
+	SentimentNegative SentimentLabel = "NEGATIVE"
+)
+
+// SentimentDistribution counts the sentiment of the scored answers of an insight batch
+type SentimentDistribution struct {
+	Positive     int     `bson:"positive" json:"positive"`
+	Neutral      int     `bson:"neutral" json:"neutral"`
+	Negative     int     `bson:"negative" json:"negative"`
+	AverageScore float64 `bson:"average_score" json:"average_score"`
+}
+
+// SentimentFilter selects submissions with a TEXTBOX answer of the given sentiment,
+// optionally to one question
+type SentimentFilter struct {
+	Label      SentimentLabel
+	QuestionID *bson.ObjectID
+}
+
+// SubmissionFilter selects a subset of a survey's submissions
+type SubmissionFilter struct {
+	From     *time.Time        `bson:"from,omitempty" json:"from,omitempty"`
+	To       *time.Time        `bson:"to,omitempty" json:"to,omitempty"`
+	Answers  []AnswerFilter    `bson:"answers,omitempty" json:"answers,omitempty" binding:"omitempty,dive"`
+	Metadata map[string]string `bson:"metadata,omitempty" json:"metadata,omitempty"`
+}
+
+// AnswerFilter matches submissions whose answer to a question is one of Values,
+// or for LIKERT questions lies within [Min, Max]
+type AnswerFilter struct {
+	QuestionID bson.ObjectID `bson:"question_id" json:"question_id" binding:"required"`
+	Values     []string      `bson:"values,omitempty" json:"values,omitempty"`
+	Min        *int          `bson:"min,omitempty" json:"min,omitempty"`
+	Max        *int          `bson:"max,omitempty" json:"max,omitempty"`
 }
 
 /* Request models */
 type CreateSubmissionRequest struct {
 	SurveyToken string               `json:"survey_token" binding:"required"`
 	Responses   []SubmissionResponse `json:"responses" binding:"required"`
+	Metadata    map[string]string    `json:"metadata" binding:"omitempty,max=20"`
 }
 
 type CreateSubmissionResponse struct {
@@ -32,9 +82,11 @@ type CreateSubmissionResponse struct {
 }
 
 type GetSubmissionsRequest struct {
-	SurveyID *string `form:"surveyId"`
-	Offset   int64   `form:"offset,default=0"`
-	Limit    int64   `form:"limit,default=10"`
+	SurveyID   *string `form:"surveyId"`
+	Offset     int64   `form:"offset,default=0"`
+	Limit      int64   `form:"limit,default=10"`
+	Sentiment  string  `form:"sentiment" binding:"omitempty,oneof=POSITIVE NEUTRAL NEGATIVE"`
+	QuestionID *string `form:"questionId"` // restricts the sentiment filter to one question
 }
 
 type GetSubmissionsResponse struct {
@@ -42,6 +94,15 @@ type GetSubmissionsResponse struct {
 	Error string        `json:"error,omitempty"`
 }
 
+type GetSubmissionRequest struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+type GetSubmissionResponse struct {
+	Data  *Submission `json:"data"`
+	Error string      `json:"error,omitempty"`
+}
+
 type DeleteSubmissionRequest struct {
 	ID string `uri:"id" binding:"required"`
 }
@@ -12,14 +12,25 @@ type Survey struct {
 	Name      string        `bson:"name" json:"name" binding:"required"`
 	Token     string        `bson:"token" json:"token" binding:"required"`
 	Questions []Question    `bson:"questions" json:"questions" binding:"required"`
-	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time     `bson:"updated_at" json:"updated_at"`
+	// TokenBudget caps the LLM tokens used for this survey, on top of the global budget
+	TokenBudget *TokenBudget `bson:"token_budget,omitempty" json:"token_budget,omitempty"`
+	// Redaction controls PII redaction of textbox answers before they are sent to the LLM
+	Redaction *RedactionSettings `bson:"redaction,omitempty" json:"redaction,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type RedactionSettings struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Terms are redacted on top of emails, phone, card and ID numbers, e.g. staff names
+	Terms []string `bson:"terms,omitempty" json:"terms,omitempty" binding:"omitempty,max=200,dive,max=100"`
 }
 
 type Question struct {
 	ID            bson.ObjectID         `bson:"id" json:"id" binding:"required"`
 	Text          string                `bson:"text" json:"text" binding:"required"`
 	Type          QuestionType          `bson:"type" json:"type" binding:"required"`
+	Section       string                `bson:"section,omitempty" json:"section,omitempty"`
 	Specification QuestionSpecification `bson:"specification" json:"specification"`
 }
 
@@ -55,13 +66,16 @@ type LikertSpecification struct {
 type QuestionInput struct {
 	Type          QuestionType          `json:"type" binding:"required,oneof=TEXTBOX MULTIPLE_CHOICE LIKERT"`
 	Text          string                `json:"text" binding:"required"`
+	Section       string                `json:"section" binding:"omitempty,max=100"`
 	Specification QuestionSpecification `json:"specification" binding:"required"`
 }
 
 /* Request models */
 type CreateSurveyRequest struct {
-	Name      string          `json:"name" binding:"required"`
-	Questions []QuestionInput `json:"questions" binding:"required,dive"`
+	Name        string             `json:"name" binding:"required"`
+	Questions   []QuestionInput    `json:"questions" binding:"required,dive"`
+	TokenBudget *TokenBudget       `json:"token_budget"`
+	Redaction   *RedactionSettings `json:"redaction"`
 }
 
 type CreateSurveyResponse struct {
@@ -102,6 +116,15 @@ type DeleteSurveyRequest struct {
 	ID string `uri:"id" binding:"required"`
 }
 
+type SetSurveyRedactionRequest struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+type SetSurveyRedactionResponse struct {
+	Data  *Survey `json:"data"`
+	Error string  `json:"error,omitempty"`
+}
+
 type DeleteSurveyResponse struct {
 	Error string `json:"error,omitempty"`
 }
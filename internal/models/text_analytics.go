@@ -0,0 +1,58 @@
+package models
+
+import "go.mongodb.org/mongo-driver/v2/bson"
+
+/* Main models */
+
+// TextAnalytics is computed offline from textual answers, without an LLM
+type TextAnalytics struct {
+	Keywords []TextKeyword `bson:"keywords" json:"keywords"`
+	Bigrams  []TextNGram   `bson:"bigrams,omitempty" json:"bigrams,omitempty"`
+	Trigrams []TextNGram   `bson:"trigrams,omitempty" json:"trigrams,omitempty"`
+	Topics   []TextTopic   `bson:"topics" json:"topics"`
+}
+
+type TextKeyword struct {
+	Term  string  `bson:"term" json:"term"`
+	Score float64 `bson:"score" json:"score"` // TF-IDF weight summed over the answers
+}
+
+type TextNGram struct {
+	Text  string `bson:"text" json:"text"`
+	Count int    `bson:"count" json:"count"` // answers containing it
+}
+
+// TextTopic is a cluster of similar answers
+type TextTopic struct {
+	Keywords []string `bson:"keywords" json:"keywords"`
+	Size     int      `bson:"size" json:"size"`
+	// Answers are the numbers of the batch answers in the topic, from 1 as in citations
+	Answers []int `bson:"answers,omitempty" json:"answers,omitempty"`
+	// Examples are the answers closest to the centre of the topic
+	Examples []string `bson:"examples,omitempty" json:"examples,omitempty"`
+}
+
+// QuestionTextAnalytics is the text analytics of all answers to a question
+type QuestionTextAnalytics struct {
+	SurveyID    bson.ObjectID `json:"survey_id"`
+	QuestionID  bson.ObjectID `json:"question_id"`
+	AnswerCount int           `json:"answer_count"`
+	TextAnalytics
+}
+
+/* Request models */
+type GetTextAnalyticsRequest struct {
+	ID         string `uri:"id" binding:"required"`
+	QuestionID string `uri:"questionId" binding:"required"`
+}
+
+type TextAnalyticsRequest struct {
+	Keywords int `form:"keywords,default=20" binding:"min=1,max=100"`
+	NGrams   int `form:"ngrams,default=10" binding:"min=0,max=100"`
+	Topics   int `form:"topics,default=0" binding:"min=0,max=20"` // 0 picks a number from the answer count
+}
+
+type GetTextAnalyticsResponse struct {
+	Data  *QuestionTextAnalytics `json:"data"`
+	Error string                 `json:"error,omitempty"`
+}
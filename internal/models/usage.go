@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+/* Main models */
+
+// UsageTotals sums the chat completion requests of a period
+type UsageTotals struct {
+	Requests         int     `bson:"requests" json:"requests"`
+	CachedRequests   int     `bson:"cached_requests" json:"cached_requests"` // cache hits, included in Requests
+	PromptTokens     int     `bson:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int     `bson:"completion_tokens" json:"completion_tokens"`
+	TotalTokens      int     `bson:"total_tokens" json:"total_tokens"`
+	Cost             float64 `bson:"cost" json:"cost"`
+	AvgLatencyMs     float64 `bson:"avg_latency_ms" json:"avg_latency_ms"`
+}
+
+type DailyUsage struct {
+	Date        string `bson:"_id" json:"date"` // YYYY-MM-DD, UTC
+	UsageTotals `bson:",inline"`
+}
+
+type InsightUsage struct {
+	InsightID   bson.ObjectID  `bson:"_id" json:"insight_id"`
+	SurveyID    *bson.ObjectID `bson:"survey_id" json:"survey_id"`
+	UsageTotals `bson:",inline"`
+}
+
+type SurveyUsage struct {
+	SurveyID    bson.ObjectID `bson:"_id" json:"survey_id"`
+	UsageTotals `bson:",inline"`
+}
+
+// CacheStats counts the outcomes of the LLM response cache across all surveys
+type CacheStats struct {
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`   // looked up but sent to the provider
+	Bypassed int64   `json:"bypassed"` // sent without a lookup, e.g. with bypass_cache
+	HitRate  float64 `json:"hit_rate"` // hits out of hits and misses
+}
+
+type UsageReport struct {
+	From   time.Time   `json:"from"`
+	To     time.Time   `json:"to"`
+	Totals UsageTotals `json:"totals"`
+	// Cache is left out for a single survey, when the cache is disabled or unavailable
+	Cache    *CacheStats    `json:"cache,omitempty"`
+	Daily    []DailyUsage   `json:"daily"`
+	Surveys  []SurveyUsage  `json:"surveys"`
+	Insights []InsightUsage `json:"insights"`
+}
+
+// UsageFilter selects the chat completion logs of a report
+type UsageFilter struct {
+	From     time.Time
+	To       time.Time
+	SurveyID *bson.ObjectID
+}
+
+/* Request models */
+type GetUsageRequest struct {
+	From     *time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	To       *time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+	SurveyID *string    `form:"surveyId"`
+}
+
+type GetUsageResponse struct {
+	Data  *UsageReport `json:"data"`
+	Error string       `json:"error,omitempty"`
+}
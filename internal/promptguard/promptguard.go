@@ -0,0 +1,46 @@
+package promptguard
+
+import "regexp"
+
+// Rule is a heuristic for text that tries to steer the model instead of answering the survey
+type Rule struct {
+	Name    string
+	pattern *regexp.Regexp
+}
+
+// Rules are matched case-insensitively. They favour recall: a flagged answer is only
+// reported, or left out of an insight on request, never rejected.
+var Rules = []Rule{
+	{
+		Name: "ignore_instructions",
+		pattern: regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override|bypass)\b.{0,40}\b(?:instructions?|prompts?|rules|directions|guidelines|context)\b` +
+			`|(?:忽略|無視|无视|忘記|忘记).{0,10}(?:指示|指令|提示|規則|规则)`),
+	},
+	{
+		Name:    "role_override",
+		pattern: regexp.MustCompile(`(?i)\b(?:you are now|from now on,? you|act as (?:an?|the)|pretend (?:to be|you are)|new instructions?)\b`),
+	},
+	{
+		Name:    "prompt_reference",
+		pattern: regexp.MustCompile(`(?i)\b(?:system prompt|system message|developer message|jailbreak|prompt injection)\b`),
+	},
+	{
+		Name:    "role_marker",
+		pattern: regexp.MustCompile(`(?im)^\s*(?:system|assistant|developer)\s*:|<\|im_(?:start|end)\|>|\[/?INST\]|</?answers>`),
+	},
+	{
+		Name:    "output_directive",
+		pattern: regexp.MustCompile(`(?i)\b(?:summary|analysis|report|insight)\b.{0,30}\b(?:must|should|has to)\b.{0,10}\b(?:say|state|conclude|include|only)\b`),
+	},
+}
+
+// Detect returns the names of the rules matched by text, or nil if it looks benign
+func Detect(text string) []string {
+	var matched []string
+	for _, rule := range Rules {
+		if rule.pattern.MatchString(text) {
+			matched = append(matched, rule.Name)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,232 @@
+package redaction
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kind is the type of personal data a placeholder stands for
+type Kind string
+
+const (
+	Email Kind = "EMAIL"
+	Card  Kind = "CARD"  // payment card numbers passing the Luhn check
+	ID    Kind = "ID"    // identity numbers such as HKID and SSN
+	Phone Kind = "PHONE" // 8 to 15 digits, optionally with a country code
+	Term  Kind = "TERM"  // words of the custom dictionary, e.g. staff names
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+	cardPattern  = regexp.MustCompile(`\d(?:[ \-]?\d){12,18}`)
+	idPattern    = regexp.MustCompile(`[A-Z]{1,2}\d{6} ?\([0-9A]\)|[A-Z]{1,2}\d{6}[0-9A]|\d{3}-\d{2}-\d{4}`)
+	phonePattern = regexp.MustCompile(`(?:\+\d{1,3}[ .\-]?)?(?:\(\d{1,4}\)[ .\-]?)?\d{2,4}(?:[ .\-]?\d{2,5}){1,4}`)
+	datePattern  = regexp.MustCompile(`^\d{4}[.\-/]\d{1,2}[.\-/]\d{1,2}$|^\d{1,2}[.\-/]\d{1,2}[.\-/]\d{4}$`)
+)
+
+// Redactor replaces personal data in free text with placeholders such as [EMAIL_1]
+type Redactor struct {
+	terms *regexp.Regexp // nil without a custom dictionary
+}
+
+// New creates a redactor that also replaces the given terms, matched case-insensitively
+// as whole words
+func New(terms []string) *Redactor {
+	r := &Redactor{}
+	var alternatives []string
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term != "" {
+			alternatives = append(alternatives, termPattern(term))
+		}
+	}
+	if len(alternatives) > 0 {
+		// Longer terms first, so "Chan Tai Man" wins over "Chan".
+		sort.SliceStable(alternatives, func(i, j int) bool { return len(alternatives[i]) > len(alternatives[j]) })
+		r.terms = regexp.MustCompile(`(?i)` + strings.Join(alternatives, "|"))
+	}
+	return r
+}
+
+// termPattern matches a term as a whole word. Word boundaries only apply next to ASCII
+// word characters, so terms in scripts without spaces (e.g. Chinese names) match anywhere.
+func termPattern(term string) string {
+	pattern := regexp.QuoteMeta(term)
+	if first, _ := utf8.DecodeRuneInString(term); isASCIIWord(first) {
+		pattern = `\b` + pattern
+	}
+	if last, _ := utf8.DecodeLastRuneInString(term); isASCIIWord(last) {
+		pattern += `\b`
+	}
+	return pattern
+}
+
+// Redact replaces the personal data in text, recording the placeholders in m, and
+// returns the redacted text with the number of replacements by kind
+func (r *Redactor) Redact(text string, m *Mapping) (string, map[Kind]int) {
+	counts := make(map[Kind]int)
+	text = m.replace(text, emailPattern, Email, counts, nil)
+	text = m.replace(text, cardPattern, Card, counts, isCardNumber)
+	text = m.replace(text, idPattern, ID, counts, nil)
+	text = m.replace(text, phonePattern, Phone, counts, isPhoneNumber)
+	if r.terms != nil {
+		text = m.replace(text, r.terms, Term, counts, nil)
+	}
+	return text, counts
+}
+
+// Entry maps a placeholder back to the value it replaced
+type Entry struct {
+	Placeholder string
+	Kind        Kind
+	Value       string
+}
+
+// Mapping assigns placeholders across the texts of one document, so the same value
+// always gets the same placeholder
+type Mapping struct {
+	placeholders map[string]string // kind and normalized value to placeholder
+	entries      []Entry
+	next         map[Kind]int
+}
+
+func NewMapping() *Mapping {
+	return &Mapping{
+		placeholders: make(map[string]string),
+		next:         make(map[Kind]int),
+	}
+}
+
+// MappingOf rebuilds a mapping from its stored entries, e.g. to restore the texts
+// they were assigned in
+func MappingOf(entries []Entry) *Mapping {
+	m := NewMapping()
+	for _, entry := range entries {
+		m.placeholders[string(entry.Kind)+"\x00"+normalize(entry.Kind, entry.Value)] = entry.Placeholder
+		m.entries = append(m.entries, entry)
+		m.next[entry.Kind]++
+	}
+	return m
+}
+
+// Entries returns the placeholders in the order they were assigned
+func (m *Mapping) Entries() []Entry {
+	return m.entries
+}
+
+// Restore puts the original values back in place of the placeholders
+func (m *Mapping) Restore(text string) string {
+	if len(m.entries) == 0 {
+		return text
+	}
+	pairs := make([]string, 0, 2*len(m.entries))
+	for _, entry := range m.entries {
+		pairs = append(pairs, entry.Placeholder, entry.Value)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+func (m *Mapping) placeholder(kind Kind, value string) string {
+	key := string(kind) + "\x00" + normalize(kind, value)
+	if placeholder, ok := m.placeholders[key]; ok {
+		return placeholder
+	}
+	m.next[kind]++
+	placeholder := fmt.Sprintf("[%s_%d]", kind, m.next[kind])
+	m.placeholders[key] = placeholder
+	m.entries = append(m.entries, Entry{Placeholder: placeholder, Kind: kind, Value: value})
+	return placeholder
+}
+
+// replace substitutes the matches of re that are not part of a longer word or number
+// and pass valid, if given
+func (m *Mapping) replace(text string, re *regexp.Regexp, kind Kind, counts map[Kind]int, valid func(string) bool) string {
+	matches := re.FindAllStringIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		value := text[start:end]
+		if !standalone(text, start, end) || (valid != nil && !valid(value)) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(m.placeholder(kind, value))
+		counts[kind]++
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// standalone reports whether text[start:end] is not glued to letters or digits
+func standalone(text string, start, end int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isASCIIWord(before) {
+		// Terms and emails start with a word character themselves, so only reject
+		// a digit or letter directly in front of a number.
+		if first, _ := utf8.DecodeRuneInString(text[start:]); unicode.IsDigit(first) || first == '+' || first == '(' {
+			return false
+		}
+	}
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isASCIIWord(after) {
+		if last, _ := utf8.DecodeLastRuneInString(text[:end]); unicode.IsDigit(last) || last == ')' {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCIIWord(r rune) bool {
+	return r < utf8.RuneSelf && (r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+func normalize(kind Kind, value string) string {
+	switch kind {
+	case Card, Phone:
+		return digits(value)
+	default:
+		return strings.ToLower(value)
+	}
+}
+
+// isCardNumber validates the Luhn checksum of a 13 to 19 digit number
+func isCardNumber(value string) bool {
+	d := digits(value)
+	if len(d) < 13 || len(d) > 19 {
+		return false
+	}
+	sum := 0
+	for i := len(d) - 1; i >= 0; i-- {
+		n := int(d[i] - '0')
+		if (len(d)-1-i)%2 == 1 {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+	}
+	return sum%10 == 0
+}
+
+// isPhoneNumber accepts 8 to 15 digits that do not read as a date
+func isPhoneNumber(value string) bool {
+	n := len(digits(value))
+	return n >= 8 && n <= 15 && !datePattern.MatchString(value)
+}
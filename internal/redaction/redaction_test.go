@@ -0,0 +1,77 @@
+package redaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	r := New(nil)
+
+	t.Run("Email", func(t *testing.T) {
+		text, counts := r.Redact("Contact me at peter.chan@example.com.hk please", NewMapping())
+		assert.Equal(t, "Contact me at [EMAIL_1] please", text)
+		assert.Equal(t, map[Kind]int{Email: 1}, counts)
+	})
+
+	t.Run("Phone", func(t *testing.T) {
+		text, counts := r.Redact("Call 9123 4567 or +852 2345-6789", NewMapping())
+		assert.Equal(t, "Call [PHONE_1] or [PHONE_2]", text)
+		assert.Equal(t, map[Kind]int{Phone: 2}, counts)
+	})
+
+	t.Run("Card", func(t *testing.T) {
+		text, counts := r.Redact("Charged to 4111 1111 1111 1111 twice", NewMapping())
+		assert.Equal(t, "Charged to [CARD_1] twice", text)
+		assert.Equal(t, map[Kind]int{Card: 1}, counts)
+	})
+
+	t.Run("ID", func(t *testing.T) {
+		text, counts := r.Redact("My HKID is A123456(7), SSN 078-05-1120", NewMapping())
+		assert.Equal(t, "My HKID is [ID_1], SSN [ID_2]", text)
+		assert.Equal(t, map[Kind]int{ID: 2}, counts)
+	})
+
+	t.Run("KeepsOrdinaryNumbers", func(t *testing.T) {
+		text, counts := r.Redact("Rated 5/5, class of 2024, held on 2024-03-15, room 1234", NewMapping())
+		assert.Equal(t, "Rated 5/5, class of 2024, held on 2024-03-15, room 1234", text)
+		assert.Empty(t, counts)
+	})
+
+	t.Run("InvalidCardIsNotACard", func(t *testing.T) {
+		text, _ := r.Redact("Order 4111 1111 1111 1112", NewMapping())
+		assert.NotContains(t, text, "[CARD_")
+	})
+}
+
+func TestRedactor_Terms(t *testing.T) {
+	r := New([]string{"Chan", "Chan Tai Man", "陳大文", " "})
+
+	text, counts := r.Redact("Mr chan tai man (陳大文) and Ms Chan, not Chanel", NewMapping())
+	assert.Equal(t, "Mr [TERM_1] ([TERM_2]) and Ms [TERM_3], not Chanel", text)
+	assert.Equal(t, map[Kind]int{Term: 3}, counts)
+}
+
+func TestMapping(t *testing.T) {
+	r := New(nil)
+	m := NewMapping()
+
+	first, _ := r.Redact("Email a@b.io or call 9123 4567", m)
+	second, _ := r.Redact("A@B.io again, 91234567", m)
+	assert.Equal(t, "Email [EMAIL_1] or call [PHONE_1]", first)
+	assert.Equal(t, "[EMAIL_1] again, [PHONE_1]", second)
+
+	assert.Equal(t, []Entry{
+		{Placeholder: "[EMAIL_1]", Kind: Email, Value: "a@b.io"},
+		{Placeholder: "[PHONE_1]", Kind: Phone, Value: "9123 4567"},
+	}, m.Entries())
+	assert.Equal(t, "Email a@b.io or call 9123 4567", m.Restore(first))
+
+	// A mapping rebuilt from its entries restores the same texts and keeps assigning
+	// the same placeholders.
+	stored := MappingOf(m.Entries())
+	assert.Equal(t, "Email a@b.io or call 9123 4567", stored.Restore(first))
+	third, _ := r.Redact("a@b.io or c@d.io", stored)
+	assert.Equal(t, "[EMAIL_1] or [EMAIL_2]", third)
+}
@@ -0,0 +1,91 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Chart is a horizontal bar chart of an answer distribution
+type Chart struct {
+	Bars []Bar
+}
+
+// Bar is one answer of a distribution
+type Bar struct {
+	Label string
+	Value int
+}
+
+// Chart geometry in points, shared by the SVG and the PDF renderer
+const (
+	chartWidth       = 480.0
+	chartLabelWidth  = 150.0
+	chartValueWidth  = 70.0
+	chartBarHeight   = 16.0
+	chartBarGap      = 6.0
+	chartFontSize    = 10.0
+	chartLabelLength = 28 // runes of a label before it is truncated
+)
+
+func (c *Chart) height() float64 {
+	return float64(len(c.Bars))*(chartBarHeight+chartBarGap) + chartBarGap
+}
+
+func (c *Chart) total() int {
+	total := 0
+	for _, bar := range c.Bars {
+		total += bar.Value
+	}
+	return total
+}
+
+// barLength scales the bars so the longest fills the space between label and value
+func (c *Chart) barLength(bar Bar) float64 {
+	longest := 0
+	for _, b := range c.Bars {
+		longest = max(longest, b.Value)
+	}
+	if longest == 0 {
+		return 0
+	}
+	return (chartWidth - chartLabelWidth - chartValueWidth) * float64(bar.Value) / float64(longest)
+}
+
+// valueLabel is the count and share printed after a bar, e.g. "12 (40%)"
+func (c *Chart) valueLabel(bar Bar) string {
+	total := c.total()
+	if total == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%d (%.0f%%)", bar.Value, float64(bar.Value)*100/float64(total))
+}
+
+// truncate shortens text to n runes, marking the cut with an ellipsis
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// SVG renders the chart as a standalone SVG image
+func SVG(c *Chart, color string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="Helvetica, Arial, sans-serif" font-size="%.0f">`,
+		chartWidth, c.height(), chartWidth, c.height(), chartFontSize)
+	for i, bar := range c.Bars {
+		y := chartBarGap + float64(i)*(chartBarHeight+chartBarGap)
+		textY := y + chartBarHeight/2 + chartFontSize/3
+		length := c.barLength(bar)
+		fmt.Fprintf(&b, `<text x="%.0f" y="%.1f" text-anchor="end" fill="#333">%s</text>`,
+			chartLabelWidth-8, textY, html.EscapeString(truncate(bar.Label, chartLabelLength)))
+		fmt.Fprintf(&b, `<rect x="%.0f" y="%.1f" width="%.1f" height="%.0f" rx="2" fill="%s"/>`,
+			chartLabelWidth, y, length, chartBarHeight, html.EscapeString(color))
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" fill="#555">%s</text>`,
+			chartLabelWidth+length+6, textY, c.valueLabel(bar))
+	}
+	b.WriteString("</svg>")
+	return b.String()
+}
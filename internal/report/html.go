@@ -0,0 +1,98 @@
+package report
+
+import (
+	"bytes"
+	"html/template"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"paragraphs": paragraphs,
+	"inc":        func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Doc.Title}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #222; max-width: 820px; margin: 0 auto; padding: 0 24px 48px; line-height: 1.5; }
+header { background: {{.Color}}; color: #fff; padding: 24px; margin: 0 -24px 24px; }
+header .brand { font-size: 13px; letter-spacing: .08em; text-transform: uppercase; opacity: .85; }
+header h1 { margin: 4px 0 0; font-size: 26px; }
+dl.details { display: grid; grid-template-columns: max-content 1fr; gap: 4px 16px; margin: 0 0 24px; }
+dl.details dt { font-weight: bold; }
+dl.details dd { margin: 0; }
+h2 { color: {{.Color}}; border-bottom: 2px solid {{.Color}}; padding-bottom: 4px; margin-top: 36px; }
+.kind { color: #777; font-size: 12px; text-transform: uppercase; letter-spacing: .06em; }
+p { white-space: pre-line; }
+table { border-collapse: collapse; margin: 12px 0; min-width: 60%; }
+th, td { padding: 4px 12px; border-bottom: 1px solid #ddd; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+th { background: #f4f6f8; }
+figure { margin: 12px 0; }
+ul.stats { padding-left: 20px; }
+footer { margin-top: 48px; color: #777; font-size: 12px; border-top: 1px solid #ddd; padding-top: 8px; }
+</style>
+</head>
+<body>
+<header>
+<div class="brand">{{.Doc.Brand.Name}}</div>
+<h1>{{.Doc.Title}}</h1>
+</header>
+{{with .Doc.Details}}<dl class="details">
+{{range .}}<dt>{{.Label}}</dt><dd>{{.Value}}</dd>
+{{end}}</dl>{{end}}
+{{with .Doc.Analysis}}<section>
+<h2>Analysis</h2>
+{{range paragraphs .}}<p>{{.}}</p>
+{{end}}</section>{{end}}
+{{range $i, $section := .Sections}}<section>
+<h2>{{inc $i}}. {{$section.Title}}</h2>
+<div class="kind">{{$section.Kind}}</div>
+{{range paragraphs $section.Summary}}<p>{{.}}</p>
+{{end}}{{with $section.Table}}<table>
+<tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>{{end}}
+{{with $section.Chart}}<figure>{{.}}</figure>{{end}}
+{{with $section.Stats}}<ul class="stats">
+{{range .}}<li><strong>{{.Label}}:</strong> {{.Value}}</li>
+{{end}}</ul>{{end}}
+</section>
+{{end}}<footer>{{.Footer}}</footer>
+</body>
+</html>
+`))
+
+// htmlSection carries a section with its chart already rendered to SVG
+type htmlSection struct {
+	Section
+	Chart template.HTML
+}
+
+// RenderHTML writes the report as a standalone HTML page with inline SVG charts
+func RenderHTML(doc *Document) ([]byte, error) {
+	data := struct {
+		Doc      *Document
+		Color    template.CSS
+		Sections []htmlSection
+		Footer   string
+	}{
+		Doc: doc,
+		// The colour is validated when the configuration is loaded.
+		Color:  template.CSS(doc.Brand.Color),
+		Footer: footer(doc),
+	}
+	for _, section := range doc.Sections {
+		s := htmlSection{Section: section}
+		if section.Chart != nil {
+			// SVG escapes the labels itself.
+			s.Chart = template.HTML(SVG(section.Chart, doc.Brand.Color))
+		}
+		data.Sections = append(data.Sections, s)
+	}
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
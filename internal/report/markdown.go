@@ -0,0 +1,81 @@
+package report
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// RenderMarkdown writes the report as Markdown, embedding the charts as SVG data URIs
+func RenderMarkdown(doc *Document) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", markdownLine(doc.Title))
+	for _, field := range doc.Details {
+		fmt.Fprintf(&b, "- **%s:** %s\n", field.Label, markdownLine(field.Value))
+	}
+	if len(doc.Details) > 0 {
+		b.WriteString("\n")
+	}
+
+	if doc.Analysis != "" {
+		fmt.Fprintf(&b, "## Analysis\n\n%s\n\n", strings.TrimSpace(doc.Analysis))
+	}
+
+	for i, section := range doc.Sections {
+		fmt.Fprintf(&b, "## %d. %s\n\n", i+1, markdownLine(section.Title))
+		fmt.Fprintf(&b, "_%s_\n\n", section.Kind)
+		if section.Summary != "" {
+			fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(section.Summary))
+		}
+		if section.Table != nil {
+			writeMarkdownTable(&b, section.Table)
+		}
+		if section.Chart != nil {
+			svg := base64.StdEncoding.EncodeToString([]byte(SVG(section.Chart, doc.Brand.Color)))
+			fmt.Fprintf(&b, "![Distribution of answers](data:image/svg+xml;base64,%s)\n\n", svg)
+		}
+		for _, field := range section.Stats {
+			fmt.Fprintf(&b, "- **%s:** %s\n", field.Label, markdownLine(field.Value))
+		}
+		if len(section.Stats) > 0 {
+			b.WriteString("\n")
+		}
+	}
+
+	fmt.Fprintf(&b, "---\n\n_%s_\n", footer(doc))
+	return []byte(b.String())
+}
+
+func writeMarkdownTable(b *strings.Builder, table *Table) {
+	b.WriteString("|")
+	for _, cell := range table.Header {
+		fmt.Fprintf(b, " %s |", markdownCell(cell))
+	}
+	b.WriteString("\n|")
+	for i := range table.Header {
+		if i == 0 {
+			b.WriteString(" --- |")
+		} else {
+			b.WriteString(" ---: |")
+		}
+	}
+	b.WriteString("\n")
+	for _, row := range table.Rows {
+		b.WriteString("|")
+		for _, cell := range row {
+			fmt.Fprintf(b, " %s |", markdownCell(cell))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+}
+
+// markdownLine keeps a value on one line, as in headings and list items
+func markdownLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// markdownCell also escapes the pipes that would end a table cell
+func markdownCell(text string) string {
+	return strings.ReplaceAll(markdownLine(text), "|", `\|`)
+}
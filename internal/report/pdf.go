@@ -0,0 +1,524 @@
+package report
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// A4 page geometry in points
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	pageMargin   = 56.0
+	contentWidth = pageWidth - 2*pageMargin
+	headerHeight = 110.0
+	footerY      = 30.0
+	bodySize     = 10.5
+	leading      = 1.4 // line height as a multiple of the font size
+)
+
+// pdfFont is one of the fonts every page references as /F1 to /F4. Latin text uses the
+// standard Helvetica fonts; Chinese and Korean text use the Adobe CJK fonts, which PDF
+// viewers supply themselves, so no font file has to be embedded.
+type pdfFont int
+
+const (
+	fontRegular pdfFont = iota + 1
+	fontBold
+	fontChinese
+	fontKorean
+)
+
+// Helvetica and Helvetica-Bold advance widths of the characters from space to tilde,
+// in thousandths of the font size, from the Adobe font metrics
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// winAnsiExtras are the WinAnsiEncoding characters outside Latin-1, with their width
+var winAnsiExtras = map[rune]struct {
+	code  byte
+	width int
+}{
+	'€': {0x80, 556}, '…': {0x85, 1000}, '‘': {0x91, 222}, '’': {0x92, 222},
+	'“': {0x93, 333}, '”': {0x94, 333}, '•': {0x95, 350}, '–': {0x96, 556},
+	'—': {0x97, 1000}, '™': {0x99, 1000},
+}
+
+// winAnsiCode returns the byte of r in the Helvetica fonts, if they have it
+func winAnsiCode(r rune) (byte, bool) {
+	if (r >= 0x20 && r < 0x7f) || (r >= 0xa0 && r <= 0xff) {
+		return byte(r), true
+	}
+	extra, ok := winAnsiExtras[r]
+	return extra.code, ok
+}
+
+func fontFor(r rune, bold bool) pdfFont {
+	switch {
+	case isLatin(r) && bold:
+		return fontBold
+	case isLatin(r):
+		return fontRegular
+	case unicode.Is(unicode.Hangul, r):
+		return fontKorean
+	}
+	return fontChinese
+}
+
+func isLatin(r rune) bool {
+	_, ok := winAnsiCode(r)
+	return ok
+}
+
+// runeWidth is the advance width of r in thousandths of the font size
+func runeWidth(r rune, bold bool) int {
+	switch {
+	case r >= 0x20 && r < 0x7f && bold:
+		return helveticaBoldWidths[r-0x20]
+	case r >= 0x20 && r < 0x7f:
+		return helveticaWidths[r-0x20]
+	case isLatin(r):
+		if extra, ok := winAnsiExtras[r]; ok {
+			return extra.width
+		}
+		return 556
+	}
+	// CJK characters are full width.
+	return 1000
+}
+
+func textWidth(text string, size float64, bold bool) float64 {
+	width := 0
+	for _, r := range text {
+		width += runeWidth(r, bold)
+	}
+	return float64(width) * size / 1000
+}
+
+// sanitize replaces the characters a text line cannot show
+func sanitize(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t':
+			return ' '
+		case r == '\n':
+			return r
+		case unicode.IsControl(r), r == unicode.ReplacementChar:
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// tokenize splits text into words, single spaces and single CJK characters, the units
+// a line may break between
+func tokenize(text string) []string {
+	var tokens []string
+	start := -1
+	for i, r := range text {
+		if !unicode.IsSpace(r) && isLatin(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, text[start:i])
+			start = -1
+		}
+		if unicode.IsSpace(r) {
+			tokens = append(tokens, " ")
+		} else {
+			tokens = append(tokens, string(r))
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
+
+// wrapText breaks text into lines no wider than width, keeping its line breaks
+func wrapText(text string, size float64, bold bool, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(sanitize(text), "\n") {
+		var line strings.Builder
+		lineWidth := 0.0
+		flush := func() {
+			lines = append(lines, strings.TrimRight(line.String(), " "))
+			line.Reset()
+			lineWidth = 0
+		}
+		for _, token := range tokenize(paragraph) {
+			w := textWidth(token, size, bold)
+			if token == " " && lineWidth == 0 {
+				continue
+			}
+			if lineWidth > 0 && lineWidth+w > width {
+				flush()
+				if token == " " {
+					continue
+				}
+			}
+			// A word wider than a whole line is broken anywhere.
+			for w > width {
+				head := fitText(token, size, bold, width)
+				line.WriteString(head)
+				flush()
+				token = token[len(head):]
+				w = textWidth(token, size, bold)
+			}
+			line.WriteString(token)
+			lineWidth += w
+		}
+		flush()
+	}
+	return lines
+}
+
+// fitText returns the longest prefix of text, at least one character, that fits width
+func fitText(text string, size float64, bold bool, width float64) string {
+	used := 0.0
+	for i, r := range text {
+		used += float64(runeWidth(r, bold)) * size / 1000
+		if used > width && i > 0 {
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// ellipsize shortens text to fit width, marking the cut with an ellipsis
+func ellipsize(text string, size float64, bold bool, width float64) string {
+	text = strings.Join(strings.Fields(sanitize(text)), " ")
+	if textWidth(text, size, bold) <= width {
+		return text
+	}
+	return strings.TrimRight(fitText(text, size, bold, width-textWidth("…", size, bold)), " ") + "…"
+}
+
+// encodeRun writes text in a font as a PDF string: bytes for the Helvetica fonts and
+// UTF-16 for the CJK fonts, whose encodings map Unicode directly
+func encodeRun(font pdfFont, text []rune) string {
+	var b strings.Builder
+	if font == fontChinese || font == fontKorean {
+		b.WriteString("<")
+		for _, unit := range utf16.Encode(text) {
+			fmt.Fprintf(&b, "%04X", unit)
+		}
+		b.WriteString(">")
+		return b.String()
+	}
+	b.WriteString("(")
+	for _, r := range text {
+		code, _ := winAnsiCode(r)
+		switch {
+		case code == '(' || code == ')' || code == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(code)
+		case code >= 0x80:
+			fmt.Fprintf(&b, "\\%03o", code)
+		default:
+			b.WriteByte(code)
+		}
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+type rgb [3]float64
+
+func (c rgb) fill() string {
+	return fmt.Sprintf("%.3f %.3f %.3f rg", c[0], c[1], c[2])
+}
+
+func (c rgb) stroke() string {
+	return fmt.Sprintf("%.3f %.3f %.3f RG", c[0], c[1], c[2])
+}
+
+// tint mixes the colour with white
+func (c rgb) tint(amount float64) rgb {
+	return rgb{1 - (1-c[0])*amount, 1 - (1-c[1])*amount, 1 - (1-c[2])*amount}
+}
+
+var (
+	textColor  = rgb{0.13, 0.13, 0.13}
+	mutedColor = rgb{0.45, 0.45, 0.45}
+	white      = rgb{1, 1, 1}
+)
+
+// parseColor reads a #rrggbb colour, falling back to a blue
+func parseColor(hex string) rgb {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(hex) != 7 {
+		return rgb{0.12, 0.44, 0.92}
+	}
+	return rgb{float64(value>>16&0xff) / 255, float64(value>>8&0xff) / 255, float64(value&0xff) / 255}
+}
+
+// pdfWriter lays out a report top to bottom, starting a new page when one is full
+type pdfWriter struct {
+	brand rgb
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64 // top of the next element
+}
+
+func (p *pdfWriter) newPage() {
+	p.page = &bytes.Buffer{}
+	p.pages = append(p.pages, p.page)
+	p.y = pageHeight - pageMargin
+}
+
+// reserve starts a new page unless height still fits on the current one
+func (p *pdfWriter) reserve(height float64) {
+	if p.y-height < pageMargin {
+		p.newPage()
+	}
+}
+
+// text draws a line of text with its baseline at y, switching fonts per script
+func (p *pdfWriter) text(x, y, size float64, bold bool, color rgb, text string) {
+	runes := []rune(sanitize(text))
+	if len(runes) == 0 {
+		return
+	}
+	fmt.Fprintf(p.page, "%s BT %.2f %.2f Td", color.fill(), x, y)
+	for start := 0; start < len(runes); {
+		font := fontFor(runes[start], bold)
+		end := start + 1
+		for end < len(runes) && fontFor(runes[end], bold) == font {
+			end++
+		}
+		fmt.Fprintf(p.page, " /F%d %.1f Tf %s Tj", font, size, encodeRun(font, runes[start:end]))
+		start = end
+	}
+	p.page.WriteString(" ET\n")
+}
+
+func (p *pdfWriter) rect(x, y, width, height float64, color rgb) {
+	fmt.Fprintf(p.page, "%s %.2f %.2f %.2f %.2f re f\n", color.fill(), x, y, width, height)
+}
+
+func (p *pdfWriter) line(x1, y1, x2, y2, width float64, color rgb) {
+	fmt.Fprintf(p.page, "%s %.2f w %.2f %.2f m %.2f %.2f l S\n", color.stroke(), width, x1, y1, x2, y2)
+}
+
+// paragraph draws wrapped text at the cursor
+func (p *pdfWriter) paragraph(text string, size float64, bold bool, color rgb) {
+	for _, line := range wrapText(text, size, bold, contentWidth) {
+		p.reserve(size * leading)
+		p.text(pageMargin, p.y-size, size, bold, color, line)
+		p.y -= size * leading
+	}
+	p.y -= size * 0.6
+}
+
+// heading draws a section title in the brand colour, kept on a page with what follows
+func (p *pdfWriter) heading(text string) {
+	const size = 14.0
+	lines := wrapText(text, size, true, contentWidth)
+	p.reserve(float64(len(lines))*size*leading + 60)
+	p.y -= size * 0.8
+	for _, line := range lines {
+		p.text(pageMargin, p.y-size, size, true, p.brand, line)
+		p.y -= size * leading
+	}
+	p.line(pageMargin, p.y+2, pageWidth-pageMargin, p.y+2, 1, p.brand)
+	p.y -= 8
+}
+
+// field draws a bold label followed by its wrapped value
+func (p *pdfWriter) field(label, value string) {
+	label += ": "
+	indent := textWidth(label, bodySize, true)
+	lines := wrapText(value, bodySize, false, contentWidth-indent)
+	for i, line := range lines {
+		p.reserve(bodySize * leading)
+		if i == 0 {
+			p.text(pageMargin, p.y-bodySize, bodySize, true, textColor, label)
+		}
+		p.text(pageMargin+indent, p.y-bodySize, bodySize, false, textColor, line)
+		p.y -= bodySize * leading
+	}
+}
+
+func (p *pdfWriter) table(table *Table) {
+	const size, rowHeight, numericWidth = 9.5, 17.0, 80.0
+	numeric := len(table.Header) - 1
+	firstWidth := contentWidth - numericWidth*float64(numeric)
+	row := func(cells []string, bold bool) {
+		p.reserve(rowHeight)
+		baseline := p.y - rowHeight + 5
+		if bold {
+			p.rect(pageMargin, p.y-rowHeight, contentWidth, rowHeight, p.brand.tint(0.12))
+		}
+		for i, cell := range cells {
+			if i == 0 {
+				p.text(pageMargin+4, baseline, size, bold, textColor, ellipsize(cell, size, bold, firstWidth-8))
+				continue
+			}
+			right := pageMargin + firstWidth + numericWidth*float64(i)
+			cell = ellipsize(cell, size, bold, numericWidth-8)
+			p.text(right-4-textWidth(cell, size, bold), baseline, size, bold, textColor, cell)
+		}
+		p.y -= rowHeight
+		p.line(pageMargin, p.y, pageWidth-pageMargin, p.y, 0.5, rgb{0.85, 0.85, 0.85})
+	}
+	p.y -= 4
+	row(table.Header, true)
+	for _, cells := range table.Rows {
+		row(cells, false)
+	}
+	p.y -= 10
+}
+
+// chart draws the bars with the geometry of the SVG charts
+func (p *pdfWriter) chart(chart *Chart) {
+	p.reserve(chart.height())
+	for i, bar := range chart.Bars {
+		top := p.y - chartBarGap - float64(i)*(chartBarHeight+chartBarGap)
+		baseline := top - chartBarHeight/2 - chartFontSize/3
+		label := ellipsize(bar.Label, chartFontSize, false, chartLabelWidth-16)
+		p.text(pageMargin+chartLabelWidth-8-textWidth(label, chartFontSize, false), baseline, chartFontSize, false, textColor, label)
+		length := chart.barLength(bar)
+		p.rect(pageMargin+chartLabelWidth, top-chartBarHeight, length, chartBarHeight, p.brand)
+		p.text(pageMargin+chartLabelWidth+length+6, baseline, chartFontSize, false, mutedColor, chart.valueLabel(bar))
+	}
+	p.y -= chart.height() + 10
+}
+
+// RenderPDF writes the report as a PDF document
+func RenderPDF(doc *Document) ([]byte, error) {
+	p := &pdfWriter{brand: parseColor(doc.Brand.Color)}
+	p.newPage()
+
+	// Brand band with the title
+	p.rect(0, pageHeight-headerHeight, pageWidth, headerHeight, p.brand)
+	p.text(pageMargin, pageHeight-40, 9, true, white, strings.ToUpper(doc.Brand.Name))
+	titleLines := wrapText(doc.Title, 20, true, contentWidth)
+	for i, line := range titleLines[:min(len(titleLines), 2)] {
+		p.text(pageMargin, pageHeight-68-float64(i)*24, 20, true, white, line)
+	}
+	p.y = pageHeight - headerHeight - 24
+
+	for _, field := range doc.Details {
+		p.field(field.Label, field.Value)
+	}
+	if doc.Analysis != "" {
+		p.heading("Analysis")
+		for _, text := range paragraphs(doc.Analysis) {
+			p.paragraph(text, bodySize, false, textColor)
+		}
+	}
+	for i, section := range doc.Sections {
+		p.heading(fmt.Sprintf("%d. %s", i+1, section.Title))
+		p.paragraph(section.Kind, 8, true, mutedColor)
+		for _, text := range paragraphs(section.Summary) {
+			p.paragraph(text, bodySize, false, textColor)
+		}
+		if section.Table != nil {
+			p.table(section.Table)
+		}
+		if section.Chart != nil {
+			p.chart(section.Chart)
+		}
+		for _, field := range section.Stats {
+			p.field(field.Label, field.Value)
+		}
+	}
+
+	footerText := footer(doc)
+	for i, page := range p.pages {
+		p.page = page
+		p.text(pageMargin, footerY, 8, false, mutedColor, footerText)
+		number := fmt.Sprintf("Page %d of %d", i+1, len(p.pages))
+		p.text(pageWidth-pageMargin-textWidth(number, 8, false), footerY, 8, false, mutedColor, number)
+	}
+	return p.encode(doc)
+}
+
+// encode writes the pages as PDF objects: the catalog, the page tree, the fonts and the
+// document info, followed by a page and a compressed content stream per page
+func (p *pdfWriter) encode(doc *Document) ([]byte, error) {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	const firstPage = 12
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UTF16-H /DescendantFonts [6 0 R] >>")
+	object("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light /CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 4 >> /FontDescriptor 7 0 R /DW 1000 >>")
+	object("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	object("<< /Type /Font /Subtype /Type0 /BaseFont /HYSMyeongJo-Medium /Encoding /UniKS-UTF16-H /DescendantFonts [9 0 R] >>")
+	object("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /HYSMyeongJo-Medium /CIDSystemInfo << /Registry (Adobe) /Ordering (Korea1) /Supplement 1 >> /FontDescriptor 10 0 R /DW 1000 >>")
+	object("<< /Type /FontDescriptor /FontName /HYSMyeongJo-Medium /Flags 6 /FontBBox [-28 -148 1001 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	object(fmt.Sprintf("<< /Title %s /Producer %s /CreationDate (D:%s) >>",
+		pdfTextString(doc.Title), pdfTextString(doc.Brand.Name), doc.GeneratedAt.UTC().Format("20060102150405Z")))
+
+	for i, page := range p.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R /F3 5 0 R /F4 8 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, firstPage+2*i+1))
+		var content bytes.Buffer
+		zw := zlib.NewWriter(&content)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 11 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes(), nil
+}
+
+// pdfTextString encodes text for the document info as UTF-16 with a byte order mark
+func pdfTextString(text string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(text)) {
+		fmt.Fprintf(&b, "%04X", unit)
+	}
+	b.WriteString(">")
+	return b.String()
+}
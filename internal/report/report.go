@@ -0,0 +1,118 @@
+package report
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Brand is the name and accent colour printed on every report
+type Brand struct {
+	Name  string
+	Color string // hex, e.g. #1f6feb
+}
+
+// Document is a format-independent report: the analysis followed by one section per question
+type Document struct {
+	Brand       Brand
+	Title       string
+	GeneratedAt time.Time
+	Details     []Field // shown under the title, e.g. the number of submissions
+	Analysis    string
+	Sections    []Section
+}
+
+// Field is a labelled value
+type Field struct {
+	Label string
+	Value string
+}
+
+// Section reports on one question
+type Section struct {
+	Title   string
+	Kind    string // question type, e.g. LIKERT
+	Summary string
+	Stats   []Field
+	Table   *Table
+	Chart   *Chart
+}
+
+// Table is a statistics table; columns after the first are numeric and right-aligned
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Format is an export format of a report
+type Format string
+
+const (
+	Markdown Format = "md"
+	HTML     Format = "html"
+	PDF      Format = "pdf"
+)
+
+// ContentType returns the MIME type of a format
+func (f Format) ContentType() string {
+	switch f {
+	case HTML:
+		return "text/html; charset=utf-8"
+	case PDF:
+		return "application/pdf"
+	default:
+		return "text/markdown; charset=utf-8"
+	}
+}
+
+// Render writes the document in the given format
+func Render(doc *Document, format Format) ([]byte, error) {
+	switch format {
+	case Markdown:
+		return RenderMarkdown(doc), nil
+	case HTML:
+		return RenderHTML(doc)
+	case PDF:
+		return RenderPDF(doc)
+	}
+	return nil, fmt.Errorf("unknown report format %q", format)
+}
+
+// footer is printed at the end of every format
+func footer(doc *Document) string {
+	return fmt.Sprintf("%s insight report, generated %s", doc.Brand.Name, doc.GeneratedAt.UTC().Format("2006-01-02 15:04 UTC"))
+}
+
+var (
+	emphasisPattern = regexp.MustCompile(`\*\*|__`)
+	headingPattern  = regexp.MustCompile(`^#{1,6}\s+`)
+	bulletPattern   = regexp.MustCompile(`^\s*[-*]\s+`)
+	blankLine       = regexp.MustCompile(`\n\s*\n`)
+)
+
+// plainText drops the markdown emphasis and headings LLM summaries often contain and
+// turns list markers into bullets, for the formats that do not render markdown
+func plainText(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		line = emphasisPattern.ReplaceAllString(line, "")
+		line = headingPattern.ReplaceAllString(line, "")
+		if bulletPattern.MatchString(line) {
+			line = "• " + bulletPattern.ReplaceAllString(line, "")
+		}
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// paragraphs splits plain text on blank lines
+func paragraphs(text string) []string {
+	var result []string
+	for _, p := range blankLine.Split(plainText(text), -1) {
+		if p = strings.TrimSpace(p); p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
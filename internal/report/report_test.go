@@ -0,0 +1,136 @@
+package report
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testDocument() *Document {
+	return &Document{
+		Brand:       Brand{Name: "OSP", Color: "#1f6feb"},
+		Title:       "Staff pulse <Q3>",
+		GeneratedAt: time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC),
+		Details:     []Field{{Label: "Submissions", Value: "42"}},
+		Analysis:    "Staff are **mostly** positive.\n\n- Overtime is a concern",
+		Sections: []Section{
+			{
+				Title:   "Would you recommend us?",
+				Kind:    "MULTIPLE_CHOICE",
+				Summary: "Most would.",
+				Table:   &Table{Header: []string{"Answer", "Count", "Share"}, Rows: [][]string{{"Yes | sure", "30", "75.0%"}, {"No", "10", "25.0%"}}},
+				Chart:   &Chart{Bars: []Bar{{Label: "Yes | sure", Value: 30}, {Label: "No", Value: 10}}},
+			},
+			{
+				Title:   "What could be better?",
+				Kind:    "TEXTBOX",
+				Summary: "加班太多，經理很好。Some want more training.",
+				Stats:   []Field{{Label: "Languages", Value: "en 3, yue 2"}},
+			},
+		},
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	md := string(RenderMarkdown(testDocument()))
+
+	assert.True(t, strings.HasPrefix(md, "# Staff pulse <Q3>\n\n- **Submissions:** 42\n"))
+	assert.Contains(t, md, "## Analysis\n\nStaff are **mostly** positive.")
+	assert.Contains(t, md, "| Answer | Count | Share |\n| --- | ---: | ---: |\n| Yes \\| sure | 30 | 75.0% |\n")
+	assert.Contains(t, md, "![Distribution of answers](data:image/svg+xml;base64,")
+	assert.Contains(t, md, "## 2. What could be better?\n\n_TEXTBOX_")
+	assert.Contains(t, md, "- **Languages:** en 3, yue 2")
+	assert.True(t, strings.HasSuffix(md, "_OSP insight report, generated 2026-10-18 09:30 UTC_\n"))
+}
+
+func TestRenderHTML(t *testing.T) {
+	page, err := RenderHTML(testDocument())
+	html := string(page)
+
+	assert.NoError(t, err)
+	assert.Contains(t, html, "<h1>Staff pulse &lt;Q3&gt;</h1>")
+	assert.Contains(t, html, "header { background: #1f6feb;")
+	// Markdown from the LLM is flattened and the SVG chart is inlined.
+	assert.Contains(t, html, "<p>Staff are mostly positive.</p>\n<p>• Overtime is a concern</p>")
+	assert.Contains(t, html, `<figure><svg xmlns="http://www.w3.org/2000/svg"`)
+	assert.Contains(t, html, "<td>Yes | sure</td>")
+}
+
+func TestSVG(t *testing.T) {
+	svg := SVG(&Chart{Bars: []Bar{{Label: "<b>", Value: 3}, {Label: "Other", Value: 1}}}, "#ff0000")
+
+	assert.Contains(t, svg, `height="50"`)
+	assert.Contains(t, svg, "&lt;b&gt;")
+	// The longest bar fills the space between label and value.
+	assert.Contains(t, svg, `<rect x="150" y="6.0" width="260.0" height="16" rx="2" fill="#ff0000"/>`)
+	assert.Contains(t, svg, ">3 (75%)</text>")
+}
+
+func TestRenderPDF(t *testing.T) {
+	out, err := RenderPDF(testDocument())
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+
+	// Every cross-reference entry points at the start of its object.
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	assert.Len(t, entries, 13)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(out[offset:], fmt.Appendf(nil, "%d 0 obj", i+1)), "object %d", i+1)
+	}
+
+	// Latin text uses Helvetica and Chinese text the CJK font, as UTF-16.
+	content := pageContent(t, out)
+	assert.Contains(t, content, "/F2 14.0 Tf (1. Would you recommend us?) Tj")
+	assert.Contains(t, content, "/F3 10.5 Tf <52A073ED592A591AFF0C7D9374065F88597D3002> Tj /F1 10.5 Tf (Some want more training.) Tj")
+	assert.Contains(t, content, "(Page 1 of 1) Tj")
+
+	t.Run("Pages", func(t *testing.T) {
+		doc := testDocument()
+		for range 40 {
+			doc.Sections = append(doc.Sections, doc.Sections[0])
+		}
+		out, err := RenderPDF(doc)
+		assert.NoError(t, err)
+		pages := regexp.MustCompile(`/Count (\d+)`).FindSubmatch(out)
+		count, _ := strconv.Atoi(string(pages[1]))
+		assert.Greater(t, count, 5)
+		assert.Equal(t, count, bytes.Count(out, []byte("/Type /Page ")))
+	})
+}
+
+// pageContent inflates the content stream of the first page
+func pageContent(t *testing.T, pdf []byte) string {
+	start := bytes.Index(pdf, []byte("stream\n")) + len("stream\n")
+	end := bytes.Index(pdf[start:], []byte("\nendstream"))
+	r, err := zlib.NewReader(bytes.NewReader(pdf[start : start+end]))
+	assert.NoError(t, err)
+	content, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	return string(content)
+}
+
+func TestWrapText(t *testing.T) {
+	// Lines break between words, and anywhere within Chinese text.
+	assert.Equal(t, []string{"aaa bbb", "ccc"}, wrapText("aaa bbb ccc", 10, false, 40))
+	assert.Equal(t, []string{"加班太", "多"}, wrapText("加班太多", 10, false, 30))
+	assert.Equal(t, []string{"a", "", "b"}, wrapText("a\n\nb", 10, false, 100))
+	// A word longer than a line is broken.
+	assert.Equal(t, []string{"mmmm", "mm"}, wrapText("mmmmmm", 10, false, 35))
+}
+
+func TestEncodeRun(t *testing.T) {
+	assert.Equal(t, `(f\(x\) \\ caf\351 \225)`, encodeRun(fontRegular, []rune(`f(x) \ café •`)))
+	assert.Equal(t, "<D55C>", encodeRun(fontKorean, []rune("한")))
+}
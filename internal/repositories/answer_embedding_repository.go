@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"osp/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type AnswerEmbeddingRepository interface {
+	// ReplaceForSubmission swaps the embeddings of a submission for the given ones
+	ReplaceForSubmission(ctx context.Context, submissionID bson.ObjectID, embeddings []*models.AnswerEmbedding) error
+	// FindBySurvey returns the embeddings of a survey made by a model, optionally of one question
+	FindBySurvey(ctx context.Context, surveyID bson.ObjectID, model string, questionID *bson.ObjectID) ([]*models.AnswerEmbedding, error)
+	DeleteBySubmission(ctx context.Context, submissionID bson.ObjectID) error
+}
+
+type MongoAnswerEmbeddingRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoAnswerEmbeddingRepository(collection *mongo.Collection) *MongoAnswerEmbeddingRepository {
+	return &MongoAnswerEmbeddingRepository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes indexes the embeddings by survey and model for searches, and by
+// submission for replacements
+func (r *MongoAnswerEmbeddingRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "survey_id", Value: 1}, {Key: "model", Value: 1}}},
+		{Keys: bson.D{{Key: "submission_id", Value: 1}}},
+	})
+	return err
+}
+
+func (r *MongoAnswerEmbeddingRepository) ReplaceForSubmission(ctx context.Context, submissionID bson.ObjectID, embeddings []*models.AnswerEmbedding) error {
+	// A retried job embeds the whole submission again, so earlier vectors are dropped first.
+	if err := r.DeleteBySubmission(ctx, submissionID); err != nil {
+		return err
+	}
+	if len(embeddings) == 0 {
+		return nil
+	}
+	_, err := r.collection.InsertMany(ctx, embeddings)
+	return err
+}
+
+func (r *MongoAnswerEmbeddingRepository) FindBySurvey(ctx context.Context, surveyID bson.ObjectID, model string, questionID *bson.ObjectID) ([]*models.AnswerEmbedding, error) {
+	filter := bson.M{"survey_id": surveyID, "model": model}
+	if questionID != nil {
+		filter["question_id"] = *questionID
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var embeddings []*models.AnswerEmbedding
+	if err := cursor.All(ctx, &embeddings); err != nil {
+		return nil, err
+	}
+	return embeddings, nil
+}
+
+func (r *MongoAnswerEmbeddingRepository) DeleteBySubmission(ctx context.Context, submissionID bson.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"submission_id": submissionID})
+	return err
+}
@@ -0,0 +1,215 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"osp/internal/models"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type ChatCompletionLogRepository interface {
+	Create(ctx context.Context, entry *models.ChatCompletionRequestLog) error
+	Update(ctx context.Context, id bson.ObjectID, update bson.M) error
+	List(ctx context.Context, filter models.ChatCompletionLogFilter, offset, limit int64) ([]*models.ChatCompletionRequestLog, int64, error)
+	GetByID(ctx context.Context, id bson.ObjectID) (*models.ChatCompletionRequestLog, error)
+	UsageTotals(ctx context.Context, filter models.UsageFilter) (*models.UsageTotals, error)
+	DailyUsage(ctx context.Context, filter models.UsageFilter) ([]models.DailyUsage, error)
+	SurveyUsage(ctx context.Context, filter models.UsageFilter) ([]models.SurveyUsage, error)
+	InsightUsage(ctx context.Context, filter models.UsageFilter) ([]models.InsightUsage, error)
+}
+
+type MongoChatCompletionLogRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoChatCompletionLogRepository(collection *mongo.Collection) *MongoChatCompletionLogRepository {
+	return &MongoChatCompletionLogRepository{
+		collection: collection,
+	}
+}
+
+func (r *MongoChatCompletionLogRepository) Create(ctx context.Context, entry *models.ChatCompletionRequestLog) error {
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *MongoChatCompletionLogRepository) Update(ctx context.Context, id bson.ObjectID, update bson.M) error {
+	_, err := r.collection.UpdateByID(ctx, id, update)
+	return err
+}
+
+// EnsureIndexes indexes the logs by creation time. With a positive retention the index
+// is a TTL index, so MongoDB deletes logs older than retention; an existing TTL index is
+// updated when the retention changes.
+func (r *MongoChatCompletionLogRepository) EnsureIndexes(ctx context.Context, retention time.Duration) error {
+	const name = "created_at_1"
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "created_at", Value: 1}},
+		Options: options.Index().SetName(name),
+	}
+	if retention > 0 {
+		index.Options.SetExpireAfterSeconds(int32(retention.Seconds()))
+	}
+	_, err := r.collection.Indexes().CreateOne(ctx, index)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == indexOptionsConflictCode {
+		// The index exists with a different expiry; collMod changes it in place.
+		expireAfter := int64(retention.Seconds())
+		if retention <= 0 {
+			// collMod cannot remove a TTL; ten years effectively keeps every log.
+			expireAfter = int64((10 * 365 * 24 * time.Hour).Seconds())
+		}
+		return r.collection.Database().RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: r.collection.Name()},
+			{Key: "index", Value: bson.M{"name": name, "expireAfterSeconds": expireAfter}},
+		}).Err()
+	}
+	return err
+}
+
+// indexOptionsConflictCode is returned when an index exists with other options
+const indexOptionsConflictCode = 85
+
+func (r *MongoChatCompletionLogRepository) List(ctx context.Context, filter models.ChatCompletionLogFilter, offset, limit int64) ([]*models.ChatCompletionRequestLog, int64, error) {
+	query := bson.M{}
+	if filter.Reference != "" {
+		query["reference"] = bson.M{"$regex": regexp.QuoteMeta(filter.Reference)}
+	}
+	if filter.InsightID != nil {
+		query["insight_id"] = *filter.InsightID
+	}
+	if filter.Model != "" {
+		query["model"] = filter.Model
+	}
+	if filter.From != nil || filter.To != nil {
+		createdAt := bson.M{}
+		if filter.From != nil {
+			createdAt["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			createdAt["$lt"] = *filter.To
+		}
+		query["created_at"] = createdAt
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(offset).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var logs []*models.ChatCompletionRequestLog
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+func (r *MongoChatCompletionLogRepository) GetByID(ctx context.Context, id bson.ObjectID) (*models.ChatCompletionRequestLog, error) {
+	var entry models.ChatCompletionRequestLog
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&entry)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *MongoChatCompletionLogRepository) UsageTotals(ctx context.Context, filter models.UsageFilter) (*models.UsageTotals, error) {
+	var totals []models.UsageTotals
+	if err := r.aggregateUsage(ctx, filter, nil, nil, &totals); err != nil {
+		return nil, err
+	}
+	if len(totals) == 0 {
+		return &models.UsageTotals{}, nil
+	}
+	return &totals[0], nil
+}
+
+func (r *MongoChatCompletionLogRepository) DailyUsage(ctx context.Context, filter models.UsageFilter) ([]models.DailyUsage, error) {
+	day := bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$created_at"}}
+	var daily []models.DailyUsage
+	if err := r.aggregateUsage(ctx, filter, day, bson.D{{Key: "_id", Value: 1}}, &daily); err != nil {
+		return nil, err
+	}
+	return daily, nil
+}
+
+func (r *MongoChatCompletionLogRepository) SurveyUsage(ctx context.Context, filter models.UsageFilter) ([]models.SurveyUsage, error) {
+	var surveys []models.SurveyUsage
+	if err := r.aggregateUsage(ctx, filter, "$survey_id", bson.D{{Key: "cost", Value: -1}}, &surveys); err != nil {
+		return nil, err
+	}
+	return surveys, nil
+}
+
+func (r *MongoChatCompletionLogRepository) InsightUsage(ctx context.Context, filter models.UsageFilter) ([]models.InsightUsage, error) {
+	var insights []models.InsightUsage
+	if err := r.aggregateUsage(ctx, filter, "$insight_id", bson.D{{Key: "cost", Value: -1}}, &insights); err != nil {
+		return nil, err
+	}
+	return insights, nil
+}
+
+// aggregateUsage sums the logs matching filter per group key; a nil key sums all logs
+func (r *MongoChatCompletionLogRepository) aggregateUsage(ctx context.Context, filter models.UsageFilter, key any, sort bson.D, results any) error {
+	match := bson.M{
+		"created_at": bson.M{"$gte": filter.From, "$lt": filter.To},
+	}
+	// Grouping by survey or insight skips logs that are not attributed to one.
+	switch key {
+	case "$survey_id":
+		match["survey_id"] = bson.M{"$ne": nil}
+	case "$insight_id":
+		match["insight_id"] = bson.M{"$ne": nil}
+	}
+	if filter.SurveyID != nil {
+		match["survey_id"] = *filter.SurveyID
+	}
+
+	group := bson.M{
+		"_id":               key,
+		"requests":          bson.M{"$sum": 1},
+		"cached_requests":   bson.M{"$sum": bson.M{"$cond": bson.A{"$cached", 1, 0}}},
+		"prompt_tokens":     bson.M{"$sum": "$usage.prompt_tokens"},
+		"completion_tokens": bson.M{"$sum": "$usage.completion_tokens"},
+		"total_tokens":      bson.M{"$sum": "$usage.total_tokens"},
+		"cost":              bson.M{"$sum": "$cost"},
+		"avg_latency_ms":    bson.M{"$avg": "$latency_ms"},
+	}
+	if key == "$insight_id" {
+		group["survey_id"] = bson.M{"$first": "$survey_id"}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: group}},
+	}
+	if sort != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: sort}})
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, results)
+}
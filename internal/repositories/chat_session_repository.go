@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"osp/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type ChatSessionRepository interface {
+	Create(ctx context.Context, session *models.ChatSession) error
+	GetByID(ctx context.Context, id bson.ObjectID) (*models.ChatSession, error)
+	List(ctx context.Context, offset, limit int64, surveyID *bson.ObjectID) ([]*models.ChatSession, error)
+	// AppendMessages adds messages to the end of a session's thread
+	AppendMessages(ctx context.Context, id bson.ObjectID, messages ...models.ChatSessionMessage) error
+	Delete(ctx context.Context, id bson.ObjectID) error
+}
+
+type MongoChatSessionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoChatSessionRepository(collection *mongo.Collection) *MongoChatSessionRepository {
+	return &MongoChatSessionRepository{
+		collection: collection,
+	}
+}
+
+func (r *MongoChatSessionRepository) Create(ctx context.Context, session *models.ChatSession) error {
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+func (r *MongoChatSessionRepository) GetByID(ctx context.Context, id bson.ObjectID) (*models.ChatSession, error) {
+	var session models.ChatSession
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *MongoChatSessionRepository) List(ctx context.Context, offset, limit int64, surveyID *bson.ObjectID) ([]*models.ChatSession, error) {
+	filter := bson.M{}
+	if surveyID != nil {
+		filter["survey_id"] = *surveyID
+	}
+	// Threads can be long; listings leave them out.
+	opts := options.Find().
+		SetSkip(offset).
+		SetLimit(limit).
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetProjection(bson.M{"messages": 0})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []*models.ChatSession
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *MongoChatSessionRepository) AppendMessages(ctx context.Context, id bson.ObjectID, messages ...models.ChatSessionMessage) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$push": bson.M{"messages": bson.M{"$each": messages}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MongoChatSessionRepository) Delete(ctx context.Context, id bson.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"osp/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type InsightComparisonRepository interface {
+	Create(ctx context.Context, comparison *models.InsightComparison) error
+	GetByID(ctx context.Context, id bson.ObjectID) (*models.InsightComparison, error)
+	List(ctx context.Context, offset, limit int64) ([]*models.InsightComparison, error)
+}
+
+type MongoInsightComparisonRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoInsightComparisonRepository(collection *mongo.Collection) *MongoInsightComparisonRepository {
+	return &MongoInsightComparisonRepository{
+		collection: collection,
+	}
+}
+
+func (r *MongoInsightComparisonRepository) Create(ctx context.Context, comparison *models.InsightComparison) error {
+	_, err := r.collection.InsertOne(ctx, comparison)
+	return err
+}
+
+func (r *MongoInsightComparisonRepository) GetByID(ctx context.Context, id bson.ObjectID) (*models.InsightComparison, error) {
+	var comparison models.InsightComparison
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&comparison)
+	if err != nil {
+		return nil, err
+	}
+	return &comparison, nil
+}
+
+func (r *MongoInsightComparisonRepository) List(ctx context.Context, offset, limit int64) ([]*models.InsightComparison, error) {
+	opts := options.Find().
+		SetSkip(offset).
+		SetLimit(limit).
+		SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var comparisons []*models.InsightComparison
+	if err := cursor.All(ctx, &comparisons); err != nil {
+		return nil, err
+	}
+	return comparisons, nil
+}
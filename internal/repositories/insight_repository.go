@@ -14,6 +14,10 @@ type InsightRepository interface {
 	GetByID(ctx context.Context, id bson.ObjectID) (*models.Insight, error)
 	GetInsights(ctx context.Context, offset, limit int64, surveyID *bson.ObjectID) ([]*models.Insight, error)
 	Update(ctx context.Context, id bson.ObjectID, update interface{}) error
+	// UpdateUnlessCancelled applies update only if the insight is not cancelled, and
+	// reports whether it did
+	UpdateUnlessCancelled(ctx context.Context, id bson.ObjectID, update interface{}) (bool, error)
+	Delete(ctx context.Context, id bson.ObjectID) error
 }
 
 type MongoInsightRepository struct {
@@ -75,3 +79,16 @@ func (r *MongoInsightRepository) Update(ctx context.Context, id bson.ObjectID, u
 	_, err := r.collection.UpdateByID(ctx, id, update)
 	return err
 }
+
+func (r *MongoInsightRepository) UpdateUnlessCancelled(ctx context.Context, id bson.ObjectID, update interface{}) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "status": bson.M{"$ne": models.InsightCancelled}}, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *MongoInsightRepository) Delete(ctx context.Context, id bson.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"osp/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type InsightScheduleRepository interface {
+	Create(ctx context.Context, schedule *models.InsightSchedule) error
+	GetByID(ctx context.Context, id bson.ObjectID) (*models.InsightSchedule, error)
+	List(ctx context.Context, offset, limit int64, surveyID *bson.ObjectID) ([]*models.InsightSchedule, error)
+	// ListEnabled returns every enabled schedule, for the periodic task manager
+	ListEnabled(ctx context.Context) ([]*models.InsightSchedule, error)
+	Update(ctx context.Context, schedule *models.InsightSchedule) error
+	SetLastRunAt(ctx context.Context, id bson.ObjectID, lastRunAt time.Time) error
+	Delete(ctx context.Context, id bson.ObjectID) error
+}
+
+type MongoInsightScheduleRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoInsightScheduleRepository(collection *mongo.Collection) *MongoInsightScheduleRepository {
+	return &MongoInsightScheduleRepository{
+		collection: collection,
+	}
+}
+
+func (r *MongoInsightScheduleRepository) Create(ctx context.Context, schedule *models.InsightSchedule) error {
+	_, err := r.collection.InsertOne(ctx, schedule)
+	return err
+}
+
+func (r *MongoInsightScheduleRepository) GetByID(ctx context.Context, id bson.ObjectID) (*models.InsightSchedule, error) {
+	var schedule models.InsightSchedule
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *MongoInsightScheduleRepository) List(ctx context.Context, offset, limit int64, surveyID *bson.ObjectID) ([]*models.InsightSchedule, error) {
+	filter := bson.M{}
+	if surveyID != nil {
+		filter["survey_id"] = *surveyID
+	}
+	opts := options.Find().
+		SetSkip(offset).
+		SetLimit(limit).
+		SetSort(bson.D{{Key: "created_at", Value: -1}})
+	return r.find(ctx, filter, opts)
+}
+
+func (r *MongoInsightScheduleRepository) ListEnabled(ctx context.Context) ([]*models.InsightSchedule, error) {
+	return r.find(ctx, bson.M{"enabled": true}, options.Find())
+}
+
+func (r *MongoInsightScheduleRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptionsBuilder) ([]*models.InsightSchedule, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var schedules []*models.InsightSchedule
+	if err := cursor.All(ctx, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (r *MongoInsightScheduleRepository) Update(ctx context.Context, schedule *models.InsightSchedule) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": schedule.ID}, schedule)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MongoInsightScheduleRepository) SetLastRunAt(ctx context.Context, id bson.ObjectID, lastRunAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"last_run_at": lastRunAt},
+	})
+	return err
+}
+
+func (r *MongoInsightScheduleRepository) Delete(ctx context.Context, id bson.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
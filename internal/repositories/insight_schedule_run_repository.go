@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"osp/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type InsightScheduleRunRepository interface {
+	Create(ctx context.Context, run *models.InsightScheduleRun) error
+	// GetByTaskID returns the run recorded by an asynq task of a schedule
+	GetByTaskID(ctx context.Context, scheduleID bson.ObjectID, taskID string) (*models.InsightScheduleRun, error)
+	Update(ctx context.Context, run *models.InsightScheduleRun) error
+	// ListBySchedule returns the runs of a schedule, latest first
+	ListBySchedule(ctx context.Context, scheduleID bson.ObjectID, offset, limit int64) ([]*models.InsightScheduleRun, error)
+	DeleteBySchedule(ctx context.Context, scheduleID bson.ObjectID) error
+}
+
+type MongoInsightScheduleRunRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoInsightScheduleRunRepository(collection *mongo.Collection) *MongoInsightScheduleRunRepository {
+	return &MongoInsightScheduleRunRepository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes indexes the runs by schedule in the order they are listed, and by
+// task so that an asynq task records a single run
+func (r *MongoInsightScheduleRunRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "schedule_id", Value: 1}, {Key: "started_at", Value: -1}}},
+		{
+			Keys: bson.D{{Key: "schedule_id", Value: 1}, {Key: "task_id", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"task_id": bson.M{"$type": "string"}}),
+		},
+	})
+	return err
+}
+
+func (r *MongoInsightScheduleRunRepository) Create(ctx context.Context, run *models.InsightScheduleRun) error {
+	_, err := r.collection.InsertOne(ctx, run)
+	return err
+}
+
+func (r *MongoInsightScheduleRunRepository) GetByTaskID(ctx context.Context, scheduleID bson.ObjectID, taskID string) (*models.InsightScheduleRun, error) {
+	var run models.InsightScheduleRun
+	err := r.collection.FindOne(ctx, bson.M{"schedule_id": scheduleID, "task_id": taskID}).Decode(&run)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *MongoInsightScheduleRunRepository) Update(ctx context.Context, run *models.InsightScheduleRun) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": run.ID}, run)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MongoInsightScheduleRunRepository) ListBySchedule(ctx context.Context, scheduleID bson.ObjectID, offset, limit int64) ([]*models.InsightScheduleRun, error) {
+	opts := options.Find().
+		SetSkip(offset).
+		SetLimit(limit).
+		SetSort(bson.D{{Key: "started_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"schedule_id": scheduleID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var runs []*models.InsightScheduleRun
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (r *MongoInsightScheduleRunRepository) DeleteBySchedule(ctx context.Context, scheduleID bson.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"schedule_id": scheduleID})
+	return err
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"fmt"
 	"osp/internal/models"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -11,8 +12,13 @@ import (
 
 type SubmissionRepository interface {
 	Create(ctx context.Context, submission *models.Submission) error
-	GetAllSubmissions(ctx context.Context, surveyID bson.ObjectID) ([]*models.Submission, error)
-	GetSubmissions(ctx context.Context, offset int64, limit int64, surveyID *bson.ObjectID) ([]*models.Submission, error)
+	GetAllSubmissions(ctx context.Context, surveyID bson.ObjectID, filter *models.SubmissionFilter) ([]*models.Submission, error)
+	// Count returns the number of submissions GetAllSubmissions would return
+	Count(ctx context.Context, surveyID bson.ObjectID, filter *models.SubmissionFilter) (int64, error)
+	GetSubmissions(ctx context.Context, offset int64, limit int64, surveyID *bson.ObjectID, sentiment *models.SentimentFilter) ([]*models.Submission, error)
+	GetByID(ctx context.Context, id bson.ObjectID) (*models.Submission, error)
+	// UpdateSentiments sets the sentiment of the responses at the given indexes
+	UpdateSentiments(ctx context.Context, id bson.ObjectID, sentiments map[int]*models.Sentiment) error
 	Delete(ctx context.Context, id bson.ObjectID) error
 }
 
@@ -31,9 +37,8 @@ func (r *MongoSubmissionRepository) Create(ctx context.Context, submission *mode
 	return err
 }
 
-func (r *MongoSubmissionRepository) GetAllSubmissions(ctx context.Context, surveyID bson.ObjectID) ([]*models.Submission, error) {
-	filter := bson.M{"survey_id": surveyID}
-	cursor, err := r.collection.Find(ctx, filter)
+func (r *MongoSubmissionRepository) GetAllSubmissions(ctx context.Context, surveyID bson.ObjectID, filter *models.SubmissionFilter) ([]*models.Submission, error) {
+	cursor, err := r.collection.Find(ctx, submissionFilterQuery(surveyID, filter))
 	if err != nil {
 		return nil, err
 	}
@@ -50,11 +55,62 @@ func (r *MongoSubmissionRepository) GetAllSubmissions(ctx context.Context, surve
 	return submissions, nil
 }
 
-func (r *MongoSubmissionRepository) GetSubmissions(ctx context.Context, offset int64, limit int64, surveyID *bson.ObjectID) ([]*models.Submission, error) {
+func (r *MongoSubmissionRepository) Count(ctx context.Context, surveyID bson.ObjectID, filter *models.SubmissionFilter) (int64, error) {
+	return r.collection.CountDocuments(ctx, submissionFilterQuery(surveyID, filter))
+}
+
+// submissionFilterQuery translates a SubmissionFilter into a MongoDB query.
+// LIKERT ranges are expected to be already expanded into Values by the caller.
+func submissionFilterQuery(surveyID bson.ObjectID, filter *models.SubmissionFilter) bson.M {
+	query := bson.M{"survey_id": surveyID}
+	if filter == nil {
+		return query
+	}
+
+	createdAt := bson.M{}
+	if filter.From != nil {
+		createdAt["$gte"] = *filter.From
+	}
+	if filter.To != nil {
+		createdAt["$lt"] = *filter.To
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+
+	answerConditions := bson.A{}
+	for _, answer := range filter.Answers {
+		answerConditions = append(answerConditions, bson.M{
+			"responses": bson.M{
+				"$elemMatch": bson.M{
+					"question_id": answer.QuestionID,
+					"answer":      bson.M{"$in": answer.Values},
+				},
+			},
+		})
+	}
+	if len(answerConditions) > 0 {
+		query["$and"] = answerConditions
+	}
+
+	for key, value := range filter.Metadata {
+		query["metadata."+key] = value
+	}
+	return query
+}
+
+func (r *MongoSubmissionRepository) GetSubmissions(ctx context.Context, offset int64, limit int64, surveyID *bson.ObjectID, sentiment *models.SentimentFilter) ([]*models.Submission, error) {
 	filter := bson.M{}
 	if surveyID != nil {
 		filter["survey_id"] = *surveyID
 	}
+	if sentiment != nil {
+		match := bson.M{"sentiment.label": sentiment.Label}
+		if sentiment.QuestionID != nil {
+			match["question_id"] = *sentiment.QuestionID
+		}
+		filter["responses"] = bson.M{"$elemMatch": match}
+	}
 
 	opts := options.Find().
 		SetSkip(offset).
@@ -82,6 +138,31 @@ func (r *MongoSubmissionRepository) GetSubmissions(ctx context.Context, offset i
 
 }
 
+func (r *MongoSubmissionRepository) GetByID(ctx context.Context, id bson.ObjectID) (*models.Submission, error) {
+	var submission models.Submission
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&submission)
+	if err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
+
+func (r *MongoSubmissionRepository) UpdateSentiments(ctx context.Context, id bson.ObjectID, sentiments map[int]*models.Sentiment) error {
+	// updated_at is left alone: scoring is not an edit of the submission.
+	set := bson.M{}
+	for i, sentiment := range sentiments {
+		set[fmt.Sprintf("responses.%d.sentiment", i)] = sentiment
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
 func (r *MongoSubmissionRepository) Delete(ctx context.Context, id bson.ObjectID) error {
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
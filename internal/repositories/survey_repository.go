@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"osp/internal/models"
+	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -15,6 +16,8 @@ type SurveyRepository interface {
 	GetByToken(ctx context.Context, token string) (*models.Survey, error)
 	GetByID(ctx context.Context, id bson.ObjectID) (*models.Survey, error)
 	Delete(ctx context.Context, id bson.ObjectID) error
+	UpdateTokenBudget(ctx context.Context, id bson.ObjectID, budget *models.TokenBudget) error
+	UpdateRedaction(ctx context.Context, id bson.ObjectID, settings *models.RedactionSettings) error
 }
 
 type MongoSurveyRepository struct {
@@ -39,7 +42,7 @@ func (r *MongoSurveyRepository) List(ctx context.Context, offset, limit int64) (
 	}
 
 	opts := options.Find().
-		SetSort(bson.D{{"created_at", -1}}).
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
 		SetSkip(offset).
 		SetLimit(limit)
 
@@ -78,3 +81,29 @@ func (r *MongoSurveyRepository) Delete(ctx context.Context, id bson.ObjectID) er
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
 }
+
+func (r *MongoSurveyRepository) UpdateTokenBudget(ctx context.Context, id bson.ObjectID, budget *models.TokenBudget) error {
+	result, err := r.collection.UpdateByID(ctx, id, bson.M{
+		"$set": bson.M{"token_budget": budget, "updated_at": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *MongoSurveyRepository) UpdateRedaction(ctx context.Context, id bson.ObjectID, settings *models.RedactionSettings) error {
+	result, err := r.collection.UpdateByID(ctx, id, bson.M{
+		"$set": bson.M{"redaction": settings, "updated_at": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -1,10 +1,14 @@
 package routes
 
 import (
+	"context"
+	"log"
 	"osp/internal/config"
+	"osp/internal/embedding"
 	"osp/internal/handlers"
 	"osp/internal/middleware"
 	"osp/internal/models"
+	"osp/internal/report"
 	"osp/internal/repositories"
 	"osp/internal/services"
 
@@ -40,10 +44,82 @@ func setupAPIRoutes(api *gin.RouterGroup, cfg *config.Config, client *mongo.Clie
 	surveyService := services.NewSurveyService(surveyRepo)
 	surveyHandler := handlers.NewSurveyHandler(surveyService)
 
-	chatCompletionService := services.NewChatCompletionService(db.Collection("chat_completion_logs"))
-	insightService := services.NewInsightService(insightRepo, surveyRepo, submissionRepo, chatCompletionService, jobSystem.Client)
+	chatCompletionLogRepo := repositories.NewMongoChatCompletionLogRepository(db.Collection("chat_completion_logs"))
+	if err := chatCompletionLogRepo.EnsureIndexes(context.Background(), cfg.LLMLogRetention); err != nil {
+		log.Printf("chat completion log indexes failed: %v", err)
+	}
+	budgetService := services.NewBudgetService(chatCompletionLogRepo, surveyRepo, cfg.LLMTokenBudget)
+	budgetHandler := handlers.NewBudgetHandler(budgetService)
+
+	var responseCache services.ResponseCache
+	var cacheStats services.CacheStatsReader
+	if cfg.LLMCacheTTL > 0 {
+		redisCache := services.NewRedisResponseCache(jobSystem.Redis, cfg.LLMCacheTTL)
+		responseCache, cacheStats = redisCache, redisCache
+	}
+	chatCompletionService := services.NewChatCompletionService(chatCompletionLogRepo, services.NewRedisCircuitBreaker(jobSystem.Redis), budgetService, responseCache, cfg.LLMPrices)
+	insightService := services.NewInsightService(insightRepo, surveyRepo, submissionRepo, chatCompletionService, jobSystem.Client, jobSystem.Inspector, services.NewRedisInsightEventBroker(jobSystem.Redis), budgetService, services.InsightSettings{
+		BatchConcurrency: cfg.InsightBatchConcurrency,
+		TokenBudgets:     cfg.InsightTokenBudgets,
+		RedactionTerms:   cfg.PIIRedactionTerms,
+		AnalysisLanguage: cfg.InsightAnalysisLanguage,
+	})
 	insightService.RegisterHandlers(jobSystem.Mux)
+
+	var sentimentChat services.IChatCompletionService
+	if cfg.SentimentAnalyzer == "llm" {
+		sentimentChat = chatCompletionService
+	}
+	sentimentService := services.NewSentimentService(submissionRepo, surveyRepo, sentimentChat, jobSystem.Client, cfg.PIIRedactionTerms)
+	sentimentService.RegisterHandlers(jobSystem.Mux)
 	insightHandler := handlers.NewInsightHandler(insightService)
+	insightExportService := services.NewInsightExportService(insightRepo, surveyRepo, report.Brand{
+		Name:  cfg.ReportBrandName,
+		Color: cfg.ReportBrandColor,
+	})
+	insightExportHandler := handlers.NewInsightExportHandler(insightExportService)
+
+	answerEmbeddingRepo := repositories.NewMongoAnswerEmbeddingRepository(db.Collection("answer_embeddings"))
+	if err := answerEmbeddingRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("answer embedding indexes failed: %v", err)
+	}
+	var embeddingProvider services.IEmbeddingProvider = services.NewGitHubEmbeddingProvider(cfg.EmbeddingModel, chatCompletionLogRepo, budgetService, cfg.LLMPrices)
+	if cfg.EmbeddingProvider == "local" {
+		embeddingProvider = embedding.Hash{}
+	}
+	embeddingService := services.NewEmbeddingService(answerEmbeddingRepo, submissionRepo, surveyRepo, embeddingProvider, jobSystem.Client, cfg.PIIRedactionTerms)
+	embeddingService.RegisterHandlers(jobSystem.Mux)
+	embeddingHandler := handlers.NewEmbeddingHandler(embeddingService)
+
+	crossTabService := services.NewCrossTabService(surveyRepo, submissionRepo, chatCompletionService)
+	crossTabHandler := handlers.NewCrossTabHandler(crossTabService)
+
+	textAnalyticsService := services.NewTextAnalyticsService(surveyRepo, submissionRepo)
+	textAnalyticsHandler := handlers.NewTextAnalyticsHandler(textAnalyticsService)
+
+	comparisonRepo := repositories.NewMongoInsightComparisonRepository(db.Collection("insight_comparisons"))
+	comparisonService := services.NewInsightComparisonService(comparisonRepo, insightRepo, chatCompletionService)
+	comparisonHandler := handlers.NewInsightComparisonHandler(comparisonService)
+
+	chatSessionRepo := repositories.NewMongoChatSessionRepository(db.Collection("chat_sessions"))
+	chatSessionService := services.NewChatSessionService(chatSessionRepo, surveyRepo, submissionRepo, insightRepo, chatCompletionService, cfg.PIIRedactionTerms)
+	chatSessionHandler := handlers.NewChatSessionHandler(chatSessionService)
+
+	scheduleRunRepo := repositories.NewMongoInsightScheduleRunRepository(db.Collection("insight_schedule_runs"))
+	if err := scheduleRunRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("insight schedule run indexes failed: %v", err)
+	}
+	scheduleRepo := repositories.NewMongoInsightScheduleRepository(db.Collection("insight_schedules"))
+	scheduleService := services.NewInsightScheduleService(scheduleRepo, scheduleRunRepo, surveyRepo, submissionRepo, insightService)
+	scheduleService.RegisterHandlers(jobSystem.Mux)
+	jobSystem.PeriodicTasks.Register(scheduleService)
+	scheduleHandler := handlers.NewInsightScheduleHandler(scheduleService)
+
+	usageService := services.NewUsageService(chatCompletionLogRepo, cacheStats)
+	usageHandler := handlers.NewUsageHandler(usageService)
+
+	chatCompletionLogService := services.NewChatCompletionLogService(chatCompletionLogRepo, chatCompletionService)
+	chatCompletionLogHandler := handlers.NewChatCompletionLogHandler(chatCompletionLogService)
 
 	// Health check endpoint
 	api.GET("/health", func(c *gin.Context) {
@@ -58,7 +134,7 @@ func setupAPIRoutes(api *gin.RouterGroup, cfg *config.Config, client *mongo.Clie
 		surveys.GET("/:token", surveyHandler.GetSurveyByToken)
 	}
 	// Submissions routes
-	submissionService := services.NewSubmissionService(submissionRepo, surveyRepo)
+	submissionService := services.NewSubmissionService(submissionRepo, surveyRepo, sentimentService, embeddingService)
 	submissionHandler := handlers.NewSubmissionHandler(submissionService)
 	submissions := api.Group("/submissions")
 	{
@@ -74,10 +150,17 @@ func setupAPIRoutes(api *gin.RouterGroup, cfg *config.Config, client *mongo.Clie
 			surveys.GET("", surveyHandler.ListSurveys)
 			surveys.GET("/:id", surveyHandler.GetSurvey)
 			surveys.DELETE("/:id", surveyHandler.DeleteSurvey)
+			surveys.PUT("/:id/token-budget", surveyHandler.SetTokenBudget)
+			surveys.PUT("/:id/redaction", surveyHandler.SetRedaction)
+			surveys.GET("/:id/crosstab", crossTabHandler.CrossTabulate)
+			surveys.GET("/:id/questions/:questionId/text-analytics", textAnalyticsHandler.GetTextAnalytics)
+			surveys.GET("/:id/search", embeddingHandler.Search)
+			surveys.POST("/:id/embeddings", embeddingHandler.ReindexSurvey)
 		}
 		submissions := admin.Group("/submissions")
 		{
 			submissions.GET("/", submissionHandler.GetSubmissions)
+			submissions.GET("/:id", submissionHandler.GetSubmission)
 			submissions.DELETE("/:id", submissionHandler.DeleteSubmission)
 		}
 		insights := admin.Group("/insights")
@@ -85,6 +168,43 @@ func setupAPIRoutes(api *gin.RouterGroup, cfg *config.Config, client *mongo.Clie
 			insights.POST("", insightHandler.CreateInsight)
 			insights.GET("", insightHandler.GetInsights)
 			insights.GET("/:id", insightHandler.GetInsight)
+			insights.POST("/:id/retry", insightHandler.RetryInsight)
+			insights.POST("/:id/regenerate", insightHandler.RegenerateInsight)
+			insights.GET("/:id/events", insightHandler.WatchInsight)
+			insights.GET("/:id/export", insightExportHandler.ExportInsight)
+			insights.POST("/:id/cancel", insightHandler.CancelInsight)
+			insights.DELETE("/:id", insightHandler.DeleteInsight)
+		}
+		comparisons := admin.Group("/insight-comparisons")
+		{
+			comparisons.POST("", comparisonHandler.CreateComparison)
+			comparisons.GET("", comparisonHandler.GetComparisons)
+			comparisons.GET("/:id", comparisonHandler.GetComparison)
+		}
+		schedules := admin.Group("/insight-schedules")
+		{
+			schedules.POST("", scheduleHandler.CreateSchedule)
+			schedules.GET("", scheduleHandler.GetSchedules)
+			schedules.GET("/:id", scheduleHandler.GetSchedule)
+			schedules.PUT("/:id", scheduleHandler.UpdateSchedule)
+			schedules.DELETE("/:id", scheduleHandler.DeleteSchedule)
+			schedules.GET("/:id/runs", scheduleHandler.GetRuns)
+		}
+		chatSessions := admin.Group("/chat-sessions")
+		{
+			chatSessions.POST("", chatSessionHandler.CreateSession)
+			chatSessions.GET("", chatSessionHandler.GetSessions)
+			chatSessions.GET("/:id", chatSessionHandler.GetSession)
+			chatSessions.POST("/:id/messages", chatSessionHandler.Ask)
+			chatSessions.DELETE("/:id", chatSessionHandler.DeleteSession)
+		}
+		admin.GET("/usage", usageHandler.GetUsage)
+		admin.GET("/budget", budgetHandler.GetBudget)
+		llmLogs := admin.Group("/llm-logs")
+		{
+			llmLogs.GET("", chatCompletionLogHandler.GetLogs)
+			llmLogs.GET("/:id", chatCompletionLogHandler.GetLog)
+			llmLogs.POST("/:id/replay", chatCompletionLogHandler.ReplayLog)
 		}
 	}
 }
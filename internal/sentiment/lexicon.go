@@ -0,0 +1,180 @@
+package sentiment
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Lexicon scores texts offline from word valences, in the spirit of VADER: negations
+// flip the next sentiment word, intensifiers strengthen it, and the sum is normalized
+// to (-1, 1). English words are matched whole; Chinese terms are matched as substrings
+// and negated by a negation character directly in front.
+type Lexicon struct {
+	words map[string]float64
+	terms []string // Chinese terms, longest first so 沉悶 wins over 悶
+}
+
+// NewLexicon returns a lexicon with the built-in word lists
+func NewLexicon() *Lexicon {
+	terms := make([]string, 0, len(chineseValences))
+	for term := range chineseValences {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if len(terms[i]) != len(terms[j]) {
+			return len(terms[i]) > len(terms[j])
+		}
+		return terms[i] < terms[j]
+	})
+	return &Lexicon{words: englishValences, terms: terms}
+}
+
+func (l *Lexicon) Name() string {
+	return "lexicon-v1"
+}
+
+func (l *Lexicon) Analyze(ctx context.Context, texts []string) ([]Result, error) {
+	results := make([]Result, len(texts))
+	for i, text := range texts {
+		score := l.Score(text)
+		results[i] = Result{Label: LabelFor(score), Score: score, Analyzer: l.Name()}
+	}
+	return results, nil
+}
+
+const (
+	// normalizationAlpha approximates the largest expected sum of valences
+	normalizationAlpha = 15
+	// negationWindow is the number of words a negation applies to
+	negationWindow = 3
+	// intensifierBoost scales the valence of the word after an intensifier
+	intensifierBoost = 1.5
+	// negationFactor flips and dampens a negated valence; "not great" is milder than "bad"
+	negationFactor = -0.75
+)
+
+// Score returns the sentiment score of text, from -1 to 1
+func (l *Lexicon) Score(text string) float64 {
+	sum := l.scoreWords(strings.ToLower(text)) + l.scoreTerms(text)
+	if sum == 0 {
+		return 0
+	}
+	return sum / math.Sqrt(sum*sum+normalizationAlpha)
+}
+
+func (l *Lexicon) scoreWords(text string) float64 {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !(unicode.IsLetter(r) && r < unicode.MaxLatin1) && r != '\''
+	})
+	sum := 0.0
+	negatedFor := 0 // words left in the current negation window
+	boost := 1.0
+	for _, word := range words {
+		word = strings.Trim(word, "'")
+		switch {
+		case negators[word] || strings.HasSuffix(word, "n't"):
+			negatedFor = negationWindow
+			continue
+		case intensifiers[word]:
+			boost = intensifierBoost
+			continue
+		}
+		if valence, ok := l.words[word]; ok {
+			valence *= boost
+			if negatedFor > 0 {
+				valence *= negationFactor
+				negatedFor = 0
+			}
+			sum += valence
+		}
+		boost = 1
+		if negatedFor > 0 {
+			negatedFor--
+		}
+	}
+	return sum
+}
+
+func (l *Lexicon) scoreTerms(text string) float64 {
+	sum := 0.0
+	covered := make([]bool, len(text)) // bytes already matched by a longer term
+	for _, term := range l.terms {
+		for offset := 0; ; {
+			i := strings.Index(text[offset:], term)
+			if i < 0 {
+				break
+			}
+			start, end := offset+i, offset+i+len(term)
+			offset = end
+			if slices.Contains(covered[start:end], true) {
+				continue
+			}
+			for j := start; j < end; j++ {
+				covered[j] = true
+			}
+			valence := chineseValences[term]
+			if before, _ := utf8.DecodeLastRuneInString(text[:start]); chineseNegators[before] {
+				valence *= negationFactor
+			} else if chineseIntensifiers[before] {
+				valence *= intensifierBoost
+			}
+			sum += valence
+		}
+	}
+	return sum
+}
+
+var negators = map[string]bool{
+	"not": true, "no": true, "never": true, "nothing": true, "hardly": true,
+	"barely": true, "without": true, "neither": true, "nor": true, "cannot": true,
+}
+
+var intensifiers = map[string]bool{
+	"very": true, "really": true, "extremely": true, "so": true, "super": true,
+	"incredibly": true, "highly": true, "totally": true, "absolutely": true,
+}
+
+var englishValences = map[string]float64{
+	// positive
+	"good": 1.9, "great": 3.1, "excellent": 3.2, "amazing": 2.8, "awesome": 3.1,
+	"fantastic": 2.6, "wonderful": 2.7, "love": 3.2, "loved": 2.9, "like": 1.5,
+	"liked": 1.6, "enjoy": 2.2, "enjoyed": 2.3, "helpful": 1.9, "useful": 1.9,
+	"clear": 1.6, "engaging": 2.0, "interesting": 1.7, "fun": 2.3, "happy": 2.7,
+	"satisfied": 1.8, "recommend": 1.5, "best": 3.2, "nice": 1.8, "easy": 1.9,
+	"friendly": 2.2, "patient": 1.6, "knowledgeable": 1.8, "well": 1.1, "perfect": 2.7,
+	"informative": 1.6, "supportive": 1.9, "impressive": 2.3, "smooth": 1.4, "fast": 0.8,
+	"thanks": 1.9, "thank": 1.5, "improved": 1.8, "valuable": 2.1, "organized": 1.4,
+	// negative
+	"bad": -2.5, "poor": -2.1, "terrible": -2.5, "awful": -2.0, "horrible": -2.5,
+	"worst": -3.1, "hate": -2.7, "hated": -3.2, "boring": -1.3, "confusing": -1.3,
+	"confused": -1.3, "difficult": -1.0, "hard": -0.4, "slow": -0.8, "rushed": -1.3,
+	"unclear": -1.6, "useless": -1.8, "waste": -1.8, "disappointed": -1.9, "disappointing": -2.2,
+	"disorganized": -1.8, "frustrating": -2.0, "frustrated": -2.2, "annoying": -1.7, "rude": -2.0,
+	"late": -0.9, "broken": -1.6, "problem": -1.7, "problems": -1.7, "issue": -0.9,
+	"issues": -1.0, "lacking": -1.3, "unhelpful": -1.9, "stressful": -1.9, "expensive": -0.9,
+	"unfair": -2.1, "crowded": -1.0, "noisy": -1.0, "bug": -1.1, "bugs": -1.1,
+}
+
+var chineseNegators = map[rune]bool{
+	'不': true, '沒': true, '没': true, '無': true, '无': true, '唔': true, '未': true, '冇': true,
+}
+
+var chineseIntensifiers = map[rune]bool{
+	'很': true, '好': true, '非': true, '太': true, '超': true, '極': true, '极': true, '最': true,
+}
+
+var chineseValences = map[string]float64{
+	// positive
+	"很好": 2.2, "不錯": 1.8, "不错": 1.8, "好玩": 2.0, "喜歡": 2.2, "喜欢": 2.2, "滿意": 1.8, "满意": 1.8, "有用": 1.9,
+	"清楚": 1.6, "推薦": 1.5, "推荐": 1.5, "優秀": 2.8, "优秀": 2.8, "精彩": 2.6,
+	"開心": 2.7, "开心": 2.7, "有趣": 1.9, "感謝": 1.9, "感谢": 1.9, "讚": 2.3, "赞": 2.3,
+	// negative
+	"差": -2.5, "不好": -1.9, "失望": -1.9, "沉悶": -1.3, "沉闷": -1.3, "悶": -1.3, "闷": -1.3,
+	"困難": -1.0, "困难": -1.0, "浪費": -1.8, "浪费": -1.8, "討厭": -2.7, "讨厌": -2.7,
+	"混亂": -1.8, "混乱": -1.8, "難明": -1.6, "难懂": -1.6, "太快": -1.3, "太慢": -1.3,
+}
@@ -0,0 +1,69 @@
+package sentiment
+
+import (
+	"context"
+	"fmt"
+)
+
+type Label string
+
+const (
+	Positive Label = "POSITIVE"
+	Neutral  Label = "NEUTRAL"
+	Negative Label = "NEGATIVE"
+)
+
+// neutralThreshold is the score below which a text counts as neutral either way
+const neutralThreshold = 0.05
+
+// Result is the sentiment of one text
+type Result struct {
+	Label    Label
+	Score    float64 // from -1 (negative) to 1 (positive)
+	Analyzer string  // name of the analyzer that produced it
+}
+
+// Analyzer scores texts; implementations return one result per text, in order
+type Analyzer interface {
+	Name() string
+	Analyze(ctx context.Context, texts []string) ([]Result, error)
+}
+
+// LabelFor labels a score
+func LabelFor(score float64) Label {
+	switch {
+	case score >= neutralThreshold:
+		return Positive
+	case score <= -neutralThreshold:
+		return Negative
+	default:
+		return Neutral
+	}
+}
+
+// Fallback uses Secondary whenever Primary fails, e.g. a lexicon when the LLM is
+// unavailable or out of budget
+type Fallback struct {
+	Primary   Analyzer
+	Secondary Analyzer
+	// OnFallback, if set, is called with the error of Primary
+	OnFallback func(err error)
+}
+
+func (f *Fallback) Name() string {
+	return f.Primary.Name() + "+" + f.Secondary.Name()
+}
+
+func (f *Fallback) Analyze(ctx context.Context, texts []string) ([]Result, error) {
+	results, err := f.Primary.Analyze(ctx, texts)
+	if err == nil && len(results) != len(texts) {
+		err = fmt.Errorf("%s returned %d results for %d texts", f.Primary.Name(), len(results), len(texts))
+	}
+	if err == nil {
+		return results, nil
+	}
+	if f.OnFallback != nil {
+		f.OnFallback(err)
+	}
+	return f.Secondary.Analyze(ctx, texts)
+}
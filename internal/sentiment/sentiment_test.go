@@ -0,0 +1,96 @@
+package sentiment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLexicon_Analyze(t *testing.T) {
+	l := NewLexicon()
+	cases := map[string]Label{
+		"The course was great and the tutor was really helpful": Positive,
+		"Boring lectures, and the pace was rushed":              Negative,
+		"The lab is on the second floor":                        Neutral,
+		"The slides were not clear":                             Negative,
+		"No problems at all":                                    Positive,
+		"I didn't enjoy the group project":                      Negative,
+		"老師很好，課程很精彩":                                            Positive,
+		"堂課好悶，教材太快":                                             Negative,
+		"講解不清楚":                                                 Negative,
+		"":                                                      Neutral,
+	}
+	for text, label := range cases {
+		results, err := l.Analyze(context.Background(), []string{text})
+		assert.NoError(t, err)
+		assert.Equal(t, label, results[0].Label, text)
+		assert.Equal(t, "lexicon-v1", results[0].Analyzer)
+		assert.True(t, results[0].Score > -1 && results[0].Score < 1)
+	}
+}
+
+func TestLexicon_Score(t *testing.T) {
+	l := NewLexicon()
+
+	assert.Greater(t, l.Score("very good"), l.Score("good"))
+	assert.Greater(t, l.Score("bad"), l.Score("very bad"))
+	// 悶 is part of 沉悶 and only counted once
+	assert.Equal(t, l.Score("悶"), l.Score("沉悶"))
+}
+
+type failingAnalyzer struct{}
+
+func (failingAnalyzer) Name() string { return "failing" }
+
+func (failingAnalyzer) Analyze(ctx context.Context, texts []string) ([]Result, error) {
+	return nil, errors.New("unavailable")
+}
+
+type shortAnalyzer struct{}
+
+func (shortAnalyzer) Name() string { return "short" }
+
+func (shortAnalyzer) Analyze(ctx context.Context, texts []string) ([]Result, error) {
+	return []Result{{Label: Positive, Score: 1, Analyzer: "short"}}, nil
+}
+
+func TestFallback(t *testing.T) {
+	t.Run("PrimaryFails", func(t *testing.T) {
+		var fallbackErr error
+		f := &Fallback{Primary: failingAnalyzer{}, Secondary: NewLexicon(), OnFallback: func(err error) { fallbackErr = err }}
+
+		results, err := f.Analyze(context.Background(), []string{"great", "bad"})
+
+		assert.NoError(t, err)
+		assert.EqualError(t, fallbackErr, "unavailable")
+		assert.Equal(t, []Label{Positive, Negative}, []Label{results[0].Label, results[1].Label})
+		assert.Equal(t, "lexicon-v1", results[0].Analyzer)
+	})
+
+	t.Run("WrongResultCount", func(t *testing.T) {
+		f := &Fallback{Primary: shortAnalyzer{}, Secondary: NewLexicon()}
+
+		results, err := f.Analyze(context.Background(), []string{"great", "bad"})
+
+		assert.NoError(t, err)
+		assert.Len(t, results, 2)
+		assert.Equal(t, "lexicon-v1", results[1].Analyzer)
+	})
+
+	t.Run("PrimarySucceeds", func(t *testing.T) {
+		f := &Fallback{Primary: shortAnalyzer{}, Secondary: NewLexicon()}
+
+		results, err := f.Analyze(context.Background(), []string{"bad"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "short", results[0].Analyzer)
+	})
+}
+
+func TestLabelFor(t *testing.T) {
+	assert.Equal(t, Positive, LabelFor(0.5))
+	assert.Equal(t, Neutral, LabelFor(0.01))
+	assert.Equal(t, Negative, LabelFor(-0.05))
+}
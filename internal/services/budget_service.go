@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"osp/internal/models"
+	"osp/internal/repositories"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ErrInsightOverBudget is returned when an insight is estimated to use more tokens than
+// the remaining budget and the caller did not confirm it
+var ErrInsightOverBudget = errors.New("insight exceeds the remaining token budget")
+
+// BudgetGuard stops LLM requests once a token budget is exhausted
+type BudgetGuard interface {
+	// Allow returns an LLMError of kind LLMErrorBudgetExceeded when a budget that applies
+	// to the survey (or the global budget, for a nil survey) is exhausted
+	Allow(ctx context.Context, surveyID *bson.ObjectID) error
+}
+
+type IBudgetService interface {
+	BudgetGuard
+	// GetBudget returns the configured budgets that apply to the survey in their current period
+	GetBudget(ctx context.Context, surveyID *bson.ObjectID) ([]models.BudgetUsage, error)
+}
+
+// budgetCacheTTL is how long the usage of a budget period and the budget of a survey
+// are reused, so that LLM requests do not each aggregate the logs. A budget can be
+// overrun by the requests made within it.
+const budgetCacheTTL = 5 * time.Second
+
+// BudgetService enforces the global token budget and the budgets set on surveys. Usage
+// is read from the chat completion logs, so every request made counts, whatever made it.
+type BudgetService struct {
+	logRepo    repositories.ChatCompletionLogRepository
+	surveyRepo repositories.SurveyRepository
+	global     models.TokenBudget
+	now        func() time.Time
+
+	mu      sync.Mutex
+	usage   map[budgetUsageKey]cachedBudgetValue[int]
+	surveys map[bson.ObjectID]cachedBudgetValue[*models.TokenBudget]
+}
+
+type cachedBudgetValue[T any] struct {
+	value   T
+	expires time.Time
+}
+
+func NewBudgetService(logRepo repositories.ChatCompletionLogRepository, surveyRepo repositories.SurveyRepository, global models.TokenBudget) *BudgetService {
+	return &BudgetService{
+		logRepo:    logRepo,
+		surveyRepo: surveyRepo,
+		global:     global,
+		now:        time.Now,
+		usage:      make(map[budgetUsageKey]cachedBudgetValue[int]),
+		surveys:    make(map[bson.ObjectID]cachedBudgetValue[*models.TokenBudget]),
+	}
+}
+
+func (s *BudgetService) GetBudget(ctx context.Context, surveyID *bson.ObjectID) ([]models.BudgetUsage, error) {
+	budgets := []models.BudgetUsage{}
+	add := func(scope models.BudgetScope, budget models.TokenBudget, surveyID *bson.ObjectID) error {
+		for _, period := range []models.BudgetPeriod{models.BudgetPeriodDaily, models.BudgetPeriodMonthly} {
+			limit := budget.Daily
+			if period == models.BudgetPeriodMonthly {
+				limit = budget.Monthly
+			}
+			if limit <= 0 {
+				continue
+			}
+			from, to := budgetPeriod(period, s.now())
+			used, err := s.usedTokens(ctx, from, to, surveyID)
+			if err != nil {
+				return err
+			}
+			budgets = append(budgets, models.BudgetUsage{
+				Scope:     scope,
+				Period:    period,
+				Limit:     limit,
+				Used:      used,
+				Remaining: max(limit-used, 0),
+			})
+		}
+		return nil
+	}
+
+	if err := add(models.BudgetScopeGlobal, s.global, nil); err != nil {
+		return nil, err
+	}
+	if surveyID != nil {
+		budget, err := s.surveyBudget(ctx, *surveyID)
+		if err != nil {
+			return nil, err
+		}
+		if budget != nil {
+			if err := add(models.BudgetScopeSurvey, *budget, surveyID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return budgets, nil
+}
+
+// usedTokens returns the tokens used in a period, by a survey or overall for a nil one
+func (s *BudgetService) usedTokens(ctx context.Context, from, to time.Time, surveyID *bson.ObjectID) (int, error) {
+	key := budgetUsageKey{from: from, to: to}
+	if surveyID != nil {
+		key.surveyID = *surveyID
+	}
+	if used, ok := cachedBudget(s, s.usage, key); ok {
+		return used, nil
+	}
+	totals, err := s.logRepo.UsageTotals(ctx, models.UsageFilter{From: from, To: to, SurveyID: surveyID})
+	if err != nil {
+		return 0, err
+	}
+	storeBudget(s, s.usage, key, totals.TotalTokens)
+	return totals.TotalTokens, nil
+}
+
+// surveyBudget returns the token budget set on a survey, nil if there is none
+func (s *BudgetService) surveyBudget(ctx context.Context, surveyID bson.ObjectID) (*models.TokenBudget, error) {
+	if budget, ok := cachedBudget(s, s.surveys, surveyID); ok {
+		return budget, nil
+	}
+	survey, err := s.surveyRepo.GetByID(ctx, surveyID)
+	if err != nil {
+		return nil, err
+	}
+	storeBudget(s, s.surveys, surveyID, survey.TokenBudget)
+	return survey.TokenBudget, nil
+}
+
+// budgetUsageKey identifies the usage of a budget period; the zero survey ID stands
+// for the usage of all surveys
+type budgetUsageKey struct {
+	from, to time.Time
+	surveyID bson.ObjectID
+}
+
+func cachedBudget[K comparable, T any](s *BudgetService, cache map[K]cachedBudgetValue[T], key K) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := cache[key]
+	if !ok || !s.now().Before(entry.expires) {
+		var zero T
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// storeBudget caches a value for budgetCacheTTL, dropping the expired entries
+func storeBudget[K comparable, T any](s *BudgetService, cache map[K]cachedBudgetValue[T], key K, value T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for k, entry := range cache {
+		if !now.Before(entry.expires) {
+			delete(cache, k)
+		}
+	}
+	cache[key] = cachedBudgetValue[T]{value: value, expires: now.Add(budgetCacheTTL)}
+}
+
+func (s *BudgetService) Allow(ctx context.Context, surveyID *bson.ObjectID) error {
+	budgets, err := s.GetBudget(ctx, surveyID)
+	if err != nil {
+		// Fail open like the circuit breaker: a lookup failure must not stop LLM requests.
+		log.Printf("token budget check failed: %v", err)
+		return nil
+	}
+	for _, budget := range budgets {
+		if budget.Remaining == 0 {
+			return &LLMError{
+				Kind:    LLMErrorBudgetExceeded,
+				Message: fmt.Sprintf("%s %s token budget of %d tokens is exhausted", strings.ToLower(string(budget.Scope)), strings.ToLower(string(budget.Period)), budget.Limit),
+			}
+		}
+	}
+	return nil
+}
+
+// remainingTokens returns the smallest remaining budget, or -1 when nothing is limited
+func remainingTokens(budgets []models.BudgetUsage) int {
+	remaining := -1
+	for _, budget := range budgets {
+		if remaining < 0 || budget.Remaining < remaining {
+			remaining = budget.Remaining
+		}
+	}
+	return remaining
+}
+
+// budgetPeriod returns the UTC day or calendar month containing now
+func budgetPeriod(period models.BudgetPeriod, now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	if period == models.BudgetPeriodMonthly {
+		from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(0, 1, 0)
+	}
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, 0, 1)
+}
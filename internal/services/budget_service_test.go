@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"osp/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockBudgetService struct {
+	mock.Mock
+}
+
+func (m *MockBudgetService) Allow(ctx context.Context, surveyID *bson.ObjectID) error {
+	args := m.Called(ctx, surveyID)
+	return args.Error(0)
+}
+
+func (m *MockBudgetService) GetBudget(ctx context.Context, surveyID *bson.ObjectID) ([]models.BudgetUsage, error) {
+	args := m.Called(ctx, surveyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.BudgetUsage), args.Error(1)
+}
+
+func TestBudgetService_GetBudget(t *testing.T) {
+	mockLogRepo := new(MockChatCompletionLogRepository)
+	mockSurveyRepo := new(MockSurveyRepository)
+	service := NewBudgetService(mockLogRepo, mockSurveyRepo, models.TokenBudget{Monthly: 100000})
+	service.now = func() time.Time { return time.Date(2026, 3, 15, 10, 30, 0, 0, time.UTC) }
+
+	surveyID := bson.NewObjectID()
+	mockSurveyRepo.On("GetByID", mock.Anything, surveyID).Return(&models.Survey{ID: surveyID, TokenBudget: &models.TokenBudget{Daily: 5000}}, nil)
+	mockLogRepo.On("UsageTotals", mock.Anything, models.UsageFilter{
+		From: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	}).Return(&models.UsageTotals{TotalTokens: 40000}, nil)
+	mockLogRepo.On("UsageTotals", mock.Anything, models.UsageFilter{
+		From:     time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC),
+		SurveyID: &surveyID,
+	}).Return(&models.UsageTotals{TotalTokens: 6000}, nil)
+
+	budgets, err := service.GetBudget(context.Background(), &surveyID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BudgetUsage{
+		{Scope: models.BudgetScopeGlobal, Period: models.BudgetPeriodMonthly, Limit: 100000, Used: 40000, Remaining: 60000},
+		{Scope: models.BudgetScopeSurvey, Period: models.BudgetPeriodDaily, Limit: 5000, Used: 6000, Remaining: 0},
+	}, budgets)
+	assert.Equal(t, 0, remainingTokens(budgets))
+	assert.Equal(t, -1, remainingTokens(nil))
+	mockLogRepo.AssertExpectations(t)
+}
+
+func TestBudgetService_Allow(t *testing.T) {
+	t.Run("Exhausted", func(t *testing.T) {
+		mockLogRepo := new(MockChatCompletionLogRepository)
+		service := NewBudgetService(mockLogRepo, new(MockSurveyRepository), models.TokenBudget{Daily: 1000})
+		mockLogRepo.On("UsageTotals", mock.Anything, mock.Anything).Return(&models.UsageTotals{TotalTokens: 1000}, nil)
+
+		err := service.Allow(context.Background(), nil)
+
+		var llmErr *LLMError
+		assert.ErrorAs(t, err, &llmErr)
+		assert.Equal(t, LLMErrorBudgetExceeded, llmErr.Kind)
+		assert.True(t, IsPermanentError(err))
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		mockLogRepo := new(MockChatCompletionLogRepository)
+		service := NewBudgetService(mockLogRepo, new(MockSurveyRepository), models.TokenBudget{})
+
+		assert.NoError(t, service.Allow(context.Background(), nil))
+		mockLogRepo.AssertNotCalled(t, "UsageTotals", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CachesUsage", func(t *testing.T) {
+		mockLogRepo := new(MockChatCompletionLogRepository)
+		mockSurveyRepo := new(MockSurveyRepository)
+		service := NewBudgetService(mockLogRepo, mockSurveyRepo, models.TokenBudget{Daily: 1000})
+		now := time.Date(2026, 3, 15, 10, 30, 0, 0, time.UTC)
+		service.now = func() time.Time { return now }
+		surveyID := bson.NewObjectID()
+		mockSurveyRepo.On("GetByID", mock.Anything, surveyID).Return(&models.Survey{ID: surveyID, TokenBudget: &models.TokenBudget{Monthly: 5000}}, nil)
+		mockLogRepo.On("UsageTotals", mock.Anything, mock.Anything).Return(&models.UsageTotals{TotalTokens: 100}, nil)
+
+		assert.NoError(t, service.Allow(context.Background(), &surveyID))
+		assert.NoError(t, service.Allow(context.Background(), &surveyID))
+		// The global usage is shared by requests of other surveys.
+		assert.NoError(t, service.Allow(context.Background(), nil))
+		mockSurveyRepo.AssertNumberOfCalls(t, "GetByID", 1)
+		mockLogRepo.AssertNumberOfCalls(t, "UsageTotals", 2)
+
+		now = now.Add(budgetCacheTTL)
+		assert.NoError(t, service.Allow(context.Background(), &surveyID))
+		mockSurveyRepo.AssertNumberOfCalls(t, "GetByID", 2)
+		mockLogRepo.AssertNumberOfCalls(t, "UsageTotals", 4)
+	})
+
+	t.Run("FailsOpen", func(t *testing.T) {
+		mockLogRepo := new(MockChatCompletionLogRepository)
+		service := NewBudgetService(mockLogRepo, new(MockSurveyRepository), models.TokenBudget{Daily: 1000})
+		mockLogRepo.On("UsageTotals", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		assert.NoError(t, service.Allow(context.Background(), nil))
+	})
+}
+
+func TestChatCompletionService_BudgetExceeded(t *testing.T) {
+	budget := new(MockBudgetService)
+	breaker := new(MockCircuitBreaker)
+	logRepo := new(MockChatCompletionLogRepository)
+	service := NewChatCompletionService(logRepo, breaker, budget, nil, nil)
+	surveyID := bson.NewObjectID()
+	budget.On("Allow", mock.Anything, &surveyID).Return(&LLMError{Kind: LLMErrorBudgetExceeded, Message: "token budget exhausted"})
+	// The rejected request is logged as failed.
+	logRepo.On("Create", mock.Anything, mock.MatchedBy(func(entry *models.ChatCompletionRequestLog) bool {
+		return entry.Status == models.ChatCompletionFailed && strings.Contains(entry.Error, "token budget exhausted") && *entry.SurveyID == surveyID
+	})).Return(nil)
+
+	_, err := service.NewRequest(context.Background(), models.ChatCompletionRequest{}, &models.ChatCompletionOptions{SurveyID: &surveyID})
+
+	assert.True(t, IsPermanentError(err))
+	breaker.AssertNotCalled(t, "Allow", mock.Anything)
+	logRepo.AssertExpectations(t)
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"osp/internal/models"
+	"osp/internal/repositories"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ErrLogNotReplayable is returned when replaying the log of an embeddings request
+var ErrLogNotReplayable = errors.New("only chat completion requests can be replayed")
+
+type IChatCompletionLogService interface {
+	GetLogs(ctx context.Context, filter models.ChatCompletionLogFilter, offset, limit int64) ([]*models.ChatCompletionRequestLog, int64, error)
+	GetLog(ctx context.Context, id bson.ObjectID) (*models.ChatCompletionRequestLog, error)
+	ReplayLog(ctx context.Context, id bson.ObjectID, req *models.ReplayChatCompletionRequest) (*models.ChatCompletionReplay, error)
+}
+
+type ChatCompletionLogService struct {
+	logRepo               repositories.ChatCompletionLogRepository
+	chatCompletionService IChatCompletionService
+}
+
+func NewChatCompletionLogService(logRepo repositories.ChatCompletionLogRepository, chatCompletionService IChatCompletionService) *ChatCompletionLogService {
+	return &ChatCompletionLogService{
+		logRepo:               logRepo,
+		chatCompletionService: chatCompletionService,
+	}
+}
+
+func (s *ChatCompletionLogService) GetLogs(ctx context.Context, filter models.ChatCompletionLogFilter, offset, limit int64) ([]*models.ChatCompletionRequestLog, int64, error) {
+	return s.logRepo.List(ctx, filter, offset, limit)
+}
+
+func (s *ChatCompletionLogService) GetLog(ctx context.Context, id bson.ObjectID) (*models.ChatCompletionRequestLog, error) {
+	return s.logRepo.GetByID(ctx, id)
+}
+
+// ReplayLog re-sends a logged request with the given overrides. The replay bypasses the
+// response cache and is logged as a new request referencing the original one; it counts
+// towards the survey's budget but not towards the usage of the insight.
+func (s *ChatCompletionLogService) ReplayLog(ctx context.Context, id bson.ObjectID, req *models.ReplayChatCompletionRequest) (*models.ChatCompletionReplay, error) {
+	entry, err := s.logRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(entry.Input) > 0 {
+		return nil, ErrLogNotReplayable
+	}
+
+	request := entry.Request
+	messages := entry.Request.Messages
+	if len(req.Messages) > 0 {
+		messages = req.Messages
+	}
+	request.Messages = append([]models.ChatCompletionMessage(nil), messages...)
+	if req.SystemPrompt != nil {
+		request.Messages = withSystemPrompt(request.Messages, *req.SystemPrompt)
+	}
+	if req.Model != nil && *req.Model != "" {
+		request.Model = *req.Model
+	}
+
+	content, err := s.chatCompletionService.NewRequest(ctx, request, &models.ChatCompletionOptions{
+		Reference:   "replay:" + id.Hex(),
+		SurveyID:    entry.SurveyID,
+		BypassCache: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	replay := &models.ChatCompletionReplay{
+		SourceID: id,
+		Request:  request,
+	}
+	if content != nil {
+		replay.Content = *content
+	}
+	return replay, nil
+}
+
+// withSystemPrompt replaces the system message, or prepends one if there is none
+func withSystemPrompt(messages []models.ChatCompletionMessage, prompt string) []models.ChatCompletionMessage {
+	for i, message := range messages {
+		if message.Role == "system" {
+			messages[i].Content = prompt
+			return messages
+		}
+	}
+	return append([]models.ChatCompletionMessage{{Role: "system", Content: prompt}}, messages...)
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"osp/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestChatCompletionLogService_ReplayLog(t *testing.T) {
+	surveyID := bson.NewObjectID()
+	insightID := bson.NewObjectID()
+	entry := &models.ChatCompletionRequestLog{
+		ID:        bson.NewObjectID(),
+		InsightID: &insightID,
+		SurveyID:  &surveyID,
+		Request: models.ChatCompletionRequest{
+			Model: insightModel,
+			Messages: []models.ChatCompletionMessage{
+				{Role: "system", Content: "Summarize."},
+				{Role: "user", Content: "Answers: [Great]"},
+			},
+		},
+	}
+
+	t.Run("WithOverrides", func(t *testing.T) {
+		mockLogRepo := new(MockChatCompletionLogRepository)
+		mockChat := new(MockChatCompletionService)
+		service := NewChatCompletionLogService(mockLogRepo, mockChat)
+		mockLogRepo.On("GetByID", mock.Anything, entry.ID).Return(entry, nil)
+
+		content := "Replayed"
+		mockChat.On("NewRequest", mock.Anything, mock.MatchedBy(func(req models.ChatCompletionRequest) bool {
+			return req.Model == "openai/gpt-4o" &&
+				req.Messages[0].Content == "Summarize in one sentence." &&
+				req.Messages[1].Content == "Answers: [Great]"
+		}), mock.MatchedBy(func(opts *models.ChatCompletionOptions) bool {
+			return opts.Reference == "replay:"+entry.ID.Hex() && opts.BypassCache && *opts.SurveyID == surveyID && opts.InsightID == nil
+		})).Return(&content, nil)
+
+		model := "openai/gpt-4o"
+		prompt := "Summarize in one sentence."
+		replay, err := service.ReplayLog(context.Background(), entry.ID, &models.ReplayChatCompletionRequest{Model: &model, SystemPrompt: &prompt})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Replayed", replay.Content)
+		assert.Equal(t, entry.ID, replay.SourceID)
+		// The logged request is left untouched.
+		assert.Equal(t, "Summarize.", entry.Request.Messages[0].Content)
+		mockChat.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockLogRepo := new(MockChatCompletionLogRepository)
+		mockChat := new(MockChatCompletionService)
+		service := NewChatCompletionLogService(mockLogRepo, mockChat)
+		mockLogRepo.On("GetByID", mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments)
+
+		_, err := service.ReplayLog(context.Background(), bson.NewObjectID(), &models.ReplayChatCompletionRequest{})
+
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+		mockChat.AssertNotCalled(t, "NewRequest", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Embeddings", func(t *testing.T) {
+		mockLogRepo := new(MockChatCompletionLogRepository)
+		mockChat := new(MockChatCompletionService)
+		service := NewChatCompletionLogService(mockLogRepo, mockChat)
+		embeddings := &models.ChatCompletionRequestLog{
+			ID:      bson.NewObjectID(),
+			Request: models.ChatCompletionRequest{Model: "openai/text-embedding-3-small"},
+			Input:   []string{"onboarding"},
+		}
+		mockLogRepo.On("GetByID", mock.Anything, embeddings.ID).Return(embeddings, nil)
+
+		_, err := service.ReplayLog(context.Background(), embeddings.ID, &models.ReplayChatCompletionRequest{})
+
+		assert.ErrorIs(t, err, ErrLogNotReplayable)
+		mockChat.AssertNotCalled(t, "NewRequest", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestWithSystemPrompt(t *testing.T) {
+	messages := withSystemPrompt([]models.ChatCompletionMessage{{Role: "user", Content: "Hi"}}, "Be brief.")
+
+	assert.Equal(t, []models.ChatCompletionMessage{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "Hi"},
+	}, messages)
+}
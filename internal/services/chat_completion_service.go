@@ -4,64 +4,222 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math/rand/v2"
 	"net/http"
 	"os"
 	"time"
 
 	"osp/internal/models"
+	"osp/internal/repositories"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 const githubModelsChatCompletionsURL = "https://models.github.ai/inference/chat/completions"
 
 // IChatCompletionService abstracts the external chat completion API
 type IChatCompletionService interface {
-	NewRequest(reqBody models.ChatCompletionRequest, reference *string) (*string, error)
+	NewRequest(ctx context.Context, reqBody models.ChatCompletionRequest, opts *models.ChatCompletionOptions) (*string, error)
 }
 
 type ChatCompletionService struct {
-	collection *mongo.Collection
+	logRepo repositories.ChatCompletionLogRepository
+	breaker CircuitBreaker
+	budget  BudgetGuard
+	cache   ResponseCache
+	prices  map[string]models.ModelPrice
+	retry   retryPolicy
+	url     string
 }
 
-func NewChatCompletionService(collection *mongo.Collection) *ChatCompletionService {
+func NewChatCompletionService(logRepo repositories.ChatCompletionLogRepository, breaker CircuitBreaker, budget BudgetGuard, cache ResponseCache, prices map[string]models.ModelPrice) *ChatCompletionService {
 	return &ChatCompletionService{
-		collection: collection,
+		logRepo: logRepo,
+		breaker: breaker,
+		budget:  budget,
+		cache:   cache,
+		prices:  prices,
+		retry:   defaultRetryPolicy,
+		url:     githubModelsChatCompletionsURL,
 	}
 }
 
-func (s *ChatCompletionService) NewRequest(reqBody models.ChatCompletionRequest, reference *string) (*string, error) {
-	ctx := context.Background()
+func (s *ChatCompletionService) NewRequest(ctx context.Context, reqBody models.ChatCompletionRequest, opts *models.ChatCompletionOptions) (*string, error) {
+	if opts == nil {
+		opts = &models.ChatCompletionOptions{}
+	}
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
 
-	// Insert request log first (best-effort) so every attempted request is tracked.
 	logEntry := models.ChatCompletionRequestLog{
 		ID:        bson.NewObjectID(),
 		Request:   reqBody,
 		Response:  nil,
-		Reference: reference,
+		Reference: &opts.Reference,
+		InsightID: opts.InsightID,
+		SurveyID:  opts.SurveyID,
+		Model:     reqBody.Model,
+		Status:    models.ChatCompletionPending,
 		CreatedAt: time.Now(),
 	}
-	if _, err := s.collection.InsertOne(ctx, logEntry); err != nil {
-		log.Printf("chat completion log insert failed: %v", err)
+
+	// Serve identical requests from the cache; hits are logged without usage or cost,
+	// as nothing was sent to the provider.
+	cacheKey := responseCacheKey(jsonData)
+	var cacheOutcome CacheOutcome
+	switch {
+	case s.cache == nil:
+	case opts.BypassCache:
+		cacheOutcome = CacheBypass
+	default:
+		if cached, ok := s.cache.Get(ctx, cacheKey); ok {
+			if c, err := firstChoiceContent(cached); err == nil {
+				s.cache.Count(ctx, CacheHit)
+				logEntry.Response = cached
+				logEntry.Cached = true
+				logEntry.Status = models.ChatCompletionSucceeded
+				s.createLog(ctx, &logEntry)
+				return &c, nil
+			}
+		}
+		cacheOutcome = CacheMiss
 	}
 
-	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		return nil, fmt.Errorf("GITHUB_TOKEN is not set")
+	if s.budget != nil {
+		if err := s.budget.Allow(ctx, opts.SurveyID); err != nil {
+			// Rejected requests are logged as failed, without attempts.
+			logEntry.Status = models.ChatCompletionFailed
+			logEntry.Error = err.Error()
+			s.createLog(ctx, &logEntry)
+			return nil, err
+		}
+	}
+	// Misses and bypasses are counted once the request is sure to reach the provider.
+	if cacheOutcome != "" {
+		s.cache.Count(ctx, cacheOutcome)
 	}
 
-	jsonData, err := json.Marshal(reqBody)
+	// Insert request log first (best-effort) so every attempted request is tracked.
+	s.createLog(ctx, &logEntry)
+
+	start := time.Now()
+	chatCompletionResponse, attempts, err := s.complete(ctx, jsonData)
+	var content string
+	if err == nil {
+		content, err = firstChoiceContent(chatCompletionResponse)
+	}
+	s.finishLog(ctx, logEntry.ID, reqBody.Model, chatCompletionResponse, attempts, time.Since(start), err)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, githubModelsChatCompletionsURL, bytes.NewBuffer(jsonData))
+	if s.cache != nil {
+		s.cache.Set(ctx, cacheKey, chatCompletionResponse)
+	}
+	return &content, nil
+}
+
+// attemptRecord is an attempt with the raw body of its response, kept for the log
+type attemptRecord struct {
+	models.ChatCompletionAttempt
+	body []byte
+}
+
+// complete sends the request with retries and returns every attempt made
+func (s *ChatCompletionService) complete(ctx context.Context, jsonData []byte) (*models.ChatCompletionResponse, []attemptRecord, error) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return nil, nil, fmt.Errorf("GITHUB_TOKEN is not set")
+	}
+
+	var chatCompletionResponse *models.ChatCompletionResponse
+	var attempts []attemptRecord
+	err := s.retry.do(ctx, func(ctx context.Context) error {
+		if s.breaker != nil {
+			if err := s.breaker.Allow(ctx); err != nil {
+				return err
+			}
+		}
+		start := time.Now()
+		resp, statusCode, body, err := s.send(ctx, token, jsonData)
+		attempt := attemptRecord{
+			ChatCompletionAttempt: models.ChatCompletionAttempt{
+				Number:     len(attempts) + 1,
+				HTTPStatus: statusCode,
+				DurationMs: time.Since(start).Milliseconds(),
+			},
+		}
+		if err != nil {
+			attempt.Error = err.Error()
+			attempt.body = body
+		}
+		attempts = append(attempts, attempt)
+		s.recordOutcome(ctx, err)
+		chatCompletionResponse = resp
+		return err
+	})
+	return chatCompletionResponse, attempts, err
+}
+
+func (s *ChatCompletionService) createLog(ctx context.Context, entry *models.ChatCompletionRequestLog) {
+	if err := s.logRepo.Create(ctx, entry); err != nil {
+		log.Printf("chat completion log insert failed: %v", err)
+	}
+}
+
+// finishLog moves the request log to its terminal state
+func (s *ChatCompletionService) finishLog(ctx context.Context, id bson.ObjectID, model string, resp *models.ChatCompletionResponse, attempts []attemptRecord, duration time.Duration, err error) {
+	loggedAttempts := make([]models.ChatCompletionAttempt, len(attempts))
+	for i, attempt := range attempts {
+		loggedAttempts[i] = attempt.ChatCompletionAttempt
+	}
+	set := bson.M{
+		"attempts":   loggedAttempts,
+		"latency_ms": duration.Milliseconds(),
+	}
+	if resp != nil {
+		// The provider answered, so its usage is billed even if the answer was unusable.
+		set["response"] = resp
+		set["usage"] = resp.Usage
+		set["cost"] = requestCost(s.prices, model, resp.Usage)
+	}
+	if err == nil {
+		set["status"] = models.ChatCompletionSucceeded
+	} else {
+		set["status"] = models.ChatCompletionFailed
+		set["error"] = err.Error()
+		if len(attempts) > 0 {
+			last := attempts[len(attempts)-1]
+			if last.HTTPStatus != 0 {
+				set["http_status"] = last.HTTPStatus
+			}
+			if len(last.body) > 0 {
+				set["raw_body"] = truncate(string(last.body), maxLoggedBodyLength)
+			}
+		}
+	}
+
+	// The log is written even if the caller's context was cancelled.
+	if err := s.logRepo.Update(context.WithoutCancel(ctx), id, bson.M{"$set": set}); err != nil {
+		log.Printf("chat completion log %s update failed: %v", id.Hex(), err)
+	}
+}
+
+// maxLoggedBodyLength caps the provider response kept on a failed request log
+const maxLoggedBodyLength = 4000
+
+// send performs a single request; failures are returned as LLMError. The status code
+// and body of the response are returned whenever one was received.
+func (s *ChatCompletionService) send(ctx context.Context, token string, jsonData []byte) (*models.ChatCompletionResponse, int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, err
+		return nil, 0, nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -70,32 +228,44 @@ func (s *ChatCompletionService) NewRequest(reqBody models.ChatCompletionRequest,
 	client := &http.Client{Timeout: 60 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, 0, nil, classifyTransportError(ctx, err)
 	}
 	defer resp.Body.Close()
 
 	body, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("github models request failed: status=%d body=%s", resp.StatusCode, string(body))
+		return nil, resp.StatusCode, body, classifyResponse(resp.StatusCode, resp.Header, body)
 	}
 
 	var chatCompletionResponse models.ChatCompletionResponse
 	if err := json.Unmarshal(body, &chatCompletionResponse); err != nil {
-		return nil, err
+		return nil, resp.StatusCode, body, fmt.Errorf("invalid chat completion response: %w", err)
 	}
+	return &chatCompletionResponse, resp.StatusCode, nil, nil
+}
 
-	// Best-effort: attach the response to the request log.
-	_, _ = s.collection.UpdateByID(ctx, logEntry.ID, bson.M{
-		"$set": bson.M{
-			"response": chatCompletionResponse,
-		},
-	})
+// recordOutcome feeds the circuit breaker; only provider outages count as failures,
+// not requests the provider rejected.
+func (s *ChatCompletionService) recordOutcome(ctx context.Context, err error) {
+	if s.breaker == nil {
+		return
+	}
+	var llmErr *LLMError
+	switch {
+	case err == nil:
+		s.breaker.RecordSuccess(ctx)
+	case errors.As(err, &llmErr) && llmErr.Retryable() && llmErr.Kind != LLMErrorCircuitOpen:
+		s.breaker.RecordFailure(ctx)
+	}
+}
 
-	c, err := firstChoiceContent(&chatCompletionResponse)
-	if err != nil {
-		return nil, err
+// requestCost prices the usage of a request; models without a price cost nothing
+func requestCost(prices map[string]models.ModelPrice, model string, usage *models.ChatCompletionUsage) float64 {
+	price, ok := prices[model]
+	if !ok || usage == nil {
+		return 0
 	}
-	return &c, nil
+	return (float64(usage.PromptTokens)*price.InputPerMillion + float64(usage.CompletionTokens)*price.OutputPerMillion) / 1e6
 }
 
 func firstChoiceContent(resp *models.ChatCompletionResponse) (string, error) {
@@ -104,3 +274,62 @@ func firstChoiceContent(resp *models.ChatCompletionResponse) (string, error) {
 	}
 	return resp.Choices[0].Message.Content, nil
 }
+
+// retryPolicy retries retryable LLM errors with jittered exponential backoff. Delays
+// longer than MaxDelay (e.g. a long Retry-After) are left to the job queue instead of
+// blocking the worker.
+type retryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	sleep       func(ctx context.Context, d time.Duration) error
+}
+
+var defaultRetryPolicy = retryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+	sleep:       sleepContext,
+}
+
+func (p retryPolicy) do(ctx context.Context, call func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := call(ctx)
+		if err == nil {
+			return nil
+		}
+		var llmErr *LLMError
+		if !errors.As(err, &llmErr) || !llmErr.Retryable() || attempt >= p.MaxAttempts {
+			return err
+		}
+		delay := p.delay(attempt, llmErr.RetryAfter)
+		if delay > p.MaxDelay {
+			return err
+		}
+		log.Printf("llm request attempt %d failed, retrying in %s: %v", attempt, delay, err)
+		if err := p.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// delay honors a requested Retry-After, otherwise doubles BaseDelay per attempt and
+// picks a random delay in the upper half so concurrent workers spread out.
+func (p retryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	backoff := min(p.BaseDelay<<(attempt-1), p.MaxDelay)
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"osp/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockCircuitBreaker struct {
+	mock.Mock
+}
+
+func (m *MockCircuitBreaker) Allow(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockCircuitBreaker) RecordSuccess(ctx context.Context) {
+	m.Called(ctx)
+}
+
+func (m *MockCircuitBreaker) RecordFailure(ctx context.Context) {
+	m.Called(ctx)
+}
+
+func TestClassifyResponse(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", "12")
+	rateLimited := classifyResponse(http.StatusTooManyRequests, header, []byte("slow down"))
+	assert.Equal(t, LLMErrorRateLimited, rateLimited.Kind)
+	assert.Equal(t, 12*time.Second, rateLimited.RetryAfter)
+	assert.True(t, rateLimited.Retryable())
+
+	assert.Equal(t, LLMErrorServer, classifyResponse(http.StatusBadGateway, http.Header{}, nil).Kind)
+	assert.Equal(t, LLMErrorTimeout, classifyResponse(http.StatusGatewayTimeout, http.Header{}, nil).Kind)
+	assert.Equal(t, LLMErrorUnauthorized, classifyResponse(http.StatusUnauthorized, http.Header{}, nil).Kind)
+
+	contentFiltered := classifyResponse(http.StatusBadRequest, http.Header{}, []byte(`{"error":{"code":"content_filter"}}`))
+	assert.Equal(t, LLMErrorInvalidRequest, contentFiltered.Kind)
+	assert.False(t, contentFiltered.Retryable())
+	assert.Contains(t, contentFiltered.Error(), "content_filter")
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter("Wed, 01 Jan 2025 00:01:30 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+}
+
+func TestClassifyTransportError(t *testing.T) {
+	err := classifyTransportError(context.Background(), fmt.Errorf("post: %w", context.DeadlineExceeded))
+	var llmErr *LLMError
+	assert.ErrorAs(t, err, &llmErr)
+	assert.Equal(t, LLMErrorTimeout, llmErr.Kind)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, classifyTransportError(ctx, errors.New("post: context canceled")))
+}
+
+func TestIsPermanentError(t *testing.T) {
+	permanent := &LLMError{Kind: LLMErrorInvalidRequest}
+	retryable := &LLMError{Kind: LLMErrorRateLimited}
+
+	assert.True(t, IsPermanentError(permanent))
+	assert.True(t, IsPermanentError(fmt.Errorf("all batches failed: %w", errors.Join(permanent, permanent))))
+	assert.False(t, IsPermanentError(fmt.Errorf("all batches failed: %w", errors.Join(permanent, retryable))))
+	assert.False(t, IsPermanentError(errors.New("db error")))
+	assert.False(t, IsPermanentError(retryable))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	newPolicy := func(sleeps *[]time.Duration) retryPolicy {
+		return retryPolicy{
+			MaxAttempts: 4,
+			BaseDelay:   time.Second,
+			MaxDelay:    30 * time.Second,
+			sleep: func(ctx context.Context, d time.Duration) error {
+				*sleeps = append(*sleeps, d)
+				return nil
+			},
+		}
+	}
+
+	t.Run("RetriesWithBackoff", func(t *testing.T) {
+		var sleeps []time.Duration
+		calls := 0
+		err := newPolicy(&sleeps).do(context.Background(), func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return &LLMError{Kind: LLMErrorServer}
+			}
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Len(t, sleeps, 2)
+		assert.GreaterOrEqual(t, sleeps[0], 500*time.Millisecond)
+		assert.LessOrEqual(t, sleeps[0], time.Second)
+		assert.GreaterOrEqual(t, sleeps[1], time.Second)
+		assert.LessOrEqual(t, sleeps[1], 2*time.Second)
+	})
+
+	t.Run("HonorsRetryAfter", func(t *testing.T) {
+		var sleeps []time.Duration
+		calls := 0
+		err := newPolicy(&sleeps).do(context.Background(), func(ctx context.Context) error {
+			calls++
+			if calls == 1 {
+				return &LLMError{Kind: LLMErrorRateLimited, RetryAfter: 7 * time.Second}
+			}
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []time.Duration{7 * time.Second}, sleeps)
+	})
+
+	t.Run("LeavesLongDelaysToTheQueue", func(t *testing.T) {
+		var sleeps []time.Duration
+		err := newPolicy(&sleeps).do(context.Background(), func(ctx context.Context) error {
+			return &LLMError{Kind: LLMErrorRateLimited, RetryAfter: time.Hour}
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, sleeps)
+	})
+
+	t.Run("DoesNotRetryPermanentErrors", func(t *testing.T) {
+		var sleeps []time.Duration
+		calls := 0
+		err := newPolicy(&sleeps).do(context.Background(), func(ctx context.Context) error {
+			calls++
+			return &LLMError{Kind: LLMErrorInvalidRequest}
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("GivesUpAfterMaxAttempts", func(t *testing.T) {
+		var sleeps []time.Duration
+		calls := 0
+		err := newPolicy(&sleeps).do(context.Background(), func(ctx context.Context) error {
+			calls++
+			return &LLMError{Kind: LLMErrorTimeout}
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, 4, calls)
+	})
+}
+
+func TestChatCompletionService_RecordOutcome(t *testing.T) {
+	breaker := new(MockCircuitBreaker)
+	service := NewChatCompletionService(nil, breaker, nil, nil, nil)
+	breaker.On("RecordSuccess", mock.Anything).Once()
+	breaker.On("RecordFailure", mock.Anything).Once()
+
+	service.recordOutcome(context.Background(), nil)
+	service.recordOutcome(context.Background(), &LLMError{Kind: LLMErrorServer})
+	service.recordOutcome(context.Background(), &LLMError{Kind: LLMErrorInvalidRequest})
+	service.recordOutcome(context.Background(), &LLMError{Kind: LLMErrorCircuitOpen})
+
+	breaker.AssertExpectations(t)
+}
+
+func TestRequestCost(t *testing.T) {
+	prices := map[string]models.ModelPrice{
+		"openai/gpt-4o-mini": {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+	}
+	usage := &models.ChatCompletionUsage{PromptTokens: 2000, CompletionTokens: 500, TotalTokens: 2500}
+
+	assert.InDelta(t, 0.0006, requestCost(prices, "openai/gpt-4o-mini", usage), 1e-12)
+	assert.Zero(t, requestCost(prices, "unknown/model", usage))
+	assert.Zero(t, requestCost(prices, "openai/gpt-4o-mini", nil))
+}
+
+func TestChatCompletionService_NewRequest_Logs(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "token")
+	noSleep := retryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Second, sleep: func(ctx context.Context, d time.Duration) error { return nil }}
+	newService := func(handler http.HandlerFunc) (*ChatCompletionService, *MockChatCompletionLogRepository) {
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+		mockLogRepo := new(MockChatCompletionLogRepository)
+		service := NewChatCompletionService(mockLogRepo, nil, nil, nil, map[string]models.ModelPrice{
+			insightModel: {InputPerMillion: 1, OutputPerMillion: 2},
+		})
+		service.url = server.URL
+		service.retry = noSleep
+		mockLogRepo.On("Create", mock.Anything, mock.MatchedBy(func(entry *models.ChatCompletionRequestLog) bool {
+			return entry.Status == models.ChatCompletionPending
+		})).Return(nil)
+		return service, mockLogRepo
+	}
+	request := models.ChatCompletionRequest{Model: insightModel, Messages: []models.ChatCompletionMessage{{Role: "user", Content: "Hi"}}}
+
+	t.Run("Succeeded", func(t *testing.T) {
+		service, mockLogRepo := newService(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Hello"}}],"usage":{"prompt_tokens":1000,"completion_tokens":500,"total_tokens":1500}}`)
+		})
+		mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(update bson.M) bool {
+			set := update["$set"].(bson.M)
+			attempts := set["attempts"].([]models.ChatCompletionAttempt)
+			return set["status"] == models.ChatCompletionSucceeded && set["cost"] == 0.002 &&
+				len(attempts) == 1 && attempts[0].HTTPStatus == http.StatusOK
+		})).Return(nil)
+
+		content, err := service.NewRequest(context.Background(), request, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello", *content)
+		mockLogRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		service, mockLogRepo := newService(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, `upstream unavailable`)
+		})
+		mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(update bson.M) bool {
+			set := update["$set"].(bson.M)
+			attempts := set["attempts"].([]models.ChatCompletionAttempt)
+			return set["status"] == models.ChatCompletionFailed && set["http_status"] == http.StatusBadGateway &&
+				set["raw_body"] == "upstream unavailable" && len(attempts) == 2 && attempts[1].Number == 2 &&
+				set["response"] == nil
+		})).Return(nil)
+
+		_, err := service.NewRequest(context.Background(), request, nil)
+
+		var llmErr *LLMError
+		assert.ErrorAs(t, err, &llmErr)
+		mockLogRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidResponse", func(t *testing.T) {
+		service, mockLogRepo := newService(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `<html>`)
+		})
+		mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(update bson.M) bool {
+			set := update["$set"].(bson.M)
+			return set["status"] == models.ChatCompletionFailed && set["raw_body"] == "<html>"
+		})).Return(nil)
+
+		_, err := service.NewRequest(context.Background(), request, nil)
+
+		assert.Error(t, err)
+		mockLogRepo.AssertExpectations(t)
+	})
+
+	t.Run("MissingToken", func(t *testing.T) {
+		t.Setenv("GITHUB_TOKEN", "")
+		service, mockLogRepo := newService(func(w http.ResponseWriter, r *http.Request) {})
+		mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(update bson.M) bool {
+			set := update["$set"].(bson.M)
+			return set["status"] == models.ChatCompletionFailed && set["error"] == "GITHUB_TOKEN is not set"
+		})).Return(nil)
+
+		_, err := service.NewRequest(context.Background(), request, nil)
+
+		assert.Error(t, err)
+		mockLogRepo.AssertExpectations(t)
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "abc...", truncate("abcdef", 3))
+	// "é" is two bytes; a cut through it is dropped rather than left invalid.
+	assert.Equal(t, "a...", truncate("aé", 2))
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// CircuitBreaker pauses LLM requests after repeated provider failures
+type CircuitBreaker interface {
+	// Allow returns an LLMError of kind LLMErrorCircuitOpen while requests are paused
+	Allow(ctx context.Context) error
+	RecordSuccess(ctx context.Context)
+	RecordFailure(ctx context.Context)
+}
+
+const (
+	circuitFailuresKey = "llm-circuit:failures"
+	circuitOpenKey     = "llm-circuit:open"
+)
+
+// RedisCircuitBreaker shares its state through Redis, so every worker stops calling
+// the provider once it is failing. The circuit opens after Threshold failures within
+// Window and closes again after Cooldown.
+type RedisCircuitBreaker struct {
+	client    redis.UniversalClient
+	Threshold int64
+	Window    time.Duration
+	Cooldown  time.Duration
+}
+
+func NewRedisCircuitBreaker(client redis.UniversalClient) *RedisCircuitBreaker {
+	return &RedisCircuitBreaker{
+		client:    client,
+		Threshold: 5,
+		Window:    time.Minute,
+		Cooldown:  30 * time.Second,
+	}
+}
+
+func (b *RedisCircuitBreaker) Allow(ctx context.Context) error {
+	ttl, err := b.client.PTTL(ctx, circuitOpenKey).Result()
+	if err != nil {
+		// Fail open: Redis problems must not stop LLM requests.
+		log.Printf("circuit breaker check failed: %v", err)
+		return nil
+	}
+	if ttl > 0 {
+		return &LLMError{Kind: LLMErrorCircuitOpen, RetryAfter: ttl, Message: "too many recent provider failures"}
+	}
+	return nil
+}
+
+func (b *RedisCircuitBreaker) RecordSuccess(ctx context.Context) {
+	if err := b.client.Del(ctx, circuitFailuresKey).Err(); err != nil {
+		log.Printf("circuit breaker reset failed: %v", err)
+	}
+}
+
+func (b *RedisCircuitBreaker) RecordFailure(ctx context.Context) {
+	failures, err := b.client.Incr(ctx, circuitFailuresKey).Result()
+	if err != nil {
+		log.Printf("circuit breaker update failed: %v", err)
+		return
+	}
+	if failures == 1 {
+		b.client.Expire(ctx, circuitFailuresKey, b.Window)
+	}
+	if failures >= b.Threshold {
+		log.Printf("circuit breaker opened for %s after %d failures", b.Cooldown, failures)
+		b.client.Set(ctx, circuitOpenKey, "1", b.Cooldown)
+		b.client.Del(ctx, circuitFailuresKey)
+	}
+}
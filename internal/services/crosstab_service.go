@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"osp/internal/models"
+	"osp/internal/repositories"
+	"osp/internal/stats"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ErrInvalidCrossTabulation wraps every error caused by the request rather than by storage.
+var ErrInvalidCrossTabulation = errors.New("invalid cross-tabulation")
+
+type ICrossTabService interface {
+	CrossTabulate(ctx context.Context, surveyID bson.ObjectID, req *models.CrossTabulationRequest) (*models.CrossTabulation, error)
+}
+
+type CrossTabService struct {
+	surveyRepo            repositories.SurveyRepository
+	submissionRepo        repositories.SubmissionRepository
+	chatCompletionService IChatCompletionService
+}
+
+func NewCrossTabService(
+	surveyRepo repositories.SurveyRepository,
+	submissionRepo repositories.SubmissionRepository,
+	chatCompletionService IChatCompletionService,
+) *CrossTabService {
+	return &CrossTabService{
+		surveyRepo:            surveyRepo,
+		submissionRepo:        submissionRepo,
+		chatCompletionService: chatCompletionService,
+	}
+}
+
+func (s *CrossTabService) CrossTabulate(ctx context.Context, surveyID bson.ObjectID, req *models.CrossTabulationRequest) (*models.CrossTabulation, error) {
+	survey, err := s.surveyRepo.GetByID(ctx, surveyID)
+	if err != nil {
+		return nil, err
+	}
+
+	rowQuestion, err := findCrossTabQuestion(survey, req.RowQuestionID)
+	if err != nil {
+		return nil, err
+	}
+	columnQuestion, err := findCrossTabQuestion(survey, req.ColumnQuestionID)
+	if err != nil {
+		return nil, err
+	}
+	if rowQuestion.ID == columnQuestion.ID {
+		return nil, fmt.Errorf("%w: row and column questions must differ", ErrInvalidCrossTabulation)
+	}
+
+	submissions, err := s.submissionRepo.GetAllSubmissions(ctx, surveyID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rowLabels := questionCategories(rowQuestion)
+	columnLabels := questionCategories(columnQuestion)
+	rowIndex := indexOf(rowLabels)
+	columnIndex := indexOf(columnLabels)
+
+	counts := make([][]int, len(rowLabels))
+	for i := range counts {
+		counts[i] = make([]int, len(columnLabels))
+	}
+	// Only submissions that answered both questions contribute to the table.
+	for _, submission := range submissions {
+		var rowAnswer, columnAnswer *string
+		for i := range submission.Responses {
+			switch submission.Responses[i].QuestionID {
+			case rowQuestion.ID:
+				rowAnswer = &submission.Responses[i].Answer
+			case columnQuestion.ID:
+				columnAnswer = &submission.Responses[i].Answer
+			}
+		}
+		if rowAnswer == nil || columnAnswer == nil {
+			continue
+		}
+		r, rowOk := rowIndex[*rowAnswer]
+		c, columnOk := columnIndex[*columnAnswer]
+		if !rowOk || !columnOk {
+			continue
+		}
+		counts[r][c]++
+	}
+
+	crossTab := buildCrossTabulation(surveyID, *rowQuestion, *columnQuestion, rowLabels, columnLabels, counts)
+
+	if req.Narrate {
+		contextType := models.ContextType("GENERAL")
+		if req.ContextType != nil {
+			contextType = *req.ContextType
+		}
+		crossTab.Narrative = s.narrateCrossTabulation(ctx, crossTab, contextType)
+	}
+	return crossTab, nil
+}
+
+func findCrossTabQuestion(survey *models.Survey, hexID string) (*models.Question, error) {
+	id, err := bson.ObjectIDFromHex(hexID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid question ID: %s", ErrInvalidCrossTabulation, hexID)
+	}
+	for i := range survey.Questions {
+		if survey.Questions[i].ID != id {
+			continue
+		}
+		question := &survey.Questions[i]
+		if question.Type != models.QuestionTypeMultipleChoice && question.Type != models.QuestionTypeLikert {
+			return nil, fmt.Errorf("%w: only MULTIPLE_CHOICE and LIKERT questions can be cross-tabulated", ErrInvalidCrossTabulation)
+		}
+		return question, nil
+	}
+	return nil, fmt.Errorf("%w: invalid question ID: %s", ErrInvalidCrossTabulation, hexID)
+}
+
+// questionCategories returns the possible answers of a choice or Likert question in display order.
+func questionCategories(question *models.Question) []string {
+	switch question.Type {
+	case models.QuestionTypeMultipleChoice:
+		if question.Specification.MultipleChoiceSpecification == nil {
+			return nil
+		}
+		return append([]string{}, question.Specification.Options...)
+	case models.QuestionTypeLikert:
+		if question.Specification.LikertSpecification == nil {
+			return nil
+		}
+		labels := []string{}
+		for v := question.Specification.Min; v <= question.Specification.Max; v++ {
+			labels = append(labels, strconv.Itoa(v))
+		}
+		return labels
+	}
+	return nil
+}
+
+func indexOf(labels []string) map[string]int {
+	index := make(map[string]int, len(labels))
+	for i, label := range labels {
+		index[label] = i
+	}
+	return index
+}
+
+func buildCrossTabulation(surveyID bson.ObjectID, rowQuestion, columnQuestion models.Question, rowLabels, columnLabels []string, counts [][]int) *models.CrossTabulation {
+	rowTotals := make([]int, len(rowLabels))
+	columnTotals := make([]int, len(columnLabels))
+	total := 0
+	for i, row := range counts {
+		for j, count := range row {
+			rowTotals[i] += count
+			columnTotals[j] += count
+			total += count
+		}
+	}
+
+	rowPercentages := make([][]float64, len(rowLabels))
+	columnPercentages := make([][]float64, len(rowLabels))
+	for i, row := range counts {
+		rowPercentages[i] = make([]float64, len(columnLabels))
+		columnPercentages[i] = make([]float64, len(columnLabels))
+		for j, count := range row {
+			rowPercentages[i][j] = percentage(count, rowTotals[i])
+			columnPercentages[i][j] = percentage(count, columnTotals[j])
+		}
+	}
+
+	return &models.CrossTabulation{
+		SurveyID:          surveyID,
+		RowQuestion:       rowQuestion,
+		ColumnQuestion:    columnQuestion,
+		RowLabels:         rowLabels,
+		ColumnLabels:      columnLabels,
+		Counts:            counts,
+		RowTotals:         rowTotals,
+		ColumnTotals:      columnTotals,
+		Total:             total,
+		RowPercentages:    rowPercentages,
+		ColumnPercentages: columnPercentages,
+		ChiSquare:         stats.ChiSquareIndependence(counts),
+	}
+}
+
+// percentage returns part/whole as a percentage rounded to two decimals.
+func percentage(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 100
+}
+
+func (s *CrossTabService) narrateCrossTabulation(ctx context.Context, crossTab *models.CrossTabulation, contextType models.ContextType) *models.InsightBatch {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Rows: %s\nColumns: %s\n", crossTab.RowQuestion.Text, crossTab.ColumnQuestion.Text)
+	for i, label := range crossTab.RowLabels {
+		fmt.Fprintf(&sb, "Row %q (n=%d):", label, crossTab.RowTotals[i])
+		for j, columnLabel := range crossTab.ColumnLabels {
+			fmt.Fprintf(&sb, " %q=%d (%.2f%%)", columnLabel, crossTab.Counts[i][j], crossTab.RowPercentages[i][j])
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "Chi-square test of independence: statistic=%.4f, df=%d, p-value=%.4f\n",
+		crossTab.ChiSquare.Statistic, crossTab.ChiSquare.DegreesOfFreedom, crossTab.ChiSquare.PValue)
+
+	reqBody := models.ChatCompletionRequest{
+		Messages: []models.ChatCompletionMessage{
+			{
+				Role:    "system",
+				Content: fmt.Sprintf("You are a helpful assistant. Describe the notable differences between the rows of the following cross-tabulation of survey responses in the context of %s. Mention whether the chi-square test indicates a statistically significant association.", contextType),
+			},
+			{
+				Role:    "user",
+				Content: sb.String(),
+			},
+		},
+		Temperature: 0.5,
+		TopP:        1.0,
+		MaxTokens:   800,
+		Model:       insightModel,
+	}
+
+	batch := &models.InsightBatch{
+		BatchNumber: 1,
+		Question:    crossTab.RowQuestion,
+	}
+	opts := &models.ChatCompletionOptions{
+		Reference: fmt.Sprintf("crosstab:%s rows:%s columns:%s", crossTab.SurveyID.Hex(), crossTab.RowQuestion.ID.Hex(), crossTab.ColumnQuestion.ID.Hex()),
+		SurveyID:  &crossTab.SurveyID,
+	}
+	summary, err := s.chatCompletionService.NewRequest(ctx, reqBody, opts)
+	if err != nil {
+		errMsg := err.Error()
+		batch.ErrorLog = &errMsg
+		return batch
+	}
+	batch.Summary = summary
+	return batch
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"osp/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func crossTabSurvey() (*models.Survey, bson.ObjectID, bson.ObjectID, bson.ObjectID) {
+	choiceID := bson.NewObjectID()
+	likertID := bson.NewObjectID()
+	textID := bson.NewObjectID()
+	survey := &models.Survey{
+		ID: bson.NewObjectID(),
+		Questions: []models.Question{
+			{
+				ID:   choiceID,
+				Text: "Plan",
+				Type: models.QuestionTypeMultipleChoice,
+				Specification: models.QuestionSpecification{
+					MultipleChoiceSpecification: &models.MultipleChoiceSpecification{Options: []string{"Free", "Pro"}},
+				},
+			},
+			{
+				ID:   likertID,
+				Text: "Rating",
+				Type: models.QuestionTypeLikert,
+				Specification: models.QuestionSpecification{
+					LikertSpecification: &models.LikertSpecification{Min: 1, Max: 3},
+				},
+			},
+			{
+				ID:   textID,
+				Text: "Comments",
+				Type: models.QuestionTypeTextbox,
+				Specification: models.QuestionSpecification{
+					TextboxSpecification: &models.TextboxSpecification{MaxLength: 100},
+				},
+			},
+		},
+	}
+	return survey, choiceID, likertID, textID
+}
+
+func crossTabSubmission(surveyID, choiceID, likertID bson.ObjectID, plan, rating string) *models.Submission {
+	return &models.Submission{
+		ID:       bson.NewObjectID(),
+		SurveyID: surveyID,
+		Responses: []models.SubmissionResponse{
+			{QuestionID: choiceID, Answer: plan},
+			{QuestionID: likertID, Answer: rating},
+		},
+	}
+}
+
+func TestService_CrossTabulate(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockSurveyRepo := new(MockSurveyRepository)
+		mockSubmissionRepo := new(MockSubmissionRepository)
+		mockChat := new(MockChatCompletionService)
+		service := NewCrossTabService(mockSurveyRepo, mockSubmissionRepo, mockChat)
+
+		survey, choiceID, likertID, _ := crossTabSurvey()
+		submissions := []*models.Submission{
+			crossTabSubmission(survey.ID, choiceID, likertID, "Free", "1"),
+			crossTabSubmission(survey.ID, choiceID, likertID, "Free", "1"),
+			crossTabSubmission(survey.ID, choiceID, likertID, "Free", "3"),
+			crossTabSubmission(survey.ID, choiceID, likertID, "Pro", "3"),
+		}
+		mockSurveyRepo.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		mockSubmissionRepo.On("GetAllSubmissions", mock.Anything, survey.ID, mock.Anything).Return(submissions, nil)
+
+		req := &models.CrossTabulationRequest{RowQuestionID: choiceID.Hex(), ColumnQuestionID: likertID.Hex()}
+		crossTab, err := service.CrossTabulate(context.Background(), survey.ID, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Free", "Pro"}, crossTab.RowLabels)
+		assert.Equal(t, []string{"1", "2", "3"}, crossTab.ColumnLabels)
+		assert.Equal(t, [][]int{{2, 0, 1}, {0, 0, 1}}, crossTab.Counts)
+		assert.Equal(t, []int{3, 1}, crossTab.RowTotals)
+		assert.Equal(t, []int{2, 0, 2}, crossTab.ColumnTotals)
+		assert.Equal(t, 4, crossTab.Total)
+		assert.Equal(t, 66.67, crossTab.RowPercentages[0][0])
+		assert.Equal(t, 50.0, crossTab.ColumnPercentages[1][2])
+		assert.Equal(t, 1, crossTab.ChiSquare.DegreesOfFreedom)
+		assert.Nil(t, crossTab.Narrative)
+		mockChat.AssertNotCalled(t, "NewRequest", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Narrate", func(t *testing.T) {
+		mockSurveyRepo := new(MockSurveyRepository)
+		mockSubmissionRepo := new(MockSubmissionRepository)
+		mockChat := new(MockChatCompletionService)
+		service := NewCrossTabService(mockSurveyRepo, mockSubmissionRepo, mockChat)
+
+		survey, choiceID, likertID, _ := crossTabSurvey()
+		mockSurveyRepo.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		mockSubmissionRepo.On("GetAllSubmissions", mock.Anything, survey.ID, mock.Anything).Return([]*models.Submission{
+			crossTabSubmission(survey.ID, choiceID, likertID, "Pro", "2"),
+		}, nil)
+		narrative := "Pro users rate higher"
+		mockChat.On("NewRequest", mock.Anything, mock.Anything, mock.Anything).Return(&narrative, nil).Once()
+
+		contextType := models.ProductSatisfactionContext
+		req := &models.CrossTabulationRequest{
+			RowQuestionID:    choiceID.Hex(),
+			ColumnQuestionID: likertID.Hex(),
+			Narrate:          true,
+			ContextType:      &contextType,
+		}
+		crossTab, err := service.CrossTabulate(context.Background(), survey.ID, req)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, crossTab.Narrative)
+		assert.Equal(t, narrative, *crossTab.Narrative.Summary)
+		assert.Equal(t, choiceID, crossTab.Narrative.Question.ID)
+		mockChat.AssertExpectations(t)
+	})
+
+	t.Run("NarrateError", func(t *testing.T) {
+		mockSurveyRepo := new(MockSurveyRepository)
+		mockSubmissionRepo := new(MockSubmissionRepository)
+		mockChat := new(MockChatCompletionService)
+		service := NewCrossTabService(mockSurveyRepo, mockSubmissionRepo, mockChat)
+
+		survey, choiceID, likertID, _ := crossTabSurvey()
+		mockSurveyRepo.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		mockSubmissionRepo.On("GetAllSubmissions", mock.Anything, survey.ID, mock.Anything).Return([]*models.Submission{}, nil)
+		mockChat.On("NewRequest", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("llm down"))
+
+		req := &models.CrossTabulationRequest{RowQuestionID: choiceID.Hex(), ColumnQuestionID: likertID.Hex(), Narrate: true}
+		crossTab, err := service.CrossTabulate(context.Background(), survey.ID, req)
+
+		assert.NoError(t, err)
+		assert.Nil(t, crossTab.Narrative.Summary)
+		assert.Equal(t, "llm down", *crossTab.Narrative.ErrorLog)
+	})
+
+	t.Run("TextboxQuestion", func(t *testing.T) {
+		mockSurveyRepo := new(MockSurveyRepository)
+		mockSubmissionRepo := new(MockSubmissionRepository)
+		service := NewCrossTabService(mockSurveyRepo, mockSubmissionRepo, new(MockChatCompletionService))
+
+		survey, choiceID, _, textID := crossTabSurvey()
+		mockSurveyRepo.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+
+		req := &models.CrossTabulationRequest{RowQuestionID: choiceID.Hex(), ColumnQuestionID: textID.Hex()}
+		_, err := service.CrossTabulate(context.Background(), survey.ID, req)
+
+		assert.ErrorIs(t, err, ErrInvalidCrossTabulation)
+		mockSubmissionRepo.AssertNotCalled(t, "GetAllSubmissions", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("SameQuestion", func(t *testing.T) {
+		mockSurveyRepo := new(MockSurveyRepository)
+		service := NewCrossTabService(mockSurveyRepo, new(MockSubmissionRepository), new(MockChatCompletionService))
+
+		survey, choiceID, _, _ := crossTabSurvey()
+		mockSurveyRepo.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+
+		req := &models.CrossTabulationRequest{RowQuestionID: choiceID.Hex(), ColumnQuestionID: choiceID.Hex()}
+		_, err := service.CrossTabulate(context.Background(), survey.ID, req)
+
+		assert.ErrorIs(t, err, ErrInvalidCrossTabulation)
+	})
+
+	t.Run("SurveyNotFound", func(t *testing.T) {
+		mockSurveyRepo := new(MockSurveyRepository)
+		service := NewCrossTabService(mockSurveyRepo, new(MockSubmissionRepository), new(MockChatCompletionService))
+
+		surveyID := bson.NewObjectID()
+		mockSurveyRepo.On("GetByID", mock.Anything, surveyID).Return(nil, mongo.ErrNoDocuments)
+
+		_, err := service.CrossTabulate(context.Background(), surveyID, &models.CrossTabulationRequest{})
+
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	})
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"osp/internal/models"
+	"osp/internal/repositories"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const githubModelsEmbeddingsURL = "https://models.github.ai/inference/embeddings"
+
+// IEmbeddingProvider abstracts the external embeddings API. Implementations return one
+// vector per text, in order; vectors are only comparable within the same Model. opts
+// attributes the request like a chat completion.
+type IEmbeddingProvider interface {
+	Model() string
+	Embed(ctx context.Context, texts []string, opts *models.ChatCompletionOptions) ([][]float32, error)
+}
+
+// GitHubEmbeddingProvider embeds texts with GitHub Models. Like chat completions, its
+// requests are checked against the token budget, logged to the chat completion logs
+// with their usage and cost, and failures are returned as LLMError and retried.
+type GitHubEmbeddingProvider struct {
+	model   string
+	logRepo repositories.ChatCompletionLogRepository
+	budget  BudgetGuard
+	prices  map[string]models.ModelPrice
+	retry   retryPolicy
+	url     string
+}
+
+func NewGitHubEmbeddingProvider(model string, logRepo repositories.ChatCompletionLogRepository, budget BudgetGuard, prices map[string]models.ModelPrice) *GitHubEmbeddingProvider {
+	return &GitHubEmbeddingProvider{
+		model:   model,
+		logRepo: logRepo,
+		budget:  budget,
+		prices:  prices,
+		retry:   defaultRetryPolicy,
+		url:     githubModelsEmbeddingsURL,
+	}
+}
+
+func (p *GitHubEmbeddingProvider) Model() string {
+	return p.model
+}
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Usage *models.ChatCompletionUsage `json:"usage,omitempty"`
+}
+
+func (p *GitHubEmbeddingProvider) Embed(ctx context.Context, texts []string, opts *models.ChatCompletionOptions) ([][]float32, error) {
+	if opts == nil {
+		opts = &models.ChatCompletionOptions{}
+	}
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("GITHUB_TOKEN is not set")
+	}
+	jsonData, err := json.Marshal(embeddingRequest{Model: p.model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+
+	logEntry := models.ChatCompletionRequestLog{
+		ID:        bson.NewObjectID(),
+		Request:   models.ChatCompletionRequest{Model: p.model},
+		Input:     texts,
+		Reference: &opts.Reference,
+		InsightID: opts.InsightID,
+		SurveyID:  opts.SurveyID,
+		Model:     p.model,
+		Status:    models.ChatCompletionPending,
+		CreatedAt: time.Now(),
+	}
+	if p.budget != nil {
+		if err := p.budget.Allow(ctx, opts.SurveyID); err != nil {
+			// Rejected requests are logged as failed, without attempts.
+			logEntry.Status = models.ChatCompletionFailed
+			logEntry.Error = err.Error()
+			p.createLog(ctx, &logEntry)
+			return nil, err
+		}
+	}
+	p.createLog(ctx, &logEntry)
+
+	start := time.Now()
+	var vectors [][]float32
+	var usage *models.ChatCompletionUsage
+	var attempts []models.ChatCompletionAttempt
+	err = p.retry.do(ctx, func(ctx context.Context) error {
+		attemptStart := time.Now()
+		var statusCode int
+		var sendErr error
+		vectors, usage, statusCode, sendErr = p.send(ctx, token, jsonData, len(texts))
+		attempt := models.ChatCompletionAttempt{
+			Number:     len(attempts) + 1,
+			HTTPStatus: statusCode,
+			DurationMs: time.Since(attemptStart).Milliseconds(),
+		}
+		if sendErr != nil {
+			attempt.Error = sendErr.Error()
+		}
+		attempts = append(attempts, attempt)
+		return sendErr
+	})
+	p.finishLog(ctx, logEntry.ID, usage, attempts, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+func (p *GitHubEmbeddingProvider) createLog(ctx context.Context, entry *models.ChatCompletionRequestLog) {
+	if err := p.logRepo.Create(ctx, entry); err != nil {
+		log.Printf("embedding log insert failed: %v", err)
+	}
+}
+
+// finishLog moves the request log to its terminal state
+func (p *GitHubEmbeddingProvider) finishLog(ctx context.Context, id bson.ObjectID, usage *models.ChatCompletionUsage, attempts []models.ChatCompletionAttempt, duration time.Duration, err error) {
+	set := bson.M{
+		"attempts":   attempts,
+		"latency_ms": duration.Milliseconds(),
+	}
+	if usage != nil {
+		set["usage"] = usage
+		set["cost"] = requestCost(p.prices, p.model, usage)
+	}
+	if err == nil {
+		set["status"] = models.ChatCompletionSucceeded
+	} else {
+		set["status"] = models.ChatCompletionFailed
+		set["error"] = err.Error()
+		if len(attempts) > 0 && attempts[len(attempts)-1].HTTPStatus != 0 {
+			set["http_status"] = attempts[len(attempts)-1].HTTPStatus
+		}
+	}
+	if err := p.logRepo.Update(context.WithoutCancel(ctx), id, bson.M{"$set": set}); err != nil {
+		log.Printf("embedding log %s update failed: %v", id.Hex(), err)
+	}
+}
+
+// send performs a single request, placing the returned vectors by their index. The
+// usage is returned whenever the provider answered, as it is billed even if the
+// answer is unusable.
+func (p *GitHubEmbeddingProvider) send(ctx context.Context, token string, jsonData []byte, n int) ([][]float32, *models.ChatCompletionUsage, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, 0, classifyTransportError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, nil, resp.StatusCode, classifyResponse(resp.StatusCode, resp.Header, body)
+	}
+
+	var embeddings embeddingResponse
+	if err := json.Unmarshal(body, &embeddings); err != nil {
+		return nil, nil, resp.StatusCode, fmt.Errorf("invalid embeddings response: %w", err)
+	}
+	if len(embeddings.Data) != n {
+		return nil, embeddings.Usage, resp.StatusCode, fmt.Errorf("invalid embeddings response: %d vectors for %d texts", len(embeddings.Data), n)
+	}
+	vectors := make([][]float32, n)
+	for _, data := range embeddings.Data {
+		if data.Index < 0 || data.Index >= n || vectors[data.Index] != nil {
+			return nil, embeddings.Usage, resp.StatusCode, fmt.Errorf("invalid embeddings response: unexpected index %d", data.Index)
+		}
+		vectors[data.Index] = data.Embedding
+	}
+	return vectors, embeddings.Usage, resp.StatusCode, nil
+}
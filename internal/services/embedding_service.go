@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"osp/internal/embedding"
+	"osp/internal/models"
+	"osp/internal/redaction"
+	"osp/internal/repositories"
+
+	"github.com/hibiken/asynq"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrInvalidSemanticSearch wraps every error caused by the request rather than by storage.
+var ErrInvalidSemanticSearch = errors.New("invalid semantic search request")
+
+// IEmbeddingService embeds the TEXTBOX answers of submissions in the background and
+// searches them by meaning
+type IEmbeddingService interface {
+	EnqueueSubmission(submissionID bson.ObjectID) error
+	EmbedSubmission(ctx context.Context, submissionID bson.ObjectID) error
+	DeleteSubmission(ctx context.Context, submissionID bson.ObjectID) error
+	ReindexSurvey(ctx context.Context, surveyID bson.ObjectID) (*models.ReindexEmbeddings, error)
+	Search(ctx context.Context, surveyID bson.ObjectID, req *models.SemanticSearchRequest) (*models.SemanticSearch, error)
+	RegisterHandlers(mux *asynq.ServeMux)
+}
+
+type EmbeddingService struct {
+	embeddingRepo  repositories.AnswerEmbeddingRepository
+	submissionRepo repositories.SubmissionRepository
+	surveyRepo     repositories.SurveyRepository
+	provider       IEmbeddingProvider
+	jobEnqueuer    JobEnqueuer
+	redactionTerms []string
+}
+
+func NewEmbeddingService(
+	embeddingRepo repositories.AnswerEmbeddingRepository,
+	submissionRepo repositories.SubmissionRepository,
+	surveyRepo repositories.SurveyRepository,
+	provider IEmbeddingProvider,
+	jobEnqueuer JobEnqueuer,
+	redactionTerms []string,
+) *EmbeddingService {
+	return &EmbeddingService{
+		embeddingRepo:  embeddingRepo,
+		submissionRepo: submissionRepo,
+		surveyRepo:     surveyRepo,
+		provider:       provider,
+		jobEnqueuer:    jobEnqueuer,
+		redactionTerms: redactionTerms,
+	}
+}
+
+func (s *EmbeddingService) RegisterHandlers(mux *asynq.ServeMux) {
+	mux.HandleFunc(TypeEmbedSubmission, func(ctx context.Context, task *asynq.Task) error {
+		submissionID, err := parseEmbedSubmissionPayload(task)
+		if err != nil {
+			return err
+		}
+		err = s.EmbedSubmission(ctx, submissionID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// The submission or its survey was deleted in the meantime.
+			return fmt.Errorf("%w: %v", asynq.SkipRetry, err)
+		}
+		return err
+	})
+}
+
+func (s *EmbeddingService) EnqueueSubmission(submissionID bson.ObjectID) error {
+	task, err := newEmbedSubmissionTask(submissionID)
+	if err != nil {
+		return err
+	}
+	_, err = s.jobEnqueuer.Enqueue(task, asynq.Queue(embeddingsQueue), asynq.MaxRetry(3))
+	return err
+}
+
+// EmbedSubmission embeds the non-empty TEXTBOX answers of a submission, replacing any
+// earlier vectors. Answers are redacted before they are sent if the survey asks for
+// it; the stored answer is the original one.
+func (s *EmbeddingService) EmbedSubmission(ctx context.Context, submissionID bson.ObjectID) error {
+	submission, err := s.submissionRepo.GetByID(ctx, submissionID)
+	if err != nil {
+		return err
+	}
+	survey, err := s.surveyRepo.GetByID(ctx, submission.SurveyID)
+	if err != nil {
+		return err
+	}
+
+	textbox := make(map[bson.ObjectID]bool)
+	for _, question := range survey.Questions {
+		if question.Type == models.QuestionTypeTextbox {
+			textbox[question.ID] = true
+		}
+	}
+	var redactor *redaction.Redactor
+	mapping := redaction.NewMapping()
+	if survey.Redaction != nil && survey.Redaction.Enabled {
+		redactor = redaction.New(append(slices.Clone(s.redactionTerms), survey.Redaction.Terms...))
+	}
+
+	var responses []models.SubmissionResponse
+	var texts []string
+	for _, response := range submission.Responses {
+		if !textbox[response.QuestionID] || strings.TrimSpace(response.Answer) == "" {
+			continue
+		}
+		text := response.Answer
+		if redactor != nil {
+			text, _ = redactor.Redact(text, mapping)
+		}
+		responses = append(responses, response)
+		texts = append(texts, text)
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+
+	vectors, err := s.provider.Embed(ctx, texts, &models.ChatCompletionOptions{
+		Reference: "embed:" + submission.ID.Hex(),
+		SurveyID:  &submission.SurveyID,
+	})
+	if err != nil {
+		return err
+	}
+	if len(vectors) != len(texts) {
+		return fmt.Errorf("embedding provider returned %d vectors for %d answers", len(vectors), len(texts))
+	}
+	now := time.Now()
+	embeddings := make([]*models.AnswerEmbedding, len(responses))
+	for i, response := range responses {
+		embeddings[i] = &models.AnswerEmbedding{
+			ID:           bson.NewObjectID(),
+			SurveyID:     submission.SurveyID,
+			SubmissionID: submission.ID,
+			QuestionID:   response.QuestionID,
+			Answer:       response.Answer,
+			Model:        s.provider.Model(),
+			Vector:       vectors[i],
+			CreatedAt:    now,
+		}
+	}
+	return s.embeddingRepo.ReplaceForSubmission(ctx, submission.ID, embeddings)
+}
+
+func (s *EmbeddingService) DeleteSubmission(ctx context.Context, submissionID bson.ObjectID) error {
+	return s.embeddingRepo.DeleteBySubmission(ctx, submissionID)
+}
+
+// ReindexSurvey queues every submission of a survey to be embedded, e.g. those stored
+// before embeddings were enabled or after the embedding model changed
+func (s *EmbeddingService) ReindexSurvey(ctx context.Context, surveyID bson.ObjectID) (*models.ReindexEmbeddings, error) {
+	if _, err := s.surveyRepo.GetByID(ctx, surveyID); err != nil {
+		return nil, fmt.Errorf("%w: survey not found", ErrInvalidSemanticSearch)
+	}
+	submissions, err := s.submissionRepo.GetAllSubmissions(ctx, surveyID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, submission := range submissions {
+		if err := s.EnqueueSubmission(submission.ID); err != nil {
+			return nil, err
+		}
+	}
+	return &models.ReindexEmbeddings{SurveyID: surveyID, Enqueued: len(submissions)}, nil
+}
+
+// Search embeds the query and returns the answers of the survey closest to it by
+// cosine similarity. Only answers embedded with the current model are searched.
+func (s *EmbeddingService) Search(ctx context.Context, surveyID bson.ObjectID, req *models.SemanticSearchRequest) (*models.SemanticSearch, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, fmt.Errorf("%w: query is empty", ErrInvalidSemanticSearch)
+	}
+	survey, err := s.surveyRepo.GetByID(ctx, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: survey not found", ErrInvalidSemanticSearch)
+	}
+	var questionID *bson.ObjectID
+	if req.QuestionID != nil {
+		id, err := bson.ObjectIDFromHex(*req.QuestionID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid question ID", ErrInvalidSemanticSearch)
+		}
+		if !slices.ContainsFunc(survey.Questions, func(q models.Question) bool { return q.ID == id }) {
+			return nil, fmt.Errorf("%w: question %s is not part of the survey", ErrInvalidSemanticSearch, id.Hex())
+		}
+		questionID = &id
+	}
+
+	embeddings, err := s.embeddingRepo.FindBySurvey(ctx, surveyID, s.provider.Model(), questionID)
+	if err != nil {
+		return nil, err
+	}
+	search := &models.SemanticSearch{
+		SurveyID: surveyID,
+		Query:    query,
+		Model:    s.provider.Model(),
+		Results:  []models.SemanticSearchResult{},
+	}
+	if len(embeddings) == 0 {
+		return search, nil
+	}
+
+	vectors, err := s.provider.Embed(ctx, []string{query}, &models.ChatCompletionOptions{
+		Reference: "search:" + surveyID.Hex(),
+		SurveyID:  &surveyID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([][]float32, len(embeddings))
+	for i, e := range embeddings {
+		candidates[i] = e.Vector
+	}
+	for _, match := range embedding.Nearest(vectors[0], candidates, req.Limit) {
+		e := embeddings[match.Index]
+		search.Results = append(search.Results, models.SemanticSearchResult{
+			SubmissionID: e.SubmissionID,
+			QuestionID:   e.QuestionID,
+			Answer:       e.Answer,
+			Score:        match.Score,
+		})
+	}
+	return search, nil
+}
+
+// Asynq task definitions
+const TypeEmbedSubmission = "submission:embed"
+
+const embeddingsQueue = "embeddings"
+
+type EmbedSubmissionPayload struct {
+	SubmissionID string `json:"submission_id"`
+}
+
+func newEmbedSubmissionTask(submissionID bson.ObjectID) (*asynq.Task, error) {
+	payload, err := json.Marshal(EmbedSubmissionPayload{SubmissionID: submissionID.Hex()})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeEmbedSubmission, payload), nil
+}
+
+func parseEmbedSubmissionPayload(task *asynq.Task) (bson.ObjectID, error) {
+	var payload EmbedSubmissionPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return bson.ObjectID{}, err
+	}
+	if payload.SubmissionID == "" {
+		return bson.ObjectID{}, fmt.Errorf("missing submission_id")
+	}
+	return bson.ObjectIDFromHex(payload.SubmissionID)
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"osp/internal/embedding"
+	"osp/internal/models"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// MockAnswerEmbeddingRepository is a mock implementation of AnswerEmbeddingRepository
+type MockAnswerEmbeddingRepository struct {
+	mock.Mock
+}
+
+func (m *MockAnswerEmbeddingRepository) ReplaceForSubmission(ctx context.Context, submissionID bson.ObjectID, embeddings []*models.AnswerEmbedding) error {
+	args := m.Called(ctx, submissionID, embeddings)
+	return args.Error(0)
+}
+
+func (m *MockAnswerEmbeddingRepository) FindBySurvey(ctx context.Context, surveyID bson.ObjectID, model string, questionID *bson.ObjectID) ([]*models.AnswerEmbedding, error) {
+	args := m.Called(ctx, surveyID, model, questionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AnswerEmbedding), args.Error(1)
+}
+
+func (m *MockAnswerEmbeddingRepository) DeleteBySubmission(ctx context.Context, submissionID bson.ObjectID) error {
+	args := m.Called(ctx, submissionID)
+	return args.Error(0)
+}
+
+func TestEmbeddingService_EmbedSubmission(t *testing.T) {
+	textID, choiceID := bson.NewObjectID(), bson.NewObjectID()
+	survey := &models.Survey{
+		ID: bson.NewObjectID(),
+		Questions: []models.Question{
+			{ID: textID, Type: models.QuestionTypeTextbox},
+			{ID: choiceID, Type: models.QuestionTypeMultipleChoice},
+		},
+		Redaction: &models.RedactionSettings{Enabled: true},
+	}
+	submission := &models.Submission{
+		ID:       bson.NewObjectID(),
+		SurveyID: survey.ID,
+		Responses: []models.SubmissionResponse{
+			{QuestionID: textID, Answer: "Onboarding was slow, mail amy@example.com"},
+			{QuestionID: choiceID, Answer: "Good"},
+		},
+	}
+	mockEmbeddingRepo := new(MockAnswerEmbeddingRepository)
+	mockSubmissionRepo := new(MockSubmissionRepository)
+	mockSurveyRepo := new(MockSurveyRepository)
+	service := NewEmbeddingService(mockEmbeddingRepo, mockSubmissionRepo, mockSurveyRepo, embedding.Hash{}, nil, nil)
+
+	mockSubmissionRepo.On("GetByID", mock.Anything, submission.ID).Return(submission, nil)
+	mockSurveyRepo.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+	// The vector is of the redacted answer, while the original is stored.
+	redacted, _ := embedding.Hash{}.Embed(context.Background(), []string{"Onboarding was slow, mail [EMAIL_1]"}, nil)
+	mockEmbeddingRepo.On("ReplaceForSubmission", mock.Anything, submission.ID, mock.MatchedBy(func(embeddings []*models.AnswerEmbedding) bool {
+		return len(embeddings) == 1 && embeddings[0].QuestionID == textID && embeddings[0].SurveyID == survey.ID &&
+			embeddings[0].Answer == submission.Responses[0].Answer && embeddings[0].Model == "local-hash-256" &&
+			assert.ObjectsAreEqual(redacted[0], embeddings[0].Vector)
+	})).Return(nil)
+
+	err := service.EmbedSubmission(context.Background(), submission.ID)
+
+	assert.NoError(t, err)
+	mockEmbeddingRepo.AssertExpectations(t)
+}
+
+func TestEmbeddingService_EnqueueSubmission(t *testing.T) {
+	mockEnqueuer := new(MockJobEnqueuer)
+	service := NewEmbeddingService(nil, nil, nil, embedding.Hash{}, mockEnqueuer, nil)
+	submissionID := bson.NewObjectID()
+
+	mockEnqueuer.On("Enqueue", mock.MatchedBy(func(task *asynq.Task) bool {
+		id, err := parseEmbedSubmissionPayload(task)
+		return task.Type() == TypeEmbedSubmission && err == nil && id == submissionID
+	}), mock.Anything).Return(&asynq.TaskInfo{}, nil)
+
+	assert.NoError(t, service.EnqueueSubmission(submissionID))
+	mockEnqueuer.AssertExpectations(t)
+}
+
+func TestEmbeddingService_Search(t *testing.T) {
+	textID := bson.NewObjectID()
+	survey := &models.Survey{
+		ID:        bson.NewObjectID(),
+		Questions: []models.Question{{ID: textID, Type: models.QuestionTypeTextbox}},
+	}
+	answers := []string{
+		"Great lunch options in the canteen",
+		"The onboarding buddy helped a lot",
+		"Onboarding documents were outdated",
+	}
+	vectors, _ := embedding.Hash{}.Embed(context.Background(), answers, nil)
+	var embeddings []*models.AnswerEmbedding
+	for i, answer := range answers {
+		embeddings = append(embeddings, &models.AnswerEmbedding{
+			SurveyID:     survey.ID,
+			SubmissionID: bson.NewObjectID(),
+			QuestionID:   textID,
+			Answer:       answer,
+			Model:        "local-hash-256",
+			Vector:       vectors[i],
+		})
+	}
+	setup := func() (*EmbeddingService, *MockAnswerEmbeddingRepository) {
+		mockEmbeddingRepo := new(MockAnswerEmbeddingRepository)
+		mockSurveyRepo := new(MockSurveyRepository)
+		mockSurveyRepo.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		return NewEmbeddingService(mockEmbeddingRepo, nil, mockSurveyRepo, embedding.Hash{}, nil, nil), mockEmbeddingRepo
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		service, mockEmbeddingRepo := setup()
+		questionID := textID.Hex()
+		mockEmbeddingRepo.On("FindBySurvey", mock.Anything, survey.ID, "local-hash-256", &textID).Return(embeddings, nil)
+
+		search, err := service.Search(context.Background(), survey.ID, &models.SemanticSearchRequest{Query: " onboarding ", QuestionID: &questionID, Limit: 5})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "onboarding", search.Query)
+		// The canteen answer shares no word with the query.
+		assert.Len(t, search.Results, 2)
+		for _, result := range search.Results {
+			assert.Contains(t, result.Answer, "nboarding")
+			assert.Greater(t, result.Score, 0.0)
+		}
+		assert.GreaterOrEqual(t, search.Results[0].Score, search.Results[1].Score)
+	})
+
+	t.Run("NothingEmbedded", func(t *testing.T) {
+		service, mockEmbeddingRepo := setup()
+		mockEmbeddingRepo.On("FindBySurvey", mock.Anything, survey.ID, "local-hash-256", (*bson.ObjectID)(nil)).Return(nil, nil)
+
+		search, err := service.Search(context.Background(), survey.ID, &models.SemanticSearchRequest{Query: "onboarding", Limit: 5})
+
+		assert.NoError(t, err)
+		assert.Empty(t, search.Results)
+	})
+
+	t.Run("UnknownQuestion", func(t *testing.T) {
+		service, _ := setup()
+		questionID := bson.NewObjectID().Hex()
+
+		_, err := service.Search(context.Background(), survey.ID, &models.SemanticSearchRequest{Query: "onboarding", QuestionID: &questionID, Limit: 5})
+
+		assert.ErrorIs(t, err, ErrInvalidSemanticSearch)
+	})
+}
+
+func TestGitHubEmbeddingProvider_Embed(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "token")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Vectors may come back out of order.
+		fmt.Fprint(w, `{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}],"usage":{"prompt_tokens":4,"total_tokens":4}}`)
+	}))
+	defer server.Close()
+	const model = "openai/text-embedding-3-small"
+	surveyID := bson.NewObjectID()
+	opts := &models.ChatCompletionOptions{Reference: "search:" + surveyID.Hex(), SurveyID: &surveyID}
+	newProvider := func() (*GitHubEmbeddingProvider, *MockChatCompletionLogRepository, *MockBudgetService) {
+		mockLogRepo := new(MockChatCompletionLogRepository)
+		mockBudget := new(MockBudgetService)
+		provider := NewGitHubEmbeddingProvider(model, mockLogRepo, mockBudget, map[string]models.ModelPrice{
+			model: {InputPerMillion: 500000},
+		})
+		provider.url = server.URL
+		provider.retry = retryPolicy{MaxAttempts: 1, BaseDelay: time.Millisecond, MaxDelay: time.Second, sleep: sleepContext}
+		return provider, mockLogRepo, mockBudget
+	}
+
+	t.Run("Succeeded", func(t *testing.T) {
+		provider, mockLogRepo, mockBudget := newProvider()
+		mockBudget.On("Allow", mock.Anything, &surveyID).Return(nil)
+		mockLogRepo.On("Create", mock.Anything, mock.MatchedBy(func(entry *models.ChatCompletionRequestLog) bool {
+			return entry.Status == models.ChatCompletionPending && *entry.SurveyID == surveyID &&
+				entry.Model == model && len(entry.Input) == 2
+		})).Return(nil)
+		// The usage of the request counts towards the survey like a chat completion.
+		mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(update bson.M) bool {
+			set := update["$set"].(bson.M)
+			usage := set["usage"].(*models.ChatCompletionUsage)
+			return set["status"] == models.ChatCompletionSucceeded && usage.TotalTokens == 4 && set["cost"] == 2.0
+		})).Return(nil)
+
+		vectors, err := provider.Embed(context.Background(), []string{"a", "b"}, opts)
+
+		assert.NoError(t, err)
+		assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, vectors)
+		mockLogRepo.AssertExpectations(t)
+	})
+
+	t.Run("VectorCountMismatch", func(t *testing.T) {
+		provider, mockLogRepo, mockBudget := newProvider()
+		mockBudget.On("Allow", mock.Anything, &surveyID).Return(nil)
+		mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(update bson.M) bool {
+			set := update["$set"].(bson.M)
+			return set["status"] == models.ChatCompletionFailed && set["usage"] != nil
+		})).Return(nil)
+
+		_, err := provider.Embed(context.Background(), []string{"a"}, opts)
+
+		assert.ErrorContains(t, err, "2 vectors for 1 texts")
+		mockLogRepo.AssertExpectations(t)
+	})
+
+	t.Run("BudgetExceeded", func(t *testing.T) {
+		provider, mockLogRepo, mockBudget := newProvider()
+		mockBudget.On("Allow", mock.Anything, &surveyID).Return(&LLMError{Kind: LLMErrorBudgetExceeded, Message: "token budget exhausted"})
+		mockLogRepo.On("Create", mock.Anything, mock.MatchedBy(func(entry *models.ChatCompletionRequestLog) bool {
+			return entry.Status == models.ChatCompletionFailed && entry.Error != ""
+		})).Return(nil)
+
+		_, err := provider.Embed(context.Background(), []string{"a"}, opts)
+
+		var llmErr *LLMError
+		assert.ErrorAs(t, err, &llmErr)
+		assert.Equal(t, LLMErrorBudgetExceeded, llmErr.Kind)
+		mockLogRepo.AssertExpectations(t)
+		mockLogRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
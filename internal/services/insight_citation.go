@@ -0,0 +1,64 @@
+package services
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"osp/internal/models"
+)
+
+// citationPattern matches citations such as [3] or [2, 5]; redaction placeholders like
+// [EMAIL_1] are not citations.
+var citationPattern = regexp.MustCompile(`\[\s*\d+(?:\s*,\s*\d+)*\s*\]`)
+
+// leadingSpaceCitationPattern also matches the space before a citation, for removing it
+var leadingSpaceCitationPattern = regexp.MustCompile(`[ \t]*` + citationPattern.String())
+
+// resolveCitations maps the answer numbers cited in a batch summary to the answers and
+// their submissions. Numbers without an answer are returned separately, so made-up
+// citations are visible to reviewers.
+func resolveCitations(batch models.InsightBatch, summary string) ([]models.Citation, []int) {
+	var answers []string
+	if batch.TextualAnswers != nil {
+		answers = *batch.TextualAnswers
+	}
+	var citations []models.Citation
+	var invalid []int
+	seen := make(map[int]bool)
+	for _, match := range citationPattern.FindAllString(summary, -1) {
+		for _, field := range strings.Split(strings.Trim(match, "[] "), ",") {
+			number, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || seen[number] {
+				continue
+			}
+			seen[number] = true
+			// Batches from before citations have no submission IDs to link to.
+			if number < 1 || number > len(answers) || number > len(batch.SubmissionIDs) {
+				invalid = append(invalid, number)
+				continue
+			}
+			submissionID := batch.SubmissionIDs[number-1]
+			citation := models.Citation{
+				Number:       number,
+				SubmissionID: submissionID,
+				Quote:        answers[number-1],
+				Link:         submissionLink(submissionID.Hex()),
+			}
+			if number <= len(batch.OriginalAnswers) {
+				citation.Original = batch.OriginalAnswers[number-1]
+			}
+			citations = append(citations, citation)
+		}
+	}
+	return citations, invalid
+}
+
+func submissionLink(id string) string {
+	return "/api/admin/submissions/" + id
+}
+
+// stripCitations removes citations from a batch summary before it is merged with others
+func stripCitations(summary string) string {
+	return leadingSpaceCitationPattern.ReplaceAllString(summary, "")
+}
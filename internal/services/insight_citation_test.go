@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+
+	"osp/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestResolveCitations(t *testing.T) {
+	first, second := bson.NewObjectID(), bson.NewObjectID()
+	batch := models.InsightBatch{
+		TextualAnswers: &[]string{"Too fast", "Pace was rushed", "Great labs"},
+		SubmissionIDs:  []bson.ObjectID{first, second, second},
+	}
+
+	citations, invalid := resolveCitations(batch, "Many found the pace too fast [1, 2]. Labs were liked [3]. Pace again [2]. Also [7] and [EMAIL_1].")
+
+	assert.Equal(t, []models.Citation{
+		{Number: 1, SubmissionID: first, Quote: "Too fast", Link: "/api/admin/submissions/" + first.Hex()},
+		{Number: 2, SubmissionID: second, Quote: "Pace was rushed", Link: "/api/admin/submissions/" + second.Hex()},
+		{Number: 3, SubmissionID: second, Quote: "Great labs", Link: "/api/admin/submissions/" + second.Hex()},
+	}, citations)
+	assert.Equal(t, []int{7}, invalid)
+
+	t.Run("Translated", func(t *testing.T) {
+		batch := models.InsightBatch{
+			TextualAnswers:  &[]string{"Too fast", "Too much overtime"},
+			OriginalAnswers: []string{"", "加班太多"},
+			SubmissionIDs:   []bson.ObjectID{first, second},
+		}
+		citations, _ := resolveCitations(batch, "Pace [1], overtime [2].")
+		assert.Empty(t, citations[0].Original)
+		assert.Equal(t, "加班太多", citations[1].Original)
+	})
+
+	t.Run("WithoutSubmissionIDs", func(t *testing.T) {
+		citations, invalid := resolveCitations(models.InsightBatch{TextualAnswers: &[]string{"a"}}, "Theme [1]")
+		assert.Nil(t, citations)
+		assert.Equal(t, []int{1}, invalid)
+	})
+}
+
+func TestStripCitations(t *testing.T) {
+	assert.Equal(t, "Pace was too fast. Contact [EMAIL_1].", stripCitations("Pace was too fast [1, 2]. Contact [EMAIL_1]."))
+}
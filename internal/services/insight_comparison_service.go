@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"osp/internal/models"
+	"osp/internal/repositories"
+	"osp/internal/stats"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ErrInvalidInsightComparison is returned when two insights cannot be compared
+var ErrInvalidInsightComparison = errors.New("invalid insight comparison")
+
+type IInsightComparisonService interface {
+	CreateComparison(ctx context.Context, req *models.CreateInsightComparisonRequest) (*models.InsightComparison, error)
+	GetComparisons(ctx context.Context, offset, limit int64) ([]*models.InsightComparison, error)
+	GetComparison(ctx context.Context, id bson.ObjectID) (*models.InsightComparison, error)
+}
+
+type InsightComparisonService struct {
+	comparisonRepo        repositories.InsightComparisonRepository
+	insightRepo           repositories.InsightRepository
+	chatCompletionService IChatCompletionService
+}
+
+func NewInsightComparisonService(
+	comparisonRepo repositories.InsightComparisonRepository,
+	insightRepo repositories.InsightRepository,
+	chatCompletionService IChatCompletionService,
+) *InsightComparisonService {
+	return &InsightComparisonService{
+		comparisonRepo:        comparisonRepo,
+		insightRepo:           insightRepo,
+		chatCompletionService: chatCompletionService,
+	}
+}
+
+func (s *InsightComparisonService) CreateComparison(ctx context.Context, req *models.CreateInsightComparisonRequest) (*models.InsightComparison, error) {
+	if req.BaseInsightID == req.TargetInsightID {
+		return nil, fmt.Errorf("%w: base and target insights must differ", ErrInvalidInsightComparison)
+	}
+	base, err := s.insightRepo.GetByID(ctx, req.BaseInsightID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: base insight not found", ErrInvalidInsightComparison)
+	}
+	target, err := s.insightRepo.GetByID(ctx, req.TargetInsightID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: target insight not found", ErrInvalidInsightComparison)
+	}
+	if !isInsightFinished(base.Status) || !isInsightFinished(target.Status) {
+		return nil, fmt.Errorf("%w: both insights must be completed", ErrInvalidInsightComparison)
+	}
+
+	questions := compareInsights(base, target)
+	matched := false
+	for _, question := range questions {
+		if question.BaseQuestionID != nil && question.TargetQuestionID != nil {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return nil, fmt.Errorf("%w: insights have no questions in common", ErrInvalidInsightComparison)
+	}
+
+	comparison := &models.InsightComparison{
+		ID:              bson.NewObjectID(),
+		BaseInsightID:   base.ID,
+		TargetInsightID: target.ID,
+		BaseSurveyID:    base.SurveyID,
+		TargetSurveyID:  target.SurveyID,
+		ContextType:     target.ContextType,
+		Questions:       questions,
+		CreatedAt:       time.Now(),
+	}
+
+	report, err := s.generateComparisonReport(ctx, comparison, base, target)
+	if err != nil {
+		errMsg := err.Error()
+		comparison.ErrorLog = &errMsg
+	} else {
+		comparison.Report = report
+	}
+
+	if err := s.comparisonRepo.Create(ctx, comparison); err != nil {
+		return nil, err
+	}
+	return comparison, nil
+}
+
+func (s *InsightComparisonService) GetComparisons(ctx context.Context, offset, limit int64) ([]*models.InsightComparison, error) {
+	return s.comparisonRepo.List(ctx, offset, limit)
+}
+
+func (s *InsightComparisonService) GetComparison(ctx context.Context, id bson.ObjectID) (*models.InsightComparison, error) {
+	return s.comparisonRepo.GetByID(ctx, id)
+}
+
+func isInsightFinished(status models.InsightStatus) bool {
+	return status == models.InsightCompleted || status == models.InsightPartial
+}
+
+// questionAggregate merges every batch of one question of an insight
+type questionAggregate struct {
+	question  models.Question
+	counts    map[string]int
+	responses int
+	summaries []string
+}
+
+func aggregateByQuestion(insight *models.Insight, key func(models.Question) string) ([]string, map[string]*questionAggregate) {
+	order := []string{}
+	aggregates := make(map[string]*questionAggregate)
+	for _, batch := range insight.Batches {
+		k := key(batch.Question)
+		aggregate, ok := aggregates[k]
+		if !ok {
+			aggregate = &questionAggregate{question: batch.Question, counts: make(map[string]int)}
+			aggregates[k] = aggregate
+			order = append(order, k)
+		}
+		if batch.AggregatedAnswer != nil {
+			for answer, count := range *batch.AggregatedAnswer {
+				aggregate.counts[answer] += count
+				aggregate.responses += count
+			}
+		}
+		if batch.TextualAnswers != nil {
+			aggregate.responses += len(*batch.TextualAnswers)
+		}
+		if batch.Summary != nil {
+			aggregate.summaries = append(aggregate.summaries, *batch.Summary)
+		}
+	}
+	return order, aggregates
+}
+
+// compareInsights pairs the questions of both insights, by ID when they share a survey
+// and by type and text otherwise, and computes the per-question deltas.
+func compareInsights(base, target *models.Insight) []models.QuestionComparison {
+	key := func(q models.Question) string {
+		return string(q.Type) + "|" + strings.ToLower(strings.TrimSpace(q.Text))
+	}
+	if base.SurveyID == target.SurveyID {
+		key = func(q models.Question) string { return q.ID.Hex() }
+	}
+
+	baseOrder, baseAggregates := aggregateByQuestion(base, key)
+	targetOrder, targetAggregates := aggregateByQuestion(target, key)
+
+	order := append([]string{}, baseOrder...)
+	for _, k := range targetOrder {
+		if _, ok := baseAggregates[k]; !ok {
+			order = append(order, k)
+		}
+	}
+
+	comparisons := make([]models.QuestionComparison, 0, len(order))
+	for _, k := range order {
+		comparisons = append(comparisons, compareQuestion(baseAggregates[k], targetAggregates[k]))
+	}
+	return comparisons
+}
+
+func compareQuestion(base, target *questionAggregate) models.QuestionComparison {
+	reference := base
+	if reference == nil {
+		reference = target
+	}
+	empty := &questionAggregate{counts: map[string]int{}}
+	comparison := models.QuestionComparison{
+		Text: reference.question.Text,
+		Type: reference.question.Type,
+	}
+	if base != nil {
+		id := base.question.ID
+		comparison.BaseQuestionID = &id
+		comparison.BaseResponses = base.responses
+	} else {
+		base = empty
+	}
+	if target != nil {
+		id := target.question.ID
+		comparison.TargetQuestionID = &id
+		comparison.TargetResponses = target.responses
+	} else {
+		target = empty
+	}
+
+	switch reference.question.Type {
+	case models.QuestionTypeMultipleChoice, models.QuestionTypeLikert:
+		for _, option := range comparisonOptions(&reference.question, base.counts, target.counts) {
+			basePercentage := percentage(base.counts[option], base.responses)
+			targetPercentage := percentage(target.counts[option], target.responses)
+			comparison.Options = append(comparison.Options, models.OptionDelta{
+				Option:           option,
+				BaseCount:        base.counts[option],
+				TargetCount:      target.counts[option],
+				BasePercentage:   basePercentage,
+				TargetPercentage: targetPercentage,
+				PercentageDelta:  roundTo2(targetPercentage - basePercentage),
+			})
+		}
+		if reference.question.Type == models.QuestionTypeLikert {
+			baseStatistics := numericSummary(base.counts)
+			targetStatistics := numericSummary(target.counts)
+			comparison.BaseStatistics = &baseStatistics
+			comparison.TargetStatistics = &targetStatistics
+			if baseStatistics.Count > 0 && targetStatistics.Count > 0 {
+				delta := roundTo2(targetStatistics.Mean - baseStatistics.Mean)
+				comparison.MeanDelta = &delta
+			}
+		}
+	default:
+		if len(base.summaries) > 0 {
+			summary := strings.Join(base.summaries, "\n")
+			comparison.BaseSummary = &summary
+		}
+		if len(target.summaries) > 0 {
+			summary := strings.Join(target.summaries, "\n")
+			comparison.TargetSummary = &summary
+		}
+	}
+	return comparison
+}
+
+// comparisonOptions lists the question's categories followed by any other observed answers.
+func comparisonOptions(question *models.Question, distributions ...map[string]int) []string {
+	options := questionCategories(question)
+	known := indexOf(options)
+	extra := []string{}
+	for _, distribution := range distributions {
+		for answer := range distribution {
+			if _, ok := known[answer]; !ok {
+				known[answer] = len(known)
+				extra = append(extra, answer)
+			}
+		}
+	}
+	sort.Strings(extra)
+	return append(options, extra...)
+}
+
+func numericSummary(counts map[string]int) stats.Summary {
+	frequencies := make(map[float64]int)
+	for answer, count := range counts {
+		value, err := strconv.ParseFloat(answer, 64)
+		if err != nil {
+			continue
+		}
+		frequencies[value] += count
+	}
+	return stats.Summarize(frequencies)
+}
+
+func roundTo2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func (s *InsightComparisonService) generateComparisonReport(ctx context.Context, comparison *models.InsightComparison, base, target *models.Insight) (string, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Base period: insight created %s with %d submissions.\n", base.CreatedAt.Format(time.DateOnly), base.SubmissionCount)
+	fmt.Fprintf(&sb, "Target period: insight created %s with %d submissions.\n\n", target.CreatedAt.Format(time.DateOnly), target.SubmissionCount)
+	for _, question := range comparison.Questions {
+		fmt.Fprintf(&sb, "Question (%s): %s\n", question.Type, question.Text)
+		if question.BaseQuestionID == nil {
+			sb.WriteString("  Only asked in the target period.\n")
+		}
+		if question.TargetQuestionID == nil {
+			sb.WriteString("  Only asked in the base period.\n")
+		}
+		fmt.Fprintf(&sb, "  Responses: base=%d target=%d\n", question.BaseResponses, question.TargetResponses)
+		for _, option := range question.Options {
+			fmt.Fprintf(&sb, "  %q: base=%.2f%% target=%.2f%% delta=%+.2f pp\n", option.Option, option.BasePercentage, option.TargetPercentage, option.PercentageDelta)
+		}
+		if question.MeanDelta != nil {
+			fmt.Fprintf(&sb, "  Mean: base=%.2f target=%.2f delta=%+.2f\n", question.BaseStatistics.Mean, question.TargetStatistics.Mean, *question.MeanDelta)
+		}
+		if question.BaseSummary != nil {
+			fmt.Fprintf(&sb, "  Base summary: %s\n", *question.BaseSummary)
+		}
+		if question.TargetSummary != nil {
+			fmt.Fprintf(&sb, "  Target summary: %s\n", *question.TargetSummary)
+		}
+	}
+	sb.WriteString("\nBase overall analysis: " + base.Analysis + "\n")
+	sb.WriteString("Target overall analysis: " + target.Analysis + "\n")
+
+	reqBody := models.ChatCompletionRequest{
+		Messages: []models.ChatCompletionMessage{
+			{
+				Role:    "system",
+				Content: fmt.Sprintf("You are a helpful assistant. Compare two runs of the same survey in the context of %s and write a \"what changed\" report highlighting the most significant improvements, regressions and new themes.", comparison.ContextType),
+			},
+			{
+				Role:    "user",
+				Content: sb.String(),
+			},
+		},
+		Temperature: 0.5,
+		TopP:        1.0,
+		MaxTokens:   800,
+		Model:       insightModel,
+	}
+
+	opts := &models.ChatCompletionOptions{
+		Reference: fmt.Sprintf("comparison:%s", comparison.ID.Hex()),
+		SurveyID:  &comparison.TargetSurveyID,
+	}
+	resp, err := s.chatCompletionService.NewRequest(ctx, reqBody, opts)
+	if err != nil {
+		return "", err
+	}
+	if resp == nil {
+		return "", fmt.Errorf("empty response")
+	}
+	return *resp, nil
+}
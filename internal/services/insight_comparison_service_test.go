@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"osp/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockInsightComparisonRepository struct {
+	mock.Mock
+}
+
+func (m *MockInsightComparisonRepository) Create(ctx context.Context, comparison *models.InsightComparison) error {
+	args := m.Called(ctx, comparison)
+	return args.Error(0)
+}
+
+func (m *MockInsightComparisonRepository) GetByID(ctx context.Context, id bson.ObjectID) (*models.InsightComparison, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InsightComparison), args.Error(1)
+}
+
+func (m *MockInsightComparisonRepository) List(ctx context.Context, offset, limit int64) ([]*models.InsightComparison, error) {
+	args := m.Called(ctx, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.InsightComparison), args.Error(1)
+}
+
+func comparisonInsight(surveyID bson.ObjectID, likert models.Question, counts map[string]int, text models.Question, summary string) *models.Insight {
+	return &models.Insight{
+		ID:          bson.NewObjectID(),
+		SurveyID:    surveyID,
+		ContextType: models.CourseFeedbackContext,
+		Status:      models.InsightCompleted,
+		Batches: []models.InsightBatch{
+			{BatchNumber: 1, Question: likert, AggregatedAnswer: &counts},
+			{BatchNumber: 2, Question: text, TextualAnswers: &[]string{"a", "b"}, Summary: &summary},
+		},
+	}
+}
+
+func TestService_CreateComparison(t *testing.T) {
+	likert := models.Question{
+		ID:   bson.NewObjectID(),
+		Text: "Pace",
+		Type: models.QuestionTypeLikert,
+		Specification: models.QuestionSpecification{
+			LikertSpecification: &models.LikertSpecification{Min: 1, Max: 3},
+		},
+	}
+	text := models.Question{ID: bson.NewObjectID(), Text: "Comments", Type: models.QuestionTypeTextbox}
+
+	t.Run("Success", func(t *testing.T) {
+		mockComparisonRepo := new(MockInsightComparisonRepository)
+		mockInsightRepo := new(MockInsightRepository)
+		mockChat := new(MockChatCompletionService)
+		service := NewInsightComparisonService(mockComparisonRepo, mockInsightRepo, mockChat)
+
+		surveyID := bson.NewObjectID()
+		base := comparisonInsight(surveyID, likert, map[string]int{"1": 2, "3": 2}, text, "Too fast")
+		target := comparisonInsight(surveyID, likert, map[string]int{"3": 4}, text, "Just right")
+
+		mockInsightRepo.On("GetByID", mock.Anything, base.ID).Return(base, nil)
+		mockInsightRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+		report := "Pace improved"
+		mockChat.On("NewRequest", mock.Anything, mock.Anything, mock.Anything).Return(&report, nil).Once()
+		mockComparisonRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		comparison, err := service.CreateComparison(context.Background(), &models.CreateInsightComparisonRequest{
+			BaseInsightID:   base.ID,
+			TargetInsightID: target.ID,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, report, comparison.Report)
+		assert.Len(t, comparison.Questions, 2)
+
+		pace := comparison.Questions[0]
+		assert.Equal(t, []string{"1", "2", "3"}, []string{pace.Options[0].Option, pace.Options[1].Option, pace.Options[2].Option})
+		assert.Equal(t, 50.0, pace.Options[0].BasePercentage)
+		assert.Equal(t, -50.0, pace.Options[0].PercentageDelta)
+		assert.Equal(t, 50.0, pace.Options[2].PercentageDelta)
+		assert.Equal(t, 1.0, *pace.MeanDelta)
+
+		comments := comparison.Questions[1]
+		assert.Equal(t, 2, comments.BaseResponses)
+		assert.Equal(t, "Too fast", *comments.BaseSummary)
+		assert.Equal(t, "Just right", *comments.TargetSummary)
+		mockComparisonRepo.AssertExpectations(t)
+	})
+
+	t.Run("EquivalentSurveys", func(t *testing.T) {
+		mockComparisonRepo := new(MockInsightComparisonRepository)
+		mockInsightRepo := new(MockInsightRepository)
+		mockChat := new(MockChatCompletionService)
+		service := NewInsightComparisonService(mockComparisonRepo, mockInsightRepo, mockChat)
+
+		renamed := text
+		renamed.ID = bson.NewObjectID()
+		renamed.Text = " comments "
+		base := comparisonInsight(bson.NewObjectID(), likert, map[string]int{"2": 1}, text, "Old")
+		target := comparisonInsight(bson.NewObjectID(), likert, map[string]int{"2": 1}, renamed, "New")
+		target.Batches[0].Question.ID = bson.NewObjectID()
+
+		mockInsightRepo.On("GetByID", mock.Anything, base.ID).Return(base, nil)
+		mockInsightRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+		mockChat.On("NewRequest", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("llm down"))
+		mockComparisonRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		comparison, err := service.CreateComparison(context.Background(), &models.CreateInsightComparisonRequest{
+			BaseInsightID:   base.ID,
+			TargetInsightID: target.ID,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, comparison.Questions, 2)
+		assert.NotNil(t, comparison.Questions[1].BaseQuestionID)
+		assert.NotNil(t, comparison.Questions[1].TargetQuestionID)
+		assert.Equal(t, "llm down", *comparison.ErrorLog)
+	})
+
+	t.Run("NotCompleted", func(t *testing.T) {
+		mockInsightRepo := new(MockInsightRepository)
+		service := NewInsightComparisonService(new(MockInsightComparisonRepository), mockInsightRepo, new(MockChatCompletionService))
+
+		surveyID := bson.NewObjectID()
+		base := comparisonInsight(surveyID, likert, map[string]int{}, text, "")
+		target := comparisonInsight(surveyID, likert, map[string]int{}, text, "")
+		target.Status = models.InsightProcessing
+		mockInsightRepo.On("GetByID", mock.Anything, base.ID).Return(base, nil)
+		mockInsightRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+
+		_, err := service.CreateComparison(context.Background(), &models.CreateInsightComparisonRequest{
+			BaseInsightID:   base.ID,
+			TargetInsightID: target.ID,
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidInsightComparison)
+	})
+
+	t.Run("NoCommonQuestions", func(t *testing.T) {
+		mockInsightRepo := new(MockInsightRepository)
+		service := NewInsightComparisonService(new(MockInsightComparisonRepository), mockInsightRepo, new(MockChatCompletionService))
+
+		other := likert
+		other.Text = "Difficulty"
+		base := comparisonInsight(bson.NewObjectID(), likert, map[string]int{}, text, "")
+		base.Batches = base.Batches[:1]
+		target := comparisonInsight(bson.NewObjectID(), other, map[string]int{}, text, "")
+		target.Batches = target.Batches[:1]
+		mockInsightRepo.On("GetByID", mock.Anything, base.ID).Return(base, nil)
+		mockInsightRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+
+		_, err := service.CreateComparison(context.Background(), &models.CreateInsightComparisonRequest{
+			BaseInsightID:   base.ID,
+			TargetInsightID: target.ID,
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidInsightComparison)
+	})
+}
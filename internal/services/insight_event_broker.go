@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"osp/internal/models"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// InsightEventBroker fans insight progress events out from the worker to the HTTP nodes
+type InsightEventBroker interface {
+	Publish(ctx context.Context, event *models.InsightEvent) error
+	// Subscribe returns the events of one insight; the channel is closed once ctx is done.
+	Subscribe(ctx context.Context, insightID bson.ObjectID) (<-chan *models.InsightEvent, error)
+}
+
+type RedisInsightEventBroker struct {
+	client redis.UniversalClient
+}
+
+func NewRedisInsightEventBroker(client redis.UniversalClient) *RedisInsightEventBroker {
+	return &RedisInsightEventBroker{
+		client: client,
+	}
+}
+
+func insightEventChannel(insightID bson.ObjectID) string {
+	return "insight-events:" + insightID.Hex()
+}
+
+func (b *RedisInsightEventBroker) Publish(ctx context.Context, event *models.InsightEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, insightEventChannel(event.InsightID), payload).Err()
+}
+
+func (b *RedisInsightEventBroker) Subscribe(ctx context.Context, insightID bson.ObjectID) (<-chan *models.InsightEvent, error) {
+	pubsub := b.client.Subscribe(ctx, insightEventChannel(insightID))
+	// Wait for the subscription to be confirmed so no event published afterwards is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	events := make(chan *models.InsightEvent)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event models.InsightEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					log.Printf("invalid insight event on %s: %v", msg.Channel, err)
+					continue
+				}
+				select {
+				case events <- &event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
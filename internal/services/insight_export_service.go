@@ -0,0 +1,180 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"osp/internal/language"
+	"osp/internal/models"
+	"osp/internal/report"
+	"osp/internal/repositories"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type IInsightExportService interface {
+	ExportInsight(ctx context.Context, id bson.ObjectID, format report.Format) (*models.InsightExport, error)
+}
+
+type InsightExportService struct {
+	insightRepo repositories.InsightRepository
+	surveyRepo  repositories.SurveyRepository
+	brand       report.Brand
+}
+
+func NewInsightExportService(insightRepo repositories.InsightRepository, surveyRepo repositories.SurveyRepository, brand report.Brand) *InsightExportService {
+	return &InsightExportService{
+		insightRepo: insightRepo,
+		surveyRepo:  surveyRepo,
+		brand:       brand,
+	}
+}
+
+// ExportInsight renders a finished insight as a report document
+func (s *InsightExportService) ExportInsight(ctx context.Context, id bson.ObjectID, format report.Format) (*models.InsightExport, error) {
+	insight, err := s.insightRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isInsightFinished(insight.Status) {
+		return nil, fmt.Errorf("%w: only completed insights can be exported", ErrInsightInProgress)
+	}
+	// The report still renders if the survey has been deleted since.
+	title := "Survey " + insight.SurveyID.Hex()
+	survey, err := s.surveyRepo.GetByID(ctx, insight.SurveyID)
+	switch {
+	case err == nil:
+		title = survey.Name
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		return nil, err
+	}
+
+	doc := buildInsightReport(insight, title, s.brand, time.Now())
+	content, err := report.Render(doc, format)
+	if err != nil {
+		return nil, err
+	}
+	return &models.InsightExport{
+		Filename:    fmt.Sprintf("insight-%s.%s", insight.ID.Hex(), format),
+		ContentType: format.ContentType(),
+		Content:     content,
+	}, nil
+}
+
+// buildInsightReport lays out the analysis and one section per question, with the
+// answer distribution of choice and Likert questions as a table and a chart
+func buildInsightReport(insight *models.Insight, title string, brand report.Brand, now time.Time) *report.Document {
+	doc := &report.Document{
+		Brand:       brand,
+		Title:       title,
+		GeneratedAt: now,
+		Analysis:    insight.Analysis,
+		Details: []report.Field{
+			{Label: "Context", Value: string(insight.ContextType)},
+			{Label: "Status", Value: string(insight.Status)},
+			{Label: "Submissions", Value: strconv.Itoa(insight.SubmissionCount)},
+			{Label: "Created", Value: insight.CreatedAt.UTC().Format(time.DateOnly)},
+		},
+	}
+	if insight.Filter != nil {
+		doc.Details = append(doc.Details, report.Field{Label: "Segment", Value: "Filtered submissions only"})
+	}
+	if insight.AnalysisLanguage != "" {
+		doc.Details = append(doc.Details, report.Field{Label: "Analysis language", Value: language.Name(insight.AnalysisLanguage)})
+	}
+
+	order, aggregates := aggregateByQuestion(insight, func(q models.Question) string { return q.ID.Hex() })
+	for _, key := range order {
+		aggregate := aggregates[key]
+		question := aggregate.question
+		section := report.Section{
+			Title:   question.Text,
+			Kind:    string(question.Type),
+			Summary: questionSummary(insight, aggregate),
+			Stats:   []report.Field{{Label: "Responses", Value: strconv.Itoa(aggregate.responses)}},
+		}
+		switch question.Type {
+		case models.QuestionTypeMultipleChoice, models.QuestionTypeLikert:
+			section.Table = &report.Table{Header: []string{"Answer", "Count", "Share"}}
+			section.Chart = &report.Chart{}
+			for _, option := range comparisonOptions(&question, aggregate.counts) {
+				count := aggregate.counts[option]
+				section.Table.Rows = append(section.Table.Rows, []string{
+					option,
+					strconv.Itoa(count),
+					fmt.Sprintf("%.1f%%", percentage(count, aggregate.responses)),
+				})
+				section.Chart.Bars = append(section.Chart.Bars, report.Bar{Label: option, Value: count})
+			}
+			if question.Type == models.QuestionTypeLikert {
+				if summary := numericSummary(aggregate.counts); summary.Count > 0 {
+					section.Stats = append(section.Stats,
+						report.Field{Label: "Mean", Value: fmt.Sprintf("%.2f", summary.Mean)},
+						report.Field{Label: "Standard deviation", Value: fmt.Sprintf("%.2f", summary.StdDev)},
+					)
+				}
+			}
+		default:
+			section.Stats = append(section.Stats, textStats(insight, question.ID)...)
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+	return doc
+}
+
+// textStats sums the sentiment, languages and flagged answers of a question's batches
+func textStats(insight *models.Insight, questionID bson.ObjectID) []report.Field {
+	var sentiment models.SentimentDistribution
+	languages := make(map[string]int)
+	flagged := 0
+	for _, batch := range insight.Batches {
+		if batch.Question.ID != questionID {
+			continue
+		}
+		if d := batch.Sentiment; d != nil {
+			n := sentiment.Positive + sentiment.Neutral + sentiment.Negative
+			m := d.Positive + d.Neutral + d.Negative
+			if n+m > 0 {
+				sentiment.AverageScore = (sentiment.AverageScore*float64(n) + d.AverageScore*float64(m)) / float64(n+m)
+			}
+			sentiment.Positive += d.Positive
+			sentiment.Neutral += d.Neutral
+			sentiment.Negative += d.Negative
+		}
+		for code, n := range batch.Languages {
+			languages[code] += n
+		}
+		flagged += len(batch.FlaggedAnswers)
+	}
+
+	var stats []report.Field
+	if sentiment.Positive+sentiment.Neutral+sentiment.Negative > 0 {
+		stats = append(stats, report.Field{Label: "Sentiment", Value: fmt.Sprintf("%d positive, %d neutral, %d negative (average %.2f)",
+			sentiment.Positive, sentiment.Neutral, sentiment.Negative, sentiment.AverageScore)})
+	}
+	if len(languages) > 0 {
+		codes := make([]string, 0, len(languages))
+		for code := range languages {
+			codes = append(codes, code)
+		}
+		slices.SortFunc(codes, func(a, b string) int {
+			return cmp.Or(cmp.Compare(languages[b], languages[a]), cmp.Compare(a, b))
+		})
+		parts := make([]string, len(codes))
+		for i, code := range codes {
+			parts[i] = fmt.Sprintf("%s %d", language.Name(code), languages[code])
+		}
+		stats = append(stats, report.Field{Label: "Languages", Value: strings.Join(parts, ", ")})
+	}
+	if flagged > 0 {
+		stats = append(stats, report.Field{Label: "Flagged answers", Value: strconv.Itoa(flagged)})
+	}
+	return stats
+}
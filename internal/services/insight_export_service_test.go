@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"osp/internal/models"
+	"osp/internal/report"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestInsightExportService_ExportInsight(t *testing.T) {
+	brand := report.Brand{Name: "OSP", Color: "#1f6feb"}
+	likertID, textID := bson.NewObjectID(), bson.NewObjectID()
+	likert := models.Question{ID: likertID, Type: models.QuestionTypeLikert, Text: "How satisfied are you?",
+		Specification: models.QuestionSpecification{LikertSpecification: &models.LikertSpecification{Min: 1, Max: 3}}}
+	text := models.Question{ID: textID, Type: models.QuestionTypeTextbox, Text: "What could be better?"}
+	summary := "Overtime is the main concern."
+	insight := &models.Insight{
+		ID:               bson.NewObjectID(),
+		SurveyID:         bson.NewObjectID(),
+		ContextType:      models.EmployeeEngagementContext,
+		Status:           models.InsightCompleted,
+		SubmissionCount:  4,
+		Analysis:         "Staff are mostly satisfied.",
+		AnalysisLanguage: "en",
+		Batches: []models.InsightBatch{
+			{Question: likert, AggregatedAnswer: &map[string]int{"1": 1, "3": 3}},
+			{Question: text, TextualAnswers: &[]string{"Less overtime", "More overtime pay"}, Summary: &summary,
+				Languages: map[string]int{"en": 1, "yue": 1},
+				Sentiment: &models.SentimentDistribution{Negative: 2, AverageScore: -0.4}},
+		},
+	}
+
+	t.Run("Markdown", func(t *testing.T) {
+		mockInsightRepo := new(MockInsightRepository)
+		mockSurveyRepo := new(MockSurveyRepository)
+		service := NewInsightExportService(mockInsightRepo, mockSurveyRepo, brand)
+		mockInsightRepo.On("GetByID", mock.Anything, insight.ID).Return(insight, nil)
+		mockSurveyRepo.On("GetByID", mock.Anything, insight.SurveyID).Return(&models.Survey{Name: "Staff pulse"}, nil)
+
+		export, err := service.ExportInsight(context.Background(), insight.ID, report.Markdown)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "insight-"+insight.ID.Hex()+".md", export.Filename)
+		md := string(export.Content)
+		assert.True(t, strings.HasPrefix(md, "# Staff pulse\n"))
+		assert.Contains(t, md, "- **Analysis language:** English")
+		// Likert scales list every point, including unused ones.
+		assert.Contains(t, md, "| 1 | 1 | 25.0% |\n| 2 | 0 | 0.0% |\n| 3 | 3 | 75.0% |")
+		assert.Contains(t, md, "- **Mean:** 2.50")
+		assert.Contains(t, md, "Overtime is the main concern.")
+		assert.Contains(t, md, "- **Sentiment:** 0 positive, 0 neutral, 2 negative (average -0.40)")
+		assert.Contains(t, md, "- **Languages:** English 1, Cantonese 1")
+	})
+
+	t.Run("DeletedSurvey", func(t *testing.T) {
+		mockInsightRepo := new(MockInsightRepository)
+		mockSurveyRepo := new(MockSurveyRepository)
+		service := NewInsightExportService(mockInsightRepo, mockSurveyRepo, brand)
+		mockInsightRepo.On("GetByID", mock.Anything, insight.ID).Return(insight, nil)
+		mockSurveyRepo.On("GetByID", mock.Anything, insight.SurveyID).Return(nil, mongo.ErrNoDocuments)
+
+		export, err := service.ExportInsight(context.Background(), insight.ID, report.PDF)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "application/pdf", export.ContentType)
+		assert.True(t, strings.HasPrefix(string(export.Content), "%PDF-"))
+	})
+
+	t.Run("InProgress", func(t *testing.T) {
+		mockInsightRepo := new(MockInsightRepository)
+		service := NewInsightExportService(mockInsightRepo, nil, brand)
+		pending := &models.Insight{ID: bson.NewObjectID(), Status: models.InsightProcessing, CreatedAt: time.Now()}
+		mockInsightRepo.On("GetByID", mock.Anything, pending.ID).Return(pending, nil)
+
+		_, err := service.ExportInsight(context.Background(), pending.ID, report.HTML)
+
+		assert.ErrorIs(t, err, ErrInsightInProgress)
+	})
+}
@@ -0,0 +1,415 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"osp/internal/models"
+	"osp/internal/repositories"
+
+	"github.com/hibiken/asynq"
+	"github.com/robfig/cron/v3"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrInvalidInsightSchedule is returned when a schedule has an invalid cron expression or survey
+var ErrInvalidInsightSchedule = errors.New("invalid insight schedule")
+
+type IInsightScheduleService interface {
+	CreateSchedule(ctx context.Context, req *models.CreateInsightScheduleRequest) (*models.InsightSchedule, error)
+	GetSchedules(ctx context.Context, offset, limit int64, surveyID *bson.ObjectID) ([]*models.InsightSchedule, error)
+	GetSchedule(ctx context.Context, id bson.ObjectID) (*models.InsightSchedule, error)
+	UpdateSchedule(ctx context.Context, id bson.ObjectID, req *models.InsightScheduleInput) (*models.InsightSchedule, error)
+	DeleteSchedule(ctx context.Context, id bson.ObjectID) error
+	GetRuns(ctx context.Context, id bson.ObjectID, offset, limit int64) ([]*models.InsightScheduleRun, error)
+}
+
+// InsightCreator is the subset of IInsightService used to run schedules
+type InsightCreator interface {
+	CreateInsight(ctx context.Context, req *models.CreateInsightRequest) (*models.Insight, error)
+	EnqueueInsight(ctx context.Context, id bson.ObjectID) error
+}
+
+type InsightScheduleService struct {
+	scheduleRepo   repositories.InsightScheduleRepository
+	runRepo        repositories.InsightScheduleRunRepository
+	surveyRepo     repositories.SurveyRepository
+	submissionRepo repositories.SubmissionRepository
+	insights       InsightCreator
+}
+
+func NewInsightScheduleService(
+	scheduleRepo repositories.InsightScheduleRepository,
+	runRepo repositories.InsightScheduleRunRepository,
+	surveyRepo repositories.SurveyRepository,
+	submissionRepo repositories.SubmissionRepository,
+	insights InsightCreator,
+) *InsightScheduleService {
+	return &InsightScheduleService{
+		scheduleRepo:   scheduleRepo,
+		runRepo:        runRepo,
+		surveyRepo:     surveyRepo,
+		submissionRepo: submissionRepo,
+		insights:       insights,
+	}
+}
+
+func (s *InsightScheduleService) CreateSchedule(ctx context.Context, req *models.CreateInsightScheduleRequest) (*models.InsightSchedule, error) {
+	now := time.Now()
+	schedule := &models.InsightSchedule{
+		ID:        bson.NewObjectID(),
+		SurveyID:  req.SurveyID,
+		CreatedAt: now,
+	}
+	if err := s.applyInput(ctx, schedule, &req.InsightScheduleInput); err != nil {
+		return nil, err
+	}
+	if err := s.scheduleRepo.Create(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *InsightScheduleService) GetSchedules(ctx context.Context, offset, limit int64, surveyID *bson.ObjectID) ([]*models.InsightSchedule, error) {
+	schedules, err := s.scheduleRepo.List(ctx, offset, limit, surveyID)
+	if err != nil {
+		return nil, err
+	}
+	for _, schedule := range schedules {
+		setNextRun(schedule, time.Now())
+	}
+	return schedules, nil
+}
+
+func (s *InsightScheduleService) GetSchedule(ctx context.Context, id bson.ObjectID) (*models.InsightSchedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	setNextRun(schedule, time.Now())
+	return schedule, nil
+}
+
+// UpdateSchedule replaces the settings of a schedule; the periodic task manager picks
+// up the change at its next sync
+func (s *InsightScheduleService) UpdateSchedule(ctx context.Context, id bson.ObjectID, req *models.InsightScheduleInput) (*models.InsightSchedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyInput(ctx, schedule, req); err != nil {
+		return nil, err
+	}
+	if err := s.scheduleRepo.Update(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// DeleteSchedule removes a schedule with its run history; the insights it created are kept
+func (s *InsightScheduleService) DeleteSchedule(ctx context.Context, id bson.ObjectID) error {
+	if err := s.scheduleRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.runRepo.DeleteBySchedule(ctx, id)
+}
+
+func (s *InsightScheduleService) GetRuns(ctx context.Context, id bson.ObjectID, offset, limit int64) ([]*models.InsightScheduleRun, error) {
+	if _, err := s.scheduleRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.runRepo.ListBySchedule(ctx, id, offset, limit)
+}
+
+// applyInput validates the settings against the schedule's survey and copies them over
+func (s *InsightScheduleService) applyInput(ctx context.Context, schedule *models.InsightSchedule, req *models.InsightScheduleInput) error {
+	if _, err := cron.ParseStandard(req.Cron); err != nil {
+		return fmt.Errorf("%w: invalid cron expression: %v", ErrInvalidInsightSchedule, err)
+	}
+	survey, err := s.surveyRepo.GetByID(ctx, schedule.SurveyID)
+	if err != nil {
+		return fmt.Errorf("%w: survey not found", ErrInvalidInsightSchedule)
+	}
+	if _, err := resolveSubmissionFilter(survey, req.Filter); err != nil {
+		return err
+	}
+	if req.OnlyNewSubmissions && req.Filter != nil && req.Filter.To != nil {
+		return fmt.Errorf("%w: only_new_submissions cannot be combined with a filter end date", ErrInvalidInsightSchedule)
+	}
+
+	schedule.ContextType = req.ContextType
+	schedule.Cron = req.Cron
+	schedule.Filter = req.Filter
+	schedule.OnlyNewSubmissions = req.OnlyNewSubmissions
+	schedule.ExcludeFlaggedAnswers = req.ExcludeFlaggedAnswers
+	schedule.AnalysisLanguage = req.AnalysisLanguage
+	schedule.Enabled = req.Enabled == nil || *req.Enabled
+	schedule.UpdatedAt = time.Now()
+	setNextRun(schedule, schedule.UpdatedAt)
+	return nil
+}
+
+// setNextRun sets when an enabled schedule is next due after now
+func setNextRun(schedule *models.InsightSchedule, now time.Time) {
+	schedule.NextRunAt = nil
+	if !schedule.Enabled {
+		return
+	}
+	// Like the asynq scheduler, expressions without CRON_TZ run in UTC.
+	spec, err := cron.ParseStandard(schedule.Cron)
+	if err != nil {
+		return
+	}
+	next := spec.Next(now.UTC())
+	schedule.NextRunAt = &next
+}
+
+// RunSchedule creates the insight of a due schedule and records the run. Runs without
+// submissions in their window are skipped, and a failed run leaves the window of the
+// next run open at the end of the last successful one. A disabled schedule is not run.
+//
+// taskID identifies the asynq task running the schedule. Its run is recorded as
+// PENDING before the insight is created, so a retried task resumes that run and
+// creates its insight at most once.
+func (s *InsightScheduleService) RunSchedule(ctx context.Context, id bson.ObjectID, taskID string) (*models.InsightScheduleRun, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !schedule.Enabled {
+		return nil, nil
+	}
+
+	run, err := s.resumeRun(ctx, schedule.ID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	resumed := run != nil
+	if !resumed {
+		run = s.newRun(ctx, schedule, taskID)
+		if err := s.runRepo.Create(ctx, run); err != nil {
+			return nil, err
+		}
+	}
+
+	if run.Status == models.InsightScheduleRunPending {
+		if err := s.createInsight(ctx, schedule, run, resumed); err != nil {
+			return nil, err
+		}
+		if err := s.runRepo.Update(ctx, run); err != nil {
+			return nil, err
+		}
+	}
+	if run.Status != models.InsightScheduleRunFailed {
+		if err := s.scheduleRepo.SetLastRunAt(ctx, schedule.ID, run.StartedAt); err != nil {
+			return nil, err
+		}
+	}
+	return run, nil
+}
+
+// resumeRun returns the run recorded by an earlier attempt of the task, or nil
+func (s *InsightScheduleService) resumeRun(ctx context.Context, scheduleID bson.ObjectID, taskID string) (*models.InsightScheduleRun, error) {
+	if taskID == "" {
+		return nil, nil
+	}
+	run, err := s.runRepo.GetByTaskID(ctx, scheduleID, taskID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	return run, err
+}
+
+// newRun starts a run of the schedule over its current window; a run with submissions
+// is PENDING until its insight is created
+func (s *InsightScheduleService) newRun(ctx context.Context, schedule *models.InsightSchedule, taskID string) *models.InsightScheduleRun {
+	// MongoDB stores milliseconds, so the end of this window equals the start of the next.
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	run := &models.InsightScheduleRun{
+		ID:         bson.NewObjectID(),
+		ScheduleID: schedule.ID,
+		TaskID:     taskID,
+		To:         now,
+		StartedAt:  now,
+	}
+	filter := scheduleWindow(schedule, now)
+	if filter != nil {
+		run.From = filter.From
+		if filter.To != nil && filter.To.Before(now) {
+			run.To = *filter.To
+		}
+	}
+
+	count, err := s.countSubmissions(ctx, schedule.SurveyID, filter)
+	switch {
+	case err != nil:
+		run.Status = models.InsightScheduleRunFailed
+		run.Error = err.Error()
+	case count == 0:
+		run.Status = models.InsightScheduleRunSkipped
+	default:
+		insightID := bson.NewObjectID()
+		run.Status = models.InsightScheduleRunPending
+		run.InsightID = &insightID
+	}
+	return run
+}
+
+// createInsight creates the insight of a pending run under the ID assigned to it. A
+// resumed run first looks the insight up, as the attempt that recorded the run may
+// have created it before failing, and enqueues it again if it is still PENDING.
+func (s *InsightScheduleService) createInsight(ctx context.Context, schedule *models.InsightSchedule, run *models.InsightScheduleRun, resumed bool) error {
+	if resumed {
+		err := s.insights.EnqueueInsight(ctx, *run.InsightID)
+		if err == nil {
+			run.Status = models.InsightScheduleRunCreated
+			return nil
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+	}
+	_, err := s.insights.CreateInsight(ctx, &models.CreateInsightRequest{
+		ID:                    *run.InsightID,
+		SurveyID:              schedule.SurveyID,
+		ContextType:           schedule.ContextType,
+		Filter:                runWindow(schedule, run),
+		ExcludeFlaggedAnswers: schedule.ExcludeFlaggedAnswers,
+		AnalysisLanguage:      schedule.AnalysisLanguage,
+	})
+	if errors.Is(err, ErrInsightNotEnqueued) {
+		// The retried task enqueues the insight when it resumes the run.
+		return err
+	}
+	if err != nil {
+		run.Status = models.InsightScheduleRunFailed
+		run.Error = err.Error()
+		return nil
+	}
+	run.Status = models.InsightScheduleRunCreated
+	return nil
+}
+
+// scheduleWindow returns the submission filter of a run at now; with
+// OnlyNewSubmissions it starts where the last run ended, or at the filter's own
+// start if that is later, and ends at now
+func scheduleWindow(schedule *models.InsightSchedule, now time.Time) *models.SubmissionFilter {
+	if !schedule.OnlyNewSubmissions {
+		return schedule.Filter
+	}
+	var filter models.SubmissionFilter
+	if schedule.Filter != nil {
+		filter = *schedule.Filter
+	}
+	if last := schedule.LastRunAt; last != nil && (filter.From == nil || last.After(*filter.From)) {
+		filter.From = last
+	}
+	filter.To = &now
+	return &filter
+}
+
+// runWindow returns the submission filter of a recorded run, which a resumed run
+// keeps even if another run moved the window of the schedule since
+func runWindow(schedule *models.InsightSchedule, run *models.InsightScheduleRun) *models.SubmissionFilter {
+	if !schedule.OnlyNewSubmissions {
+		return schedule.Filter
+	}
+	var filter models.SubmissionFilter
+	if schedule.Filter != nil {
+		filter = *schedule.Filter
+	}
+	to := run.To
+	filter.From, filter.To = run.From, &to
+	return &filter
+}
+
+// countSubmissions counts the submissions an insight with the given filter would cover
+func (s *InsightScheduleService) countSubmissions(ctx context.Context, surveyID bson.ObjectID, filter *models.SubmissionFilter) (int64, error) {
+	survey, err := s.surveyRepo.GetByID(ctx, surveyID)
+	if err != nil {
+		return 0, fmt.Errorf("survey not found")
+	}
+	resolved, err := resolveSubmissionFilter(survey, filter)
+	if err != nil {
+		return 0, err
+	}
+	return s.submissionRepo.Count(ctx, surveyID, resolved)
+}
+
+// GetConfigs implements asynq.PeriodicTaskConfigProvider with a task per enabled schedule
+func (s *InsightScheduleService) GetConfigs() ([]*asynq.PeriodicTaskConfig, error) {
+	schedules, err := s.scheduleRepo.ListEnabled(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	configs := make([]*asynq.PeriodicTaskConfig, 0, len(schedules))
+	for _, schedule := range schedules {
+		task, err := newRunInsightScheduleTask(schedule.ID)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, &asynq.PeriodicTaskConfig{
+			Cronspec: schedule.Cron,
+			Task:     task,
+			// Every server runs a periodic task manager; only one task is enqueued per run.
+			Opts: []asynq.Option{asynq.Queue(insightQueue), asynq.Unique(time.Minute)},
+		})
+	}
+	return configs, nil
+}
+
+func (s *InsightScheduleService) RegisterHandlers(mux *asynq.ServeMux) {
+	mux.HandleFunc(TypeRunInsightSchedule, func(ctx context.Context, task *asynq.Task) error {
+		scheduleID, err := parseRunInsightSchedulePayload(task)
+		if err != nil {
+			return err
+		}
+		// Periodic tasks get a new ID each time they are enqueued; retries keep it.
+		taskID, _ := asynq.GetTaskID(ctx)
+		run, err := s.RunSchedule(ctx, scheduleID, taskID)
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			// The schedule was deleted after the task was enqueued.
+			return nil
+		case err != nil:
+			return err
+		case run == nil:
+			log.Printf("asynq: insight schedule %s is disabled", scheduleID.Hex())
+		case run.Status == models.InsightScheduleRunFailed:
+			log.Printf("asynq: insight schedule %s failed: %s", scheduleID.Hex(), run.Error)
+		default:
+			log.Printf("asynq: insight schedule %s %s", scheduleID.Hex(), run.Status)
+		}
+		return nil
+	})
+}
+
+const TypeRunInsightSchedule = "insight:schedule"
+
+type RunInsightSchedulePayload struct {
+	ScheduleID string `json:"schedule_id"`
+}
+
+func newRunInsightScheduleTask(scheduleID bson.ObjectID) (*asynq.Task, error) {
+	payload, err := json.Marshal(RunInsightSchedulePayload{ScheduleID: scheduleID.Hex()})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeRunInsightSchedule, payload), nil
+}
+
+func parseRunInsightSchedulePayload(task *asynq.Task) (bson.ObjectID, error) {
+	var payload RunInsightSchedulePayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return bson.ObjectID{}, err
+	}
+	if payload.ScheduleID == "" {
+		return bson.ObjectID{}, fmt.Errorf("missing schedule_id")
+	}
+	return bson.ObjectIDFromHex(payload.ScheduleID)
+}
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"osp/internal/models"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type MockInsightScheduleRepository struct {
+	mock.Mock
+}
+
+func (m *MockInsightScheduleRepository) Create(ctx context.Context, schedule *models.InsightSchedule) error {
+	args := m.Called(ctx, schedule)
+	return args.Error(0)
+}
+
+func (m *MockInsightScheduleRepository) GetByID(ctx context.Context, id bson.ObjectID) (*models.InsightSchedule, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InsightSchedule), args.Error(1)
+}
+
+func (m *MockInsightScheduleRepository) List(ctx context.Context, offset, limit int64, surveyID *bson.ObjectID) ([]*models.InsightSchedule, error) {
+	args := m.Called(ctx, offset, limit, surveyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.InsightSchedule), args.Error(1)
+}
+
+func (m *MockInsightScheduleRepository) ListEnabled(ctx context.Context) ([]*models.InsightSchedule, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.InsightSchedule), args.Error(1)
+}
+
+func (m *MockInsightScheduleRepository) Update(ctx context.Context, schedule *models.InsightSchedule) error {
+	args := m.Called(ctx, schedule)
+	return args.Error(0)
+}
+
+func (m *MockInsightScheduleRepository) SetLastRunAt(ctx context.Context, id bson.ObjectID, lastRunAt time.Time) error {
+	args := m.Called(ctx, id, lastRunAt)
+	return args.Error(0)
+}
+
+func (m *MockInsightScheduleRepository) Delete(ctx context.Context, id bson.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type MockInsightScheduleRunRepository struct {
+	mock.Mock
+}
+
+func (m *MockInsightScheduleRunRepository) Create(ctx context.Context, run *models.InsightScheduleRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *MockInsightScheduleRunRepository) GetByTaskID(ctx context.Context, scheduleID bson.ObjectID, taskID string) (*models.InsightScheduleRun, error) {
+	args := m.Called(ctx, scheduleID, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InsightScheduleRun), args.Error(1)
+}
+
+func (m *MockInsightScheduleRunRepository) Update(ctx context.Context, run *models.InsightScheduleRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *MockInsightScheduleRunRepository) ListBySchedule(ctx context.Context, scheduleID bson.ObjectID, offset, limit int64) ([]*models.InsightScheduleRun, error) {
+	args := m.Called(ctx, scheduleID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.InsightScheduleRun), args.Error(1)
+}
+
+func (m *MockInsightScheduleRunRepository) DeleteBySchedule(ctx context.Context, scheduleID bson.ObjectID) error {
+	args := m.Called(ctx, scheduleID)
+	return args.Error(0)
+}
+
+type MockInsightCreator struct {
+	mock.Mock
+}
+
+func (m *MockInsightCreator) CreateInsight(ctx context.Context, req *models.CreateInsightRequest) (*models.Insight, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Insight), args.Error(1)
+}
+
+func (m *MockInsightCreator) EnqueueInsight(ctx context.Context, id bson.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type scheduleMocks struct {
+	schedules   *MockInsightScheduleRepository
+	runs        *MockInsightScheduleRunRepository
+	surveys     *MockSurveyRepository
+	submissions *MockSubmissionRepository
+	insights    *MockInsightCreator
+}
+
+func newScheduleService() (*InsightScheduleService, *scheduleMocks) {
+	m := &scheduleMocks{
+		schedules:   new(MockInsightScheduleRepository),
+		runs:        new(MockInsightScheduleRunRepository),
+		surveys:     new(MockSurveyRepository),
+		submissions: new(MockSubmissionRepository),
+		insights:    new(MockInsightCreator),
+	}
+	return NewInsightScheduleService(m.schedules, m.runs, m.surveys, m.submissions, m.insights), m
+}
+
+func TestService_CreateSchedule(t *testing.T) {
+	survey := &models.Survey{ID: bson.NewObjectID()}
+	input := func(cron string) models.InsightScheduleInput {
+		return models.InsightScheduleInput{ContextType: models.EmployeeEngagementContext, Cron: cron, OnlyNewSubmissions: true}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		service, m := newScheduleService()
+		m.surveys.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		m.schedules.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		schedule, err := service.CreateSchedule(context.Background(), &models.CreateInsightScheduleRequest{
+			SurveyID:             survey.ID,
+			InsightScheduleInput: input("CRON_TZ=Asia/Hong_Kong 0 9 * * MON"),
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, schedule.Enabled)
+		assert.True(t, schedule.OnlyNewSubmissions)
+		// 09:00 on Monday in Hong Kong is 01:00 UTC.
+		assert.Equal(t, time.Monday, schedule.NextRunAt.Weekday())
+		assert.Equal(t, 1, schedule.NextRunAt.UTC().Hour())
+		m.schedules.AssertExpectations(t)
+	})
+
+	t.Run("InvalidCron", func(t *testing.T) {
+		service, m := newScheduleService()
+
+		_, err := service.CreateSchedule(context.Background(), &models.CreateInsightScheduleRequest{
+			SurveyID:             survey.ID,
+			InsightScheduleInput: input("every monday"),
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidInsightSchedule)
+		m.schedules.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("SurveyNotFound", func(t *testing.T) {
+		service, m := newScheduleService()
+		m.surveys.On("GetByID", mock.Anything, mock.Anything).Return(nil, errors.New("not found"))
+
+		_, err := service.CreateSchedule(context.Background(), &models.CreateInsightScheduleRequest{
+			SurveyID:             bson.NewObjectID(),
+			InsightScheduleInput: input("@weekly"),
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidInsightSchedule)
+	})
+
+	t.Run("FilterEndWithNewSubmissions", func(t *testing.T) {
+		service, m := newScheduleService()
+		m.surveys.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		to := time.Now()
+		req := input("@weekly")
+		req.Filter = &models.SubmissionFilter{To: &to}
+
+		_, err := service.CreateSchedule(context.Background(), &models.CreateInsightScheduleRequest{
+			SurveyID:             survey.ID,
+			InsightScheduleInput: req,
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidInsightSchedule)
+	})
+}
+
+func TestService_RunSchedule(t *testing.T) {
+	survey := &models.Survey{ID: bson.NewObjectID()}
+	lastRun := time.Date(2026, 10, 12, 1, 0, 0, 0, time.UTC)
+	schedule := func() *models.InsightSchedule {
+		last := lastRun
+		return &models.InsightSchedule{
+			ID:                 bson.NewObjectID(),
+			SurveyID:           survey.ID,
+			ContextType:        models.EmployeeEngagementContext,
+			Cron:               "@weekly",
+			OnlyNewSubmissions: true,
+			Enabled:            true,
+			LastRunAt:          &last,
+		}
+	}
+	// since matches a filter covering the submissions from lastRun until now
+	since := mock.MatchedBy(func(filter *models.SubmissionFilter) bool {
+		return filter.From.Equal(lastRun) && filter.To.After(lastRun)
+	})
+
+	t.Run("Created", func(t *testing.T) {
+		service, m := newScheduleService()
+		s := schedule()
+		var insightID bson.ObjectID
+		m.schedules.On("GetByID", mock.Anything, s.ID).Return(s, nil)
+		m.surveys.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		m.submissions.On("Count", mock.Anything, survey.ID, since).Return(int64(12), nil)
+		// The run is recorded with the ID of its insight before the insight is created.
+		m.runs.On("Create", mock.Anything, mock.MatchedBy(func(run *models.InsightScheduleRun) bool {
+			if run.Status != models.InsightScheduleRunPending || run.InsightID == nil || run.TaskID != "task-1" {
+				return false
+			}
+			insightID = *run.InsightID
+			return true
+		})).Return(nil)
+		m.insights.On("CreateInsight", mock.Anything, mock.MatchedBy(func(req *models.CreateInsightRequest) bool {
+			return req.ID == insightID && req.SurveyID == survey.ID && req.ContextType == models.EmployeeEngagementContext && req.Filter.From.Equal(lastRun)
+		})).Return(&models.Insight{ID: insightID}, nil)
+		m.runs.On("Update", mock.Anything, mock.MatchedBy(func(run *models.InsightScheduleRun) bool {
+			return run.Status == models.InsightScheduleRunCreated
+		})).Return(nil)
+		m.schedules.On("SetLastRunAt", mock.Anything, s.ID, mock.Anything).Return(nil)
+		m.runs.On("GetByTaskID", mock.Anything, s.ID, "task-1").Return(nil, mongo.ErrNoDocuments)
+
+		run, err := service.RunSchedule(context.Background(), s.ID, "task-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.InsightScheduleRunCreated, run.Status)
+		assert.Equal(t, insightID, *run.InsightID)
+		m.runs.AssertExpectations(t)
+		assert.Equal(t, lastRun, *run.From)
+		// The next window starts where this one ended.
+		m.schedules.AssertCalled(t, "SetLastRunAt", mock.Anything, s.ID, run.To)
+	})
+
+	t.Run("SkippedWithoutSubmissions", func(t *testing.T) {
+		service, m := newScheduleService()
+		s := schedule()
+		m.schedules.On("GetByID", mock.Anything, s.ID).Return(s, nil)
+		m.surveys.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		m.submissions.On("Count", mock.Anything, survey.ID, since).Return(int64(0), nil)
+		m.runs.On("Create", mock.Anything, mock.Anything).Return(nil)
+		m.schedules.On("SetLastRunAt", mock.Anything, s.ID, mock.Anything).Return(nil)
+
+		run, err := service.RunSchedule(context.Background(), s.ID, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.InsightScheduleRunSkipped, run.Status)
+		m.insights.AssertNotCalled(t, "CreateInsight")
+		m.schedules.AssertExpectations(t)
+	})
+
+	t.Run("FailedKeepsWindow", func(t *testing.T) {
+		service, m := newScheduleService()
+		s := schedule()
+		m.schedules.On("GetByID", mock.Anything, s.ID).Return(s, nil)
+		m.surveys.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		m.submissions.On("Count", mock.Anything, survey.ID, since).Return(int64(3), nil)
+		m.insights.On("CreateInsight", mock.Anything, mock.Anything).Return(nil, &LLMError{Kind: LLMErrorBudgetExceeded, Message: "token budget exhausted"})
+		m.runs.On("Create", mock.Anything, mock.Anything).Return(nil)
+		m.runs.On("Update", mock.Anything, mock.MatchedBy(func(run *models.InsightScheduleRun) bool {
+			return run.Status == models.InsightScheduleRunFailed && run.Error != "" && run.InsightID != nil
+		})).Return(nil)
+
+		run, err := service.RunSchedule(context.Background(), s.ID, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.InsightScheduleRunFailed, run.Status)
+		m.schedules.AssertNotCalled(t, "SetLastRunAt")
+	})
+
+	t.Run("FirstRunAllSubmissions", func(t *testing.T) {
+		service, m := newScheduleService()
+		s := schedule()
+		s.LastRunAt = nil
+		m.schedules.On("GetByID", mock.Anything, s.ID).Return(s, nil)
+		m.surveys.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		m.submissions.On("Count", mock.Anything, survey.ID, mock.MatchedBy(func(filter *models.SubmissionFilter) bool {
+			return filter.From == nil && filter.To != nil
+		})).Return(int64(0), nil)
+		m.runs.On("Create", mock.Anything, mock.Anything).Return(nil)
+		m.schedules.On("SetLastRunAt", mock.Anything, s.ID, mock.Anything).Return(nil)
+
+		run, err := service.RunSchedule(context.Background(), s.ID, "")
+
+		assert.NoError(t, err)
+		assert.Nil(t, run.From)
+	})
+
+	// pendingRun is a run whose attempt stopped after it was recorded
+	pendingRun := func(s *models.InsightSchedule) *models.InsightScheduleRun {
+		insightID := bson.NewObjectID()
+		to := lastRun.Add(7 * 24 * time.Hour)
+		return &models.InsightScheduleRun{
+			ID:         bson.NewObjectID(),
+			ScheduleID: s.ID,
+			TaskID:     "task-1",
+			Status:     models.InsightScheduleRunPending,
+			InsightID:  &insightID,
+			From:       &lastRun,
+			To:         to,
+			StartedAt:  to,
+		}
+	}
+
+	t.Run("RetryEnqueuesCreatedInsight", func(t *testing.T) {
+		service, m := newScheduleService()
+		s := schedule()
+		pending := pendingRun(s)
+		m.schedules.On("GetByID", mock.Anything, s.ID).Return(s, nil)
+		m.runs.On("GetByTaskID", mock.Anything, s.ID, "task-1").Return(pending, nil)
+		m.insights.On("EnqueueInsight", mock.Anything, *pending.InsightID).Return(nil)
+		m.runs.On("Update", mock.Anything, pending).Return(nil)
+		m.schedules.On("SetLastRunAt", mock.Anything, s.ID, pending.StartedAt).Return(nil)
+
+		run, err := service.RunSchedule(context.Background(), s.ID, "task-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.InsightScheduleRunCreated, run.Status)
+		m.insights.AssertNotCalled(t, "CreateInsight", mock.Anything, mock.Anything)
+		m.runs.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		m.submissions.AssertNotCalled(t, "Count", mock.Anything, mock.Anything, mock.Anything)
+		m.schedules.AssertExpectations(t)
+	})
+
+	t.Run("RetryCreatesMissingInsight", func(t *testing.T) {
+		service, m := newScheduleService()
+		s := schedule()
+		pending := pendingRun(s)
+		m.schedules.On("GetByID", mock.Anything, s.ID).Return(s, nil)
+		m.runs.On("GetByTaskID", mock.Anything, s.ID, "task-1").Return(pending, nil)
+		m.insights.On("EnqueueInsight", mock.Anything, *pending.InsightID).Return(mongo.ErrNoDocuments)
+		// The insight keeps the ID and window recorded with the run.
+		m.insights.On("CreateInsight", mock.Anything, mock.MatchedBy(func(req *models.CreateInsightRequest) bool {
+			return req.ID == *pending.InsightID && req.Filter.From.Equal(lastRun) && req.Filter.To.Equal(pending.To)
+		})).Return(&models.Insight{ID: *pending.InsightID}, nil)
+		m.runs.On("Update", mock.Anything, pending).Return(nil)
+		m.schedules.On("SetLastRunAt", mock.Anything, s.ID, pending.StartedAt).Return(nil)
+
+		run, err := service.RunSchedule(context.Background(), s.ID, "task-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.InsightScheduleRunCreated, run.Status)
+		m.insights.AssertExpectations(t)
+	})
+
+	t.Run("EnqueueFailureRetriesTask", func(t *testing.T) {
+		service, m := newScheduleService()
+		s := schedule()
+		m.schedules.On("GetByID", mock.Anything, s.ID).Return(s, nil)
+		m.runs.On("GetByTaskID", mock.Anything, s.ID, "task-1").Return(nil, mongo.ErrNoDocuments)
+		m.surveys.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		m.submissions.On("Count", mock.Anything, survey.ID, since).Return(int64(3), nil)
+		m.runs.On("Create", mock.Anything, mock.Anything).Return(nil)
+		m.insights.On("CreateInsight", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: connection refused", ErrInsightNotEnqueued))
+
+		_, err := service.RunSchedule(context.Background(), s.ID, "task-1")
+
+		// The run stays PENDING, so the retried task enqueues the insight.
+		assert.ErrorIs(t, err, ErrInsightNotEnqueued)
+		m.runs.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		m.schedules.AssertNotCalled(t, "SetLastRunAt", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("RetryFinishesRecordedRun", func(t *testing.T) {
+		service, m := newScheduleService()
+		s := schedule()
+		created := pendingRun(s)
+		created.Status = models.InsightScheduleRunCreated
+		m.schedules.On("GetByID", mock.Anything, s.ID).Return(s, nil)
+		m.runs.On("GetByTaskID", mock.Anything, s.ID, "task-1").Return(created, nil)
+		m.schedules.On("SetLastRunAt", mock.Anything, s.ID, created.StartedAt).Return(nil)
+
+		run, err := service.RunSchedule(context.Background(), s.ID, "task-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, created, run)
+		m.insights.AssertNotCalled(t, "EnqueueInsight", mock.Anything, mock.Anything)
+		m.insights.AssertNotCalled(t, "CreateInsight", mock.Anything, mock.Anything)
+		m.runs.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		service, m := newScheduleService()
+		s := schedule()
+		s.Enabled = false
+		m.schedules.On("GetByID", mock.Anything, s.ID).Return(s, nil)
+
+		run, err := service.RunSchedule(context.Background(), s.ID, "")
+
+		assert.NoError(t, err)
+		assert.Nil(t, run)
+		m.runs.AssertNotCalled(t, "Create")
+	})
+}
+
+func TestScheduleWindow(t *testing.T) {
+	now := time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2026, 9, 28, 1, 0, 0, 0, time.UTC)
+	filter := &models.SubmissionFilter{From: &start, Metadata: map[string]string{"team": "ops"}}
+
+	// Without OnlyNewSubmissions the schedule's filter is used as is.
+	assert.Same(t, filter, scheduleWindow(&models.InsightSchedule{Filter: filter, LastRunAt: &last}, now))
+
+	// The later of the filter start and the last run opens the window.
+	window := scheduleWindow(&models.InsightSchedule{Filter: filter, OnlyNewSubmissions: true, LastRunAt: &last}, now)
+	assert.Equal(t, start, *window.From)
+	assert.Equal(t, now, *window.To)
+	assert.Equal(t, "ops", window.Metadata["team"])
+	assert.Nil(t, filter.To)
+}
+
+func TestService_GetConfigs(t *testing.T) {
+	service, m := newScheduleService()
+	id := bson.NewObjectID()
+	m.schedules.On("ListEnabled", mock.Anything).Return([]*models.InsightSchedule{{ID: id, Cron: "0 9 * * MON", Enabled: true}}, nil)
+
+	configs, err := service.GetConfigs()
+
+	assert.NoError(t, err)
+	assert.Len(t, configs, 1)
+	assert.Equal(t, "0 9 * * MON", configs[0].Cronspec)
+	assert.Equal(t, TypeRunInsightSchedule, configs[0].Task.Type())
+	scheduleID, err := parseRunInsightSchedulePayload(configs[0].Task)
+	assert.NoError(t, err)
+	assert.Equal(t, id, scheduleID)
+	assert.Contains(t, configs[0].Opts, asynq.Queue(insightQueue))
+}
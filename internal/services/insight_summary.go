@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"osp/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// summarizeInsight reduces the batch summaries level by level: batches of a question
+// into a question summary, questions of a section into a section summary and the top
+// level into the overall analysis. A level with a single child reuses its summary
+// instead of calling the LLM again. Any level whose input exceeds the token budget is
+// first condensed chunk by chunk, so the prompt size no longer grows with the survey.
+func (s *InsightService) summarizeInsight(ctx context.Context, insight *models.Insight) ([]models.InsightSummary, string, error) {
+	sizing := s.batchSizing(insight.ContextType)
+	if insight.BatchSizing != nil {
+		sizing = *insight.BatchSizing
+	}
+
+	// Question level
+	questions := []*models.InsightSummary{}
+	byID := make(map[string]*models.InsightSummary)
+	sections := make(map[string]string) // question summary ID -> section
+	texts := make(map[string][]string)
+	failed := 0
+	for _, batch := range insight.Batches {
+		if batch.Summary == nil {
+			failed++
+			continue
+		}
+		id := questionSummaryID(batch.Question.ID)
+		question, ok := byID[id]
+		if !ok {
+			questionID := batch.Question.ID
+			question = &models.InsightSummary{
+				ID:         id,
+				Level:      models.InsightSummaryQuestion,
+				Title:      batch.Question.Text,
+				QuestionID: &questionID,
+			}
+			questions = append(questions, question)
+			byID[id] = question
+			sections[id] = batch.Question.Section
+		}
+		// Citation numbers only mean something within their batch.
+		texts[id] = append(texts[id], stripCitations(*batch.Summary))
+		question.BatchNumbers = append(question.BatchNumbers, batch.BatchNumber)
+	}
+	for _, question := range questions {
+		summary, err := s.reduceSummaries(ctx, insight, sizing, "the question \""+question.Title+"\"", texts[question.ID])
+		if err != nil {
+			return nil, "", err
+		}
+		question.Summary = summary
+	}
+
+	// Section level, only when the survey groups its questions into sections
+	top := questions
+	var sectionSummaries []*models.InsightSummary
+	if hasSections(sections) {
+		children := make(map[string][]*models.InsightSummary)
+		for _, question := range questions {
+			section := sections[question.ID]
+			if section == "" {
+				section = "Other"
+			}
+			id := "section:" + section
+			if _, ok := children[id]; !ok {
+				sectionSummaries = append(sectionSummaries, &models.InsightSummary{
+					ID:    id,
+					Level: models.InsightSummarySection,
+					Title: section,
+				})
+			}
+			question.ParentID = id
+			children[id] = append(children[id], question)
+		}
+		for _, section := range sectionSummaries {
+			if len(children[section.ID]) == 1 {
+				section.Summary = children[section.ID][0].Summary
+				continue
+			}
+			sectionTexts := make([]string, 0, len(children[section.ID]))
+			for _, question := range children[section.ID] {
+				sectionTexts = append(sectionTexts, fmt.Sprintf("Question: %s\n%s", question.Title, question.Summary))
+			}
+			summary, err := s.reduceSummaries(ctx, insight, sizing, "the section \""+section.Title+"\"", sectionTexts)
+			if err != nil {
+				return nil, "", err
+			}
+			section.Summary = summary
+		}
+		top = sectionSummaries
+	}
+
+	// Overall analysis
+	parts := make([]string, 0, len(top))
+	for _, summary := range top {
+		label := "Question"
+		if summary.Level == models.InsightSummarySection {
+			label = "Section"
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s\n%s", label, summary.Title, summary.Summary))
+	}
+	parts, err := s.condenseSummaries(ctx, insight, sizing, "the whole survey", parts)
+	if err != nil {
+		return nil, "", err
+	}
+	analysis, err := s.generateAnalysis(ctx, insight, parts, failed)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result := make([]models.InsightSummary, 0, len(sectionSummaries)+len(questions))
+	for _, summary := range sectionSummaries {
+		result = append(result, *summary)
+	}
+	for _, summary := range questions {
+		result = append(result, *summary)
+	}
+	return result, analysis, nil
+}
+
+func hasSections(sections map[string]string) bool {
+	for _, section := range sections {
+		if section != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// reduceSummaries merges texts into one summary; a single text is returned as is.
+func (s *InsightService) reduceSummaries(ctx context.Context, insight *models.Insight, sizing models.BatchSizing, scope string, texts []string) (string, error) {
+	if len(texts) == 1 {
+		return texts[0], nil
+	}
+	texts, err := s.condenseSummaries(ctx, insight, sizing, scope, texts)
+	if err != nil {
+		return "", err
+	}
+	if len(texts) == 1 {
+		return texts[0], nil
+	}
+	return s.mergeSummaries(ctx, insight, scope, texts)
+}
+
+// condenseSummaries summarizes chunks of texts until they fit in the answer budget of
+// one request. Every chunk holds at least two texts, so each round shrinks the list.
+func (s *InsightService) condenseSummaries(ctx context.Context, insight *models.Insight, sizing models.BatchSizing, scope string, texts []string) ([]string, error) {
+	for len(texts) > 1 && s.countTokens(texts) > sizing.AnswerBudget {
+		chunks := s.chunkByTokens(texts, sizing.AnswerBudget)
+		condensed := make([]string, 0, len(chunks))
+		for _, chunk := range chunks {
+			if len(chunk) == 1 {
+				condensed = append(condensed, chunk[0])
+				continue
+			}
+			summary, err := s.mergeSummaries(ctx, insight, scope, chunk)
+			if err != nil {
+				return nil, err
+			}
+			condensed = append(condensed, summary)
+		}
+		texts = condensed
+	}
+	return texts, nil
+}
+
+func (s *InsightService) countTokens(texts []string) int {
+	tokens := 0
+	for _, text := range texts {
+		tokens += s.tokenizer.Count(text) + answerSeparatorTokens
+	}
+	return tokens
+}
+
+// chunkByTokens groups consecutive texts into chunks within budget, putting at least
+// two texts in a chunk whenever possible.
+func (s *InsightService) chunkByTokens(texts []string, budget int) [][]string {
+	var chunks [][]string
+	var current []string
+	tokens := 0
+	for _, text := range texts {
+		t := s.tokenizer.Count(text) + answerSeparatorTokens
+		if len(current) >= 2 && tokens+t > budget {
+			chunks = append(chunks, current)
+			current, tokens = nil, 0
+		}
+		current = append(current, text)
+		tokens += t
+	}
+	if len(current) == 1 && len(chunks) > 0 {
+		last := len(chunks) - 1
+		chunks[last] = append(chunks[last], current[0])
+	} else if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+func (s *InsightService) mergeSummaries(ctx context.Context, insight *models.Insight, scope string, texts []string) (string, error) {
+	reqBody := models.ChatCompletionRequest{
+		Messages: []models.ChatCompletionMessage{
+			{
+				Role:    "system",
+				Content: fmt.Sprintf("You are a helpful assistant. Combine the following summaries of survey responses about %s into one summary in the context of %s. Keep the recurring themes and notable outliers.", scope, insight.ContextType),
+			},
+			{
+				Role:    "user",
+				Content: strings.Join(texts, "\n\n"),
+			},
+		},
+		Temperature: 0.5,
+		TopP:        1.0,
+		MaxTokens:   summaryMaxTokens,
+		Model:       insightModel,
+	}
+
+	return s.completeSummary(ctx, reqBody, insightRequestOptions(insight, "reduce:"+scope))
+}
+
+func (s *InsightService) generateAnalysis(ctx context.Context, insight *models.Insight, parts []string, failedBatches int) (string, error) {
+	meta := "Here are the summaries of the different parts of the survey:\n\n" + strings.Join(parts, "\n\n")
+	if failedBatches > 0 {
+		meta += fmt.Sprintf("\n\nNote: %d batches of answers could not be summarized and are not included.", failedBatches)
+	}
+
+	reqBody := models.ChatCompletionRequest{
+		Messages: []models.ChatCompletionMessage{
+			{
+				Role:    "system",
+				Content: fmt.Sprintf("You are a helpful assistant. Analyze survey responses in the context of %s.", insight.ContextType),
+			},
+			{
+				Role:    "user",
+				Content: meta,
+			},
+		},
+		Temperature: 0.5,
+		TopP:        1.0,
+		MaxTokens:   summaryMaxTokens,
+		Model:       insightModel,
+	}
+
+	return s.completeSummary(ctx, reqBody, insightRequestOptions(insight, "meta"))
+}
+
+func (s *InsightService) completeSummary(ctx context.Context, reqBody models.ChatCompletionRequest, opts *models.ChatCompletionOptions) (string, error) {
+	resp, err := s.chatCompletionService.NewRequest(ctx, reqBody, opts)
+	if err != nil {
+		return "", err
+	}
+	if resp == nil {
+		return "", fmt.Errorf("empty response")
+	}
+	return *resp, nil
+}
+
+// insightRequestOptions attributes a chat completion request to the insight and its survey
+func insightRequestOptions(insight *models.Insight, step string) *models.ChatCompletionOptions {
+	return &models.ChatCompletionOptions{
+		Reference:   fmt.Sprintf("insight:%s %s", insight.ID.Hex(), step),
+		InsightID:   &insight.ID,
+		SurveyID:    &insight.SurveyID,
+		BypassCache: insight.BypassCache,
+	}
+}
+
+func questionSummaryID(questionID bson.ObjectID) string {
+	return "question:" + questionID.Hex()
+}
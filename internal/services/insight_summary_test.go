@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"osp/internal/models"
+	"osp/internal/tokenizer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func summarizedBatch(number int, question models.Question, summary string) models.InsightBatch {
+	return models.InsightBatch{BatchNumber: number, Question: question, Summary: &summary}
+}
+
+func TestService_SummarizeInsight(t *testing.T) {
+	t.Run("Sections", func(t *testing.T) {
+		mockChat := new(MockChatCompletionService)
+		service := NewInsightService(new(MockInsightRepository), new(MockSurveyRepository), new(MockSubmissionRepository), mockChat, new(MockJobEnqueuer), nil, nil, nil, InsightSettings{})
+
+		pace := models.Question{ID: bson.NewObjectID(), Text: "Pace", Section: "Teaching"}
+		clarity := models.Question{ID: bson.NewObjectID(), Text: "Clarity", Section: "Teaching"}
+		rooms := models.Question{ID: bson.NewObjectID(), Text: "Rooms", Section: "Facilities"}
+		other := models.Question{ID: bson.NewObjectID(), Text: "Anything else"}
+		failed := "llm down"
+		insight := &models.Insight{
+			ID: bson.NewObjectID(),
+			Batches: []models.InsightBatch{
+				summarizedBatch(1, pace, "too fast"),
+				summarizedBatch(2, pace, "fast"),
+				summarizedBatch(3, clarity, "clear"),
+				summarizedBatch(4, rooms, "too cold"),
+				{BatchNumber: 5, Question: other, ErrorLog: &failed},
+			},
+		}
+		merged := "merged"
+		mockChat.On("NewRequest", mock.Anything, mock.Anything, mock.Anything).Return(&merged, nil)
+
+		summaries, analysis, err := service.summarizeInsight(context.Background(), insight)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "merged", analysis)
+		// pace batches, teaching section and the overall analysis; the facilities
+		// section has a single question and reuses its summary
+		mockChat.AssertNumberOfCalls(t, "NewRequest", 3)
+		assert.Len(t, summaries, 5)
+
+		assert.Equal(t, "section:Teaching", summaries[0].ID)
+		assert.Equal(t, models.InsightSummarySection, summaries[0].Level)
+		assert.Equal(t, "section:Facilities", summaries[1].ID)
+		assert.Equal(t, "too cold", summaries[1].Summary)
+
+		assert.Equal(t, models.InsightSummaryQuestion, summaries[2].Level)
+		assert.Equal(t, pace.ID, *summaries[2].QuestionID)
+		assert.Equal(t, []int{1, 2}, summaries[2].BatchNumbers)
+		assert.Equal(t, "section:Teaching", summaries[2].ParentID)
+		assert.Equal(t, "clear", summaries[3].Summary)
+		assert.Equal(t, "section:Facilities", summaries[4].ParentID)
+	})
+
+	t.Run("CondensesOverBudget", func(t *testing.T) {
+		mockChat := new(MockChatCompletionService)
+		service := NewInsightService(new(MockInsightRepository), new(MockSurveyRepository), new(MockSubmissionRepository), mockChat, new(MockJobEnqueuer), nil, nil, nil, InsightSettings{})
+
+		insight := &models.Insight{
+			ID:          bson.NewObjectID(),
+			BatchSizing: &models.BatchSizing{AnswerBudget: 40},
+		}
+		for i := 1; i <= 8; i++ {
+			question := models.Question{ID: bson.NewObjectID(), Text: "Question"}
+			insight.Batches = append(insight.Batches, summarizedBatch(i, question, strings.Repeat("theme ", 8)))
+		}
+
+		var prompts []string
+		merged := "merged themes"
+		mockChat.On("NewRequest", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			prompts = append(prompts, args.Get(1).(models.ChatCompletionRequest).Messages[1].Content)
+		}).Return(&merged, nil)
+
+		_, analysis, err := service.summarizeInsight(context.Background(), insight)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "merged themes", analysis)
+		assert.Greater(t, len(prompts), 2)
+		estimator := tokenizer.NewEstimator()
+		for _, prompt := range prompts[:len(prompts)-1] {
+			assert.LessOrEqual(t, estimator.Count(prompt), 2*40)
+		}
+		assert.NotContains(t, prompts[len(prompts)-1], "theme theme")
+	})
+}
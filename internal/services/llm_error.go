@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LLMErrorKind classifies a failed chat completion request
+type LLMErrorKind string
+
+const (
+	LLMErrorRateLimited    LLMErrorKind = "RATE_LIMITED"    // 429, retry after the given delay
+	LLMErrorServer         LLMErrorKind = "SERVER_ERROR"    // 5xx
+	LLMErrorTimeout        LLMErrorKind = "TIMEOUT"         // no response in time
+	LLMErrorCircuitOpen    LLMErrorKind = "CIRCUIT_OPEN"    // requests are paused after repeated failures
+	LLMErrorUnauthorized   LLMErrorKind = "UNAUTHORIZED"    // 401 / 403
+	LLMErrorInvalidRequest LLMErrorKind = "INVALID_REQUEST" // other 4xx, e.g. content filtered or context too long
+	LLMErrorBudgetExceeded LLMErrorKind = "BUDGET_EXCEEDED" // a token budget is exhausted for the current period
+)
+
+// LLMError is returned by the chat completion service for provider failures
+type LLMError struct {
+	Kind       LLMErrorKind
+	StatusCode int           // HTTP status, 0 when no response was received
+	RetryAfter time.Duration // delay requested by the provider or the circuit breaker
+	Message    string
+	Err        error
+}
+
+func (e *LLMError) Error() string {
+	msg := fmt.Sprintf("llm request failed (%s", e.Kind)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(", status=%d", e.StatusCode)
+	}
+	msg += ")"
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *LLMError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the same request may succeed later
+func (e *LLMError) Retryable() bool {
+	switch e.Kind {
+	case LLMErrorRateLimited, LLMErrorServer, LLMErrorTimeout, LLMErrorCircuitOpen:
+		return true
+	}
+	return false
+}
+
+// maxErrorBodyLength caps the provider response kept in error messages
+const maxErrorBodyLength = 500
+
+// classifyResponse returns an LLMError for a non-2xx provider response
+func classifyResponse(statusCode int, header http.Header, body []byte) *LLMError {
+	err := &LLMError{StatusCode: statusCode, Message: truncate(string(body), maxErrorBodyLength)}
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		err.Kind = LLMErrorRateLimited
+		err.RetryAfter = parseRetryAfter(header.Get("Retry-After"), time.Now())
+	case statusCode == http.StatusRequestTimeout || statusCode == http.StatusGatewayTimeout:
+		err.Kind = LLMErrorTimeout
+	case statusCode >= 500:
+		err.Kind = LLMErrorServer
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		err.Kind = LLMErrorUnauthorized
+	default:
+		err.Kind = LLMErrorInvalidRequest
+	}
+	return err
+}
+
+// classifyTransportError wraps errors of requests that got no response. The caller's
+// own cancellation is returned as is, since it is not a provider failure.
+func classifyTransportError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &LLMError{Kind: LLMErrorTimeout, Err: err}
+	}
+	// Connection resets and refused connections are transient as well.
+	return &LLMError{Kind: LLMErrorServer, Err: err}
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// IsPermanentError reports whether retrying err cannot succeed: every LLM error in it
+// is permanent and nothing else, such as a database error, failed.
+func IsPermanentError(err error) bool {
+	switch e := err.(type) {
+	case *LLMError:
+		return !e.Retryable()
+	case interface{ Unwrap() []error }:
+		errs := e.Unwrap()
+		for _, err := range errs {
+			if !IsPermanentError(err) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	case interface{ Unwrap() error }:
+		return IsPermanentError(e.Unwrap())
+	}
+	return false
+}
+
+// RetryAfter returns the delay requested by the provider or the circuit breaker, if any
+func RetryAfter(err error) (time.Duration, bool) {
+	var llmErr *LLMError
+	if errors.As(err, &llmErr) && llmErr.RetryAfter > 0 {
+		return llmErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// truncate shortens s to at most n bytes, marking the cut
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	// Drop a rune cut in half so the result stays valid UTF-8.
+	return strings.ToValidUTF8(s[:n], "") + "..."
+}
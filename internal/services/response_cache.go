@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"osp/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ResponseCache stores chat completion responses by a hash of their request
+type ResponseCache interface {
+	Get(ctx context.Context, key string) (*models.ChatCompletionResponse, bool)
+	Set(ctx context.Context, key string, resp *models.ChatCompletionResponse)
+	// Count records the outcome of a request for the cache statistics
+	Count(ctx context.Context, outcome CacheOutcome)
+}
+
+// CacheStatsReader sums the outcomes counted by a ResponseCache
+type CacheStatsReader interface {
+	// CacheStats returns the counts of the UTC days from From (inclusive) to To (exclusive)
+	CacheStats(ctx context.Context, from, to time.Time) (*models.CacheStats, error)
+}
+
+// CacheOutcome is how the cache took part in a chat completion request
+type CacheOutcome string
+
+const (
+	CacheHit    CacheOutcome = "hit"
+	CacheMiss   CacheOutcome = "miss"   // sent to the provider
+	CacheBypass CacheOutcome = "bypass" // sent to the provider without looking the request up
+)
+
+// cacheStatsRetention is how long the daily counters of the cache outcomes are kept
+const cacheStatsRetention = 400 * 24 * time.Hour
+
+// responseCacheKey addresses a response by the SHA-256 of the full request body, so any
+// change to the model, messages or sampling parameters is a different entry.
+func responseCacheKey(jsonData []byte) string {
+	sum := sha256.Sum256(jsonData)
+	return hex.EncodeToString(sum[:])
+}
+
+// RedisResponseCache keeps responses in Redis for TTL. Like the circuit breaker it fails
+// open: Redis errors are logged and treated as misses.
+type RedisResponseCache struct {
+	client redis.UniversalClient
+	TTL    time.Duration
+}
+
+func NewRedisResponseCache(client redis.UniversalClient, ttl time.Duration) *RedisResponseCache {
+	return &RedisResponseCache{
+		client: client,
+		TTL:    ttl,
+	}
+}
+
+func (c *RedisResponseCache) Get(ctx context.Context, key string) (*models.ChatCompletionResponse, bool) {
+	payload, err := c.client.Get(ctx, "llm-cache:"+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("llm cache read failed: %v", err)
+		}
+		return nil, false
+	}
+	var resp models.ChatCompletionResponse
+	if err := json.Unmarshal(payload, &resp); err != nil {
+		log.Printf("llm cache entry %s is invalid: %v", key, err)
+		return nil, false
+	}
+	return &resp, true
+}
+
+func (c *RedisResponseCache) Set(ctx context.Context, key string, resp *models.ChatCompletionResponse) {
+	payload, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	if err := c.client.Set(ctx, "llm-cache:"+key, payload, c.TTL).Err(); err != nil {
+		log.Printf("llm cache write failed: %v", err)
+	}
+}
+
+// Count increments the counter of the outcome in a Redis hash per UTC day
+func (c *RedisResponseCache) Count(ctx context.Context, outcome CacheOutcome) {
+	key := cacheStatsKey(time.Now())
+	pipe := c.client.TxPipeline()
+	pipe.HIncrBy(ctx, key, string(outcome), 1)
+	pipe.Expire(ctx, key, cacheStatsRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("llm cache counter update failed: %v", err)
+	}
+}
+
+func (c *RedisResponseCache) CacheStats(ctx context.Context, from, to time.Time) (*models.CacheStats, error) {
+	pipe := c.client.Pipeline()
+	var counts []*redis.MapStringStringCmd
+	for day := from.UTC().Truncate(24 * time.Hour); day.Before(to); day = day.AddDate(0, 0, 1) {
+		counts = append(counts, pipe.HGetAll(ctx, cacheStatsKey(day)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	var stats models.CacheStats
+	for _, cmd := range counts {
+		var day struct {
+			Hit    int64 `redis:"hit"`
+			Miss   int64 `redis:"miss"`
+			Bypass int64 `redis:"bypass"`
+		}
+		if err := cmd.Scan(&day); err != nil {
+			return nil, err
+		}
+		stats.Hits += day.Hit
+		stats.Misses += day.Miss
+		stats.Bypassed += day.Bypass
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+	return &stats, nil
+}
+
+// cacheStatsKey is the Redis hash counting the cache outcomes of the UTC day of t
+func cacheStatsKey(t time.Time) string {
+	return "llm-cache-stats:" + t.UTC().Format(time.DateOnly)
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"osp/internal/models"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockResponseCache struct {
+	mock.Mock
+}
+
+func (m *MockResponseCache) Get(ctx context.Context, key string) (*models.ChatCompletionResponse, bool) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1)
+	}
+	return args.Get(0).(*models.ChatCompletionResponse), args.Bool(1)
+}
+
+func (m *MockResponseCache) Set(ctx context.Context, key string, resp *models.ChatCompletionResponse) {
+	m.Called(ctx, key, resp)
+}
+
+func (m *MockResponseCache) Count(ctx context.Context, outcome CacheOutcome) {
+	m.Called(ctx, outcome)
+}
+
+type MockCacheStatsReader struct {
+	mock.Mock
+}
+
+func (m *MockCacheStatsReader) CacheStats(ctx context.Context, from, to time.Time) (*models.CacheStats, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CacheStats), args.Error(1)
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	request := models.ChatCompletionRequest{
+		Messages:    []models.ChatCompletionMessage{{Role: "user", Content: "Answers: [Great]"}},
+		Temperature: 0.5,
+		TopP:        1.0,
+		MaxTokens:   800,
+		Model:       insightModel,
+	}
+	key := func(r models.ChatCompletionRequest) string {
+		jsonData, _ := json.Marshal(r)
+		return responseCacheKey(jsonData)
+	}
+
+	assert.Equal(t, key(request), key(request))
+	assert.Len(t, key(request), 64)
+
+	changed := request
+	changed.Temperature = 0.2
+	assert.NotEqual(t, key(request), key(changed))
+
+	changed = request
+	changed.Messages = []models.ChatCompletionMessage{{Role: "user", Content: "Answers: [Good]"}}
+	assert.NotEqual(t, key(request), key(changed))
+}
+
+func TestRedisResponseCache_FailsOpen(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+	cache := NewRedisResponseCache(client, time.Hour)
+
+	cache.Set(context.Background(), "key", &models.ChatCompletionResponse{})
+	cache.Count(context.Background(), CacheMiss)
+	resp, ok := cache.Get(context.Background(), "key")
+
+	assert.False(t, ok)
+	assert.Nil(t, resp)
+	_, err := cache.CacheStats(context.Background(), time.Now().AddDate(0, 0, -1), time.Now())
+	assert.Error(t, err)
+}
+
+func TestCacheStatsKey(t *testing.T) {
+	hongKong := time.FixedZone("HKT", 8*60*60)
+
+	// Counters are kept per UTC day.
+	assert.Equal(t, "llm-cache-stats:2026-03-14", cacheStatsKey(time.Date(2026, 3, 15, 7, 0, 0, 0, hongKong)))
+	assert.Equal(t, "llm-cache-stats:2026-03-15", cacheStatsKey(time.Date(2026, 3, 15, 9, 0, 0, 0, hongKong)))
+}
+
+func TestChatCompletionService_NewRequest_CacheCounters(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "token")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Fresh"}}]}`)
+	}))
+	defer server.Close()
+	request := models.ChatCompletionRequest{Model: insightModel, Messages: []models.ChatCompletionMessage{{Role: "user", Content: "Hi"}}}
+	newService := func(budget BudgetGuard) (*ChatCompletionService, *MockResponseCache) {
+		mockLogRepo := new(MockChatCompletionLogRepository)
+		mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockCache := new(MockResponseCache)
+		mockCache.On("Count", mock.Anything, mock.Anything).Return()
+		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything).Return()
+		service := NewChatCompletionService(mockLogRepo, nil, budget, mockCache, nil)
+		service.url = server.URL
+		return service, mockCache
+	}
+
+	t.Run("Hit", func(t *testing.T) {
+		service, mockCache := newService(nil)
+		mockCache.On("Get", mock.Anything, mock.Anything).Return(&models.ChatCompletionResponse{
+			Choices: []models.ChatCompletionChoice{{Message: models.ChatCompletionMessage{Role: "assistant", Content: "Cached"}}},
+		}, true)
+
+		content, err := service.NewRequest(context.Background(), request, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Cached", *content)
+		mockCache.AssertCalled(t, "Count", mock.Anything, CacheHit)
+		mockCache.AssertNumberOfCalls(t, "Count", 1)
+	})
+
+	t.Run("Miss", func(t *testing.T) {
+		service, mockCache := newService(nil)
+		mockCache.On("Get", mock.Anything, mock.Anything).Return(nil, false)
+
+		content, err := service.NewRequest(context.Background(), request, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Fresh", *content)
+		mockCache.AssertCalled(t, "Count", mock.Anything, CacheMiss)
+		mockCache.AssertNumberOfCalls(t, "Count", 1)
+	})
+
+	t.Run("Bypass", func(t *testing.T) {
+		service, mockCache := newService(nil)
+
+		_, err := service.NewRequest(context.Background(), request, &models.ChatCompletionOptions{BypassCache: true})
+
+		assert.NoError(t, err)
+		mockCache.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+		mockCache.AssertCalled(t, "Count", mock.Anything, CacheBypass)
+		mockCache.AssertNumberOfCalls(t, "Count", 1)
+	})
+
+	t.Run("BudgetExceeded", func(t *testing.T) {
+		budget := new(MockBudgetService)
+		budget.On("Allow", mock.Anything, mock.Anything).Return(&LLMError{Kind: LLMErrorBudgetExceeded})
+		service, mockCache := newService(budget)
+		mockCache.On("Get", mock.Anything, mock.Anything).Return(nil, false)
+
+		_, err := service.NewRequest(context.Background(), request, nil)
+
+		// Nothing reached the provider, so the lookup is not counted as a miss.
+		assert.Error(t, err)
+		mockCache.AssertNotCalled(t, "Count", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"osp/internal/models"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestSentimentService_AnalyzeSubmission(t *testing.T) {
+	textID, choiceID, emptyID := bson.NewObjectID(), bson.NewObjectID(), bson.NewObjectID()
+	survey := &models.Survey{
+		ID: bson.NewObjectID(),
+		Questions: []models.Question{
+			{ID: textID, Type: models.QuestionTypeTextbox},
+			{ID: choiceID, Type: models.QuestionTypeMultipleChoice},
+			{ID: emptyID, Type: models.QuestionTypeTextbox},
+		},
+		Redaction: &models.RedactionSettings{Enabled: true},
+	}
+	submission := &models.Submission{
+		ID:       bson.NewObjectID(),
+		SurveyID: survey.ID,
+		Responses: []models.SubmissionResponse{
+			{QuestionID: textID, Answer: "Great course, mail me at amy@example.com"},
+			{QuestionID: choiceID, Answer: "Good"},
+			{QuestionID: emptyID, Answer: " "},
+		},
+	}
+	setup := func(chat IChatCompletionService) (*SentimentService, *MockSubmissionRepository) {
+		mockSubmissionRepo := new(MockSubmissionRepository)
+		mockSurveyRepo := new(MockSurveyRepository)
+		mockSubmissionRepo.On("GetByID", mock.Anything, submission.ID).Return(submission, nil)
+		mockSurveyRepo.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		return NewSentimentService(mockSubmissionRepo, mockSurveyRepo, chat, nil, nil), mockSubmissionRepo
+	}
+
+	t.Run("LLM", func(t *testing.T) {
+		mockChat := new(MockChatCompletionService)
+		service, mockSubmissionRepo := setup(mockChat)
+
+		content := "```json\n[{\"label\": \"POSITIVE\", \"score\": 0.9}]\n```"
+		mockChat.On("NewRequest", mock.Anything, mock.MatchedBy(func(req models.ChatCompletionRequest) bool {
+			// Only the redacted textbox answer is sent.
+			return req.Messages[1].Content == `<answers>{"1":"Great course, mail me at [EMAIL_1]"}</answers>`
+		}), mock.MatchedBy(func(opts *models.ChatCompletionOptions) bool {
+			return opts.Reference == "sentiment:"+submission.ID.Hex() && *opts.SurveyID == survey.ID
+		})).Return(&content, nil)
+		mockSubmissionRepo.On("UpdateSentiments", mock.Anything, submission.ID, map[int]*models.Sentiment{
+			0: {Label: models.SentimentPositive, Score: 0.9, Analyzer: "llm"},
+		}).Return(nil)
+
+		err := service.AnalyzeSubmission(context.Background(), submission.ID)
+
+		assert.NoError(t, err)
+		mockChat.AssertExpectations(t)
+		mockSubmissionRepo.AssertExpectations(t)
+	})
+
+	t.Run("FallsBackToLexicon", func(t *testing.T) {
+		mockChat := new(MockChatCompletionService)
+		service, mockSubmissionRepo := setup(mockChat)
+
+		mockChat.On("NewRequest", mock.Anything, mock.Anything, mock.Anything).Return(nil, &LLMError{Kind: LLMErrorBudgetExceeded})
+		mockSubmissionRepo.On("UpdateSentiments", mock.Anything, submission.ID, mock.MatchedBy(func(sentiments map[int]*models.Sentiment) bool {
+			return len(sentiments) == 1 && sentiments[0].Label == models.SentimentPositive && sentiments[0].Analyzer == "lexicon-v1"
+		})).Return(nil)
+
+		err := service.AnalyzeSubmission(context.Background(), submission.ID)
+
+		assert.NoError(t, err)
+		mockSubmissionRepo.AssertExpectations(t)
+	})
+
+	t.Run("LexiconOnly", func(t *testing.T) {
+		service, mockSubmissionRepo := setup(nil)
+
+		mockSubmissionRepo.On("UpdateSentiments", mock.Anything, submission.ID, mock.MatchedBy(func(sentiments map[int]*models.Sentiment) bool {
+			return sentiments[0].Analyzer == "lexicon-v1"
+		})).Return(nil)
+
+		err := service.AnalyzeSubmission(context.Background(), submission.ID)
+
+		assert.NoError(t, err)
+		mockSubmissionRepo.AssertExpectations(t)
+	})
+}
+
+func TestSentimentService_EnqueueSubmission(t *testing.T) {
+	mockEnqueuer := new(MockJobEnqueuer)
+	service := NewSentimentService(nil, nil, nil, mockEnqueuer, nil)
+	submissionID := bson.NewObjectID()
+
+	mockEnqueuer.On("Enqueue", mock.MatchedBy(func(task *asynq.Task) bool {
+		id, err := parseAnalyzeSentimentPayload(task)
+		return task.Type() == TypeAnalyzeSentiment && err == nil && id == submissionID
+	}), mock.Anything).Return(&asynq.TaskInfo{}, nil)
+
+	assert.NoError(t, service.EnqueueSubmission(submissionID))
+	mockEnqueuer.AssertExpectations(t)
+}
+
+func TestParseSentimentResults(t *testing.T) {
+	results, err := parseSentimentResults(`[{"label": "NEGATIVE", "score": -1.4}, {"label": "NEUTRAL", "score": 0}]`, 2, "llm")
+	assert.NoError(t, err)
+	assert.Equal(t, -1.0, results[0].Score)
+	assert.Equal(t, "llm", results[1].Analyzer)
+
+	_, err = parseSentimentResults(`[{"label": "NEGATIVE", "score": -0.5}]`, 2, "llm")
+	assert.Error(t, err)
+
+	_, err = parseSentimentResults(`[{"label": "ANGRY", "score": -0.5}]`, 1, "llm")
+	assert.Error(t, err)
+
+	_, err = parseSentimentResults("Mostly positive", 1, "llm")
+	assert.Error(t, err)
+}
+
+func TestAddSentiment(t *testing.T) {
+	batch := &models.InsightBatch{}
+	addSentiment(batch, nil)
+	assert.Nil(t, batch.Sentiment)
+
+	addSentiment(batch, &models.Sentiment{Label: models.SentimentPositive, Score: 0.8})
+	addSentiment(batch, &models.Sentiment{Label: models.SentimentNegative, Score: -0.4})
+	addSentiment(batch, &models.Sentiment{Label: models.SentimentNeutral, Score: 0.2})
+
+	assert.Equal(t, 1, batch.Sentiment.Positive)
+	assert.Equal(t, 1, batch.Sentiment.Negative)
+	assert.Equal(t, 1, batch.Sentiment.Neutral)
+	assert.InDelta(t, 0.2, batch.Sentiment.AverageScore, 1e-9)
+}
+
+func TestSubmissionService_CreateSubmission_EnqueuesSentiment(t *testing.T) {
+	mockSubmissionRepo := new(MockSubmissionRepository)
+	mockSurveyRepo := new(MockSurveyRepository)
+	mockEnqueuer := new(MockJobEnqueuer)
+	service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo, NewSentimentService(mockSubmissionRepo, mockSurveyRepo, nil, mockEnqueuer, nil), nil)
+
+	questionID := bson.NewObjectID()
+	survey := &models.Survey{
+		ID:        bson.NewObjectID(),
+		Token:     "abc",
+		Questions: []models.Question{{ID: questionID, Type: models.QuestionTypeTextbox, Specification: models.QuestionSpecification{TextboxSpecification: &models.TextboxSpecification{MaxLength: 100}}}},
+	}
+	mockSurveyRepo.On("GetByToken", mock.Anything, "abc").Return(survey, nil)
+	mockSubmissionRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	// A failed enqueue does not fail the submission.
+	mockEnqueuer.On("Enqueue", mock.Anything, mock.Anything).Return(nil, errors.New("redis down"))
+
+	submission, err := service.CreateSubmission(context.Background(), &models.CreateSubmissionRequest{
+		SurveyToken: "abc",
+		Responses:   []models.SubmissionResponse{{QuestionID: questionID, Answer: "Great", Sentiment: &models.Sentiment{Label: models.SentimentPositive}}},
+	})
+
+	assert.NoError(t, err)
+	assert.Nil(t, submission.Responses[0].Sentiment)
+	mockEnqueuer.AssertExpectations(t)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"osp/internal/models"
 	"osp/internal/repositories"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -13,19 +15,24 @@ import (
 
 type ISubmissionService interface {
 	CreateSubmission(ctx context.Context, req *models.CreateSubmissionRequest) (*models.Submission, error)
-	GetSubmissions(ctx context.Context, offset int64, limit int64, surveyID *bson.ObjectID) ([]*models.Submission, error)
+	GetSubmissions(ctx context.Context, offset int64, limit int64, surveyID *bson.ObjectID, sentiment *models.SentimentFilter) ([]*models.Submission, error)
+	GetSubmission(ctx context.Context, id bson.ObjectID) (*models.Submission, error)
 	Delete(ctx context.Context, id bson.ObjectID) error
 }
 
 type SubmissionService struct {
 	submissionRepo repositories.SubmissionRepository
 	surveyRepo     repositories.SurveyRepository
+	sentiment      ISentimentService
+	embeddings     IEmbeddingService
 }
 
-func NewSubmissionService(submissionRepo repositories.SubmissionRepository, surveyRepo repositories.SurveyRepository) *SubmissionService {
+func NewSubmissionService(submissionRepo repositories.SubmissionRepository, surveyRepo repositories.SurveyRepository, sentiment ISentimentService, embeddings IEmbeddingService) *SubmissionService {
 	return &SubmissionService{
 		submissionRepo: submissionRepo,
 		surveyRepo:     surveyRepo,
+		sentiment:      sentiment,
+		embeddings:     embeddings,
 	}
 }
 
@@ -77,6 +84,11 @@ func (s *SubmissionService) CreateSubmission(ctx context.Context, req *models.Cr
 		}
 		questionMap[resp.QuestionID] = resp.Answer
 	}
+	for key := range req.Metadata {
+		if err := validateMetadataKey(key); err != nil {
+			return nil, err
+		}
+	}
 	validatedResponses := make([]models.SubmissionResponse, 0, len(survey.Questions))
 	for _, question := range survey.Questions {
 		answer, ok := questionMap[question.ID]
@@ -93,6 +105,7 @@ func (s *SubmissionService) CreateSubmission(ctx context.Context, req *models.Cr
 		ID:        bson.NewObjectID(),
 		SurveyID:  survey.ID,
 		Responses: validatedResponses,
+		Metadata:  req.Metadata,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -100,13 +113,42 @@ func (s *SubmissionService) CreateSubmission(ctx context.Context, req *models.Cr
 	if err != nil {
 		return nil, err
 	}
+	// Sentiment and embeddings are best-effort; the submission is stored either way.
+	if s.sentiment != nil {
+		if err := s.sentiment.EnqueueSubmission(submission.ID); err != nil {
+			log.Printf("failed to enqueue sentiment of submission %s: %v", submission.ID.Hex(), err)
+		}
+	}
+	if s.embeddings != nil {
+		if err := s.embeddings.EnqueueSubmission(submission.ID); err != nil {
+			log.Printf("failed to enqueue embeddings of submission %s: %v", submission.ID.Hex(), err)
+		}
+	}
 	return submission, nil
 }
 
-func (s *SubmissionService) GetSubmissions(ctx context.Context, offset int64, limit int64, surveyID *bson.ObjectID) ([]*models.Submission, error) {
-	return s.submissionRepo.GetSubmissions(ctx, offset, limit, surveyID)
+// validateMetadataKey rejects keys that MongoDB would interpret as paths or operators.
+func validateMetadataKey(key string) error {
+	if key == "" || strings.Contains(key, ".") || strings.HasPrefix(key, "$") {
+		return errors.New("invalid metadata key: " + key)
+	}
+	return nil
+}
+
+func (s *SubmissionService) GetSubmissions(ctx context.Context, offset int64, limit int64, surveyID *bson.ObjectID, sentiment *models.SentimentFilter) ([]*models.Submission, error) {
+	return s.submissionRepo.GetSubmissions(ctx, offset, limit, surveyID, sentiment)
+}
+
+func (s *SubmissionService) GetSubmission(ctx context.Context, id bson.ObjectID) (*models.Submission, error) {
+	return s.submissionRepo.GetByID(ctx, id)
 }
 
 func (s *SubmissionService) Delete(ctx context.Context, id bson.ObjectID) error {
-	return s.submissionRepo.Delete(ctx, id)
+	if err := s.submissionRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if s.embeddings != nil {
+		return s.embeddings.DeleteSubmission(ctx, id)
+	}
+	return nil
 }
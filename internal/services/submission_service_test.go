@@ -22,16 +22,21 @@ func (m *MockSubmissionRepository) Create(ctx context.Context, submission *model
 	return args.Error(0)
 }
 
-func (m *MockSubmissionRepository) GetAllSubmissions(ctx context.Context, surveyID bson.ObjectID) ([]*models.Submission, error) {
-	args := m.Called(ctx, surveyID)
+func (m *MockSubmissionRepository) GetAllSubmissions(ctx context.Context, surveyID bson.ObjectID, filter *models.SubmissionFilter) ([]*models.Submission, error) {
+	args := m.Called(ctx, surveyID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*models.Submission), args.Error(1)
 }
 
-func (m *MockSubmissionRepository) GetSubmissions(ctx context.Context, offset int64, limit int64, surveyID *bson.ObjectID) ([]*models.Submission, error) {
-	args := m.Called(ctx, offset, limit, surveyID)
+func (m *MockSubmissionRepository) Count(ctx context.Context, surveyID bson.ObjectID, filter *models.SubmissionFilter) (int64, error) {
+	args := m.Called(ctx, surveyID, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockSubmissionRepository) GetSubmissions(ctx context.Context, offset int64, limit int64, surveyID *bson.ObjectID, sentiment *models.SentimentFilter) ([]*models.Submission, error) {
+	args := m.Called(ctx, offset, limit, surveyID, sentiment)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -46,6 +51,19 @@ func (m *MockSubmissionRepository) GetBySurveyID(ctx context.Context, surveyID b
 	return args.Get(0).([]models.Submission), args.Error(1)
 }
 
+func (m *MockSubmissionRepository) GetByID(ctx context.Context, id bson.ObjectID) (*models.Submission, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Submission), args.Error(1)
+}
+
+func (m *MockSubmissionRepository) UpdateSentiments(ctx context.Context, id bson.ObjectID, sentiments map[int]*models.Sentiment) error {
+	args := m.Called(ctx, id, sentiments)
+	return args.Error(0)
+}
+
 func (m *MockSubmissionRepository) Delete(ctx context.Context, id bson.ObjectID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -55,7 +73,7 @@ func TestService_CreateSubmission(t *testing.T) {
 	t.Run("SurveyNotFound", func(t *testing.T) {
 		mockSurveyRepo := new(MockSurveyRepository)
 		mockSubmissionRepo := new(MockSubmissionRepository)
-		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo)
+		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo, nil, nil)
 
 		mockSurveyRepo.On("GetByToken", mock.Anything, "invalid").Return(nil, errors.New("not found"))
 
@@ -69,7 +87,7 @@ func TestService_CreateSubmission(t *testing.T) {
 	t.Run("InvalidQuestionID", func(t *testing.T) {
 		mockSurveyRepo := new(MockSurveyRepository)
 		mockSubmissionRepo := new(MockSubmissionRepository)
-		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo)
+		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo, nil, nil)
 
 		surveyID := bson.NewObjectID()
 		survey := &models.Survey{ID: surveyID, Questions: []models.Question{}}
@@ -90,7 +108,7 @@ func TestService_CreateSubmission(t *testing.T) {
 	t.Run("Validation_Textbox_Success", func(t *testing.T) {
 		mockSurveyRepo := new(MockSurveyRepository)
 		mockSubmissionRepo := new(MockSubmissionRepository)
-		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo)
+		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo, nil, nil)
 
 		qID := bson.NewObjectID()
 		survey := &models.Survey{
@@ -123,7 +141,7 @@ func TestService_CreateSubmission(t *testing.T) {
 	t.Run("Validation_Textbox_Fail", func(t *testing.T) {
 		mockSurveyRepo := new(MockSurveyRepository)
 		mockSubmissionRepo := new(MockSubmissionRepository)
-		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo)
+		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo, nil, nil)
 
 		qID := bson.NewObjectID()
 		survey := &models.Survey{
@@ -155,7 +173,7 @@ func TestService_CreateSubmission(t *testing.T) {
 	t.Run("Validation_MultipleChoice_Success", func(t *testing.T) {
 		mockSurveyRepo := new(MockSurveyRepository)
 		mockSubmissionRepo := new(MockSubmissionRepository)
-		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo)
+		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo, nil, nil)
 
 		qID := bson.NewObjectID()
 		survey := &models.Survey{
@@ -189,7 +207,7 @@ func TestService_CreateSubmission(t *testing.T) {
 	t.Run("Validation_MultipleChoice_Fail", func(t *testing.T) {
 		mockSurveyRepo := new(MockSurveyRepository)
 		mockSubmissionRepo := new(MockSubmissionRepository)
-		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo)
+		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo, nil, nil)
 
 		qID := bson.NewObjectID()
 		survey := &models.Survey{
@@ -222,7 +240,7 @@ func TestService_CreateSubmission(t *testing.T) {
 	t.Run("Validation_Likert_Success", func(t *testing.T) {
 		mockSurveyRepo := new(MockSurveyRepository)
 		mockSubmissionRepo := new(MockSubmissionRepository)
-		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo)
+		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo, nil, nil)
 
 		qID := bson.NewObjectID()
 		survey := &models.Survey{
@@ -256,7 +274,7 @@ func TestService_CreateSubmission(t *testing.T) {
 	t.Run("Validation_Likert_Fail", func(t *testing.T) {
 		mockSurveyRepo := new(MockSurveyRepository)
 		mockSubmissionRepo := new(MockSubmissionRepository)
-		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo)
+		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo, nil, nil)
 
 		qID := bson.NewObjectID()
 		survey := &models.Survey{
@@ -289,7 +307,7 @@ func TestService_CreateSubmission(t *testing.T) {
 	t.Run("MissingResponse", func(t *testing.T) {
 		mockSurveyRepo := new(MockSurveyRepository)
 		mockSubmissionRepo := new(MockSubmissionRepository)
-		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo)
+		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo, nil, nil)
 
 		qID1 := bson.NewObjectID()
 		qID2 := bson.NewObjectID()
@@ -328,14 +346,15 @@ func TestService_GetSubmissionsBySurveyID(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockSubmissionRepo := new(MockSubmissionRepository)
 		mockSurveyRepo := new(MockSurveyRepository)
-		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo)
+		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo, nil, nil)
 		surveyID := bson.NewObjectID()
 		expectedSubmissions := []*models.Submission{
 			{ID: bson.NewObjectID()},
 			{ID: bson.NewObjectID()},
 		}
-		mockSubmissionRepo.On("GetSubmissions", mock.Anything, int64(0), int64(10), &surveyID).Return(expectedSubmissions, nil)
-		submissions, err := service.GetSubmissions(context.Background(), 0, 10, &surveyID)
+		sentiment := &models.SentimentFilter{Label: models.SentimentNegative}
+		mockSubmissionRepo.On("GetSubmissions", mock.Anything, int64(0), int64(10), &surveyID, sentiment).Return(expectedSubmissions, nil)
+		submissions, err := service.GetSubmissions(context.Background(), 0, 10, &surveyID, sentiment)
 		assert.NoError(t, err)
 		assert.Equal(t, expectedSubmissions, submissions)
 	})
@@ -345,7 +364,7 @@ func TestService_DeleteSubmission(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockSubmissionRepo := new(MockSubmissionRepository)
 		mockSurveyRepo := new(MockSurveyRepository)
-		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo)
+		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo, nil, nil)
 		submissionID := bson.NewObjectID()
 		mockSubmissionRepo.On("Delete", mock.Anything, submissionID).Return(nil)
 		err := service.Delete(context.Background(), submissionID)
@@ -354,7 +373,7 @@ func TestService_DeleteSubmission(t *testing.T) {
 	t.Run("RepoError", func(t *testing.T) {
 		mockSubmissionRepo := new(MockSubmissionRepository)
 		mockSurveyRepo := new(MockSurveyRepository)
-		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo)
+		service := NewSubmissionService(mockSubmissionRepo, mockSurveyRepo, nil, nil)
 		submissionID := bson.NewObjectID()
 		mockSubmissionRepo.On("Delete", mock.Anything, submissionID).Return(errors.New("db error"))
 		err := service.Delete(context.Background(), submissionID)
@@ -18,6 +18,8 @@ type ISurveyService interface {
 	GetSurveyByToken(ctx context.Context, token string) (*models.Survey, error)
 	GetSurveyByID(ctx context.Context, id bson.ObjectID) (*models.Survey, error)
 	DeleteSurvey(ctx context.Context, id bson.ObjectID) error
+	SetTokenBudget(ctx context.Context, id bson.ObjectID, budget *models.TokenBudget) (*models.Survey, error)
+	SetRedaction(ctx context.Context, id bson.ObjectID, settings *models.RedactionSettings) (*models.Survey, error)
 }
 
 type SurveyService struct {
@@ -32,18 +34,21 @@ func NewSurveyService(repo repositories.SurveyRepository) *SurveyService {
 
 func (s *SurveyService) CreateSurvey(ctx context.Context, req *models.CreateSurveyRequest) (*models.Survey, error) {
 	survey := &models.Survey{
-		ID:        bson.NewObjectID(),
-		Name:      req.Name,
-		Token:     generateRandomToken(5),
-		Questions: make([]models.Question, len(req.Questions)),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:          bson.NewObjectID(),
+		Name:        req.Name,
+		Token:       generateRandomToken(5),
+		Questions:   make([]models.Question, len(req.Questions)),
+		TokenBudget: req.TokenBudget,
+		Redaction:   req.Redaction,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}
 	for i, qInput := range req.Questions {
 		survey.Questions[i] = models.Question{
 			ID:            bson.NewObjectID(),
 			Text:          qInput.Text,
 			Type:          qInput.Type,
+			Section:       strings.TrimSpace(qInput.Section),
 			Specification: qInput.Specification,
 		}
 	}
@@ -70,8 +75,15 @@ func (s *SurveyService) ListSurveys(ctx context.Context, offset, limit int64) ([
 	return s.repo.List(ctx, offset, limit)
 }
 
+// GetSurveyByToken serves respondents, so admin-only settings are left out
 func (s *SurveyService) GetSurveyByToken(ctx context.Context, token string) (*models.Survey, error) {
-	return s.repo.GetByToken(ctx, token)
+	survey, err := s.repo.GetByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	survey.TokenBudget = nil
+	survey.Redaction = nil
+	return survey, nil
 }
 
 func (s *SurveyService) GetSurveyByID(ctx context.Context, id bson.ObjectID) (*models.Survey, error) {
@@ -81,3 +93,20 @@ func (s *SurveyService) GetSurveyByID(ctx context.Context, id bson.ObjectID) (*m
 func (s *SurveyService) DeleteSurvey(ctx context.Context, id bson.ObjectID) error {
 	return s.repo.Delete(ctx, id)
 }
+
+// SetTokenBudget replaces the token budget of a survey; zero limits are unlimited
+func (s *SurveyService) SetTokenBudget(ctx context.Context, id bson.ObjectID, budget *models.TokenBudget) (*models.Survey, error) {
+	if err := s.repo.UpdateTokenBudget(ctx, id, budget); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// SetRedaction replaces the redaction settings of a survey; insights created afterwards
+// apply them, existing insights keep the answers they were redacted with
+func (s *SurveyService) SetRedaction(ctx context.Context, id bson.ObjectID, settings *models.RedactionSettings) (*models.Survey, error) {
+	if err := s.repo.UpdateRedaction(ctx, id, settings); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
@@ -51,6 +51,16 @@ func (m *MockSurveyRepository) Delete(ctx context.Context, id bson.ObjectID) err
 	return args.Error(0)
 }
 
+func (m *MockSurveyRepository) UpdateTokenBudget(ctx context.Context, id bson.ObjectID, budget *models.TokenBudget) error {
+	args := m.Called(ctx, id, budget)
+	return args.Error(0)
+}
+
+func (m *MockSurveyRepository) UpdateRedaction(ctx context.Context, id bson.ObjectID, settings *models.RedactionSettings) error {
+	args := m.Called(ctx, id, settings)
+	return args.Error(0)
+}
+
 func TestService_CreateSurvey(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockRepo := new(MockSurveyRepository)
@@ -107,6 +117,23 @@ func TestService_GetSurveyByToken(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, expectedSurvey, survey)
 	})
+
+	t.Run("HidesAdminSettings", func(t *testing.T) {
+		mockRepo := new(MockSurveyRepository)
+		service := NewSurveyService(mockRepo)
+
+		mockRepo.On("GetByToken", mock.Anything, "abc").Return(&models.Survey{
+			Token:       "abc",
+			TokenBudget: &models.TokenBudget{Daily: 1000},
+			Redaction:   &models.RedactionSettings{Enabled: true, Terms: []string{"Dr Lee"}},
+		}, nil)
+
+		survey, err := service.GetSurveyByToken(context.Background(), "abc")
+
+		assert.NoError(t, err)
+		assert.Nil(t, survey.TokenBudget)
+		assert.Nil(t, survey.Redaction)
+	})
 }
 
 func TestService_GetSurveyByID(t *testing.T) {
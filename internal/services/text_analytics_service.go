@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"osp/internal/models"
+	"osp/internal/repositories"
+	"osp/internal/textanalytics"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ErrInvalidTextAnalytics wraps every error caused by the request rather than by storage.
+var ErrInvalidTextAnalytics = errors.New("invalid text analytics request")
+
+// maxTopics caps the number of topics picked from the answer count
+const maxTopics = 8
+
+// topicExamples is the number of example answers returned per topic
+const topicExamples = 3
+
+type ITextAnalyticsService interface {
+	AnalyzeQuestion(ctx context.Context, surveyID, questionID bson.ObjectID, req *models.TextAnalyticsRequest) (*models.QuestionTextAnalytics, error)
+}
+
+type TextAnalyticsService struct {
+	surveyRepo     repositories.SurveyRepository
+	submissionRepo repositories.SubmissionRepository
+}
+
+func NewTextAnalyticsService(surveyRepo repositories.SurveyRepository, submissionRepo repositories.SubmissionRepository) *TextAnalyticsService {
+	return &TextAnalyticsService{
+		surveyRepo:     surveyRepo,
+		submissionRepo: submissionRepo,
+	}
+}
+
+// AnalyzeQuestion extracts keywords, n-grams and topics from every answer to a
+// TEXTBOX question, without calling the LLM
+func (s *TextAnalyticsService) AnalyzeQuestion(ctx context.Context, surveyID, questionID bson.ObjectID, req *models.TextAnalyticsRequest) (*models.QuestionTextAnalytics, error) {
+	survey, err := s.surveyRepo.GetByID(ctx, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: survey not found", ErrInvalidTextAnalytics)
+	}
+	var question *models.Question
+	for i := range survey.Questions {
+		if survey.Questions[i].ID == questionID {
+			question = &survey.Questions[i]
+			break
+		}
+	}
+	if question == nil {
+		return nil, fmt.Errorf("%w: question %s is not part of the survey", ErrInvalidTextAnalytics, questionID.Hex())
+	}
+	if question.Type != models.QuestionTypeTextbox {
+		return nil, fmt.Errorf("%w: question %s is not a TEXTBOX question", ErrInvalidTextAnalytics, questionID.Hex())
+	}
+
+	submissions, err := s.submissionRepo.GetAllSubmissions(ctx, surveyID, nil)
+	if err != nil {
+		return nil, err
+	}
+	var answers []string
+	for _, submission := range submissions {
+		for _, response := range submission.Responses {
+			if response.QuestionID == questionID && response.Answer != "" {
+				answers = append(answers, response.Answer)
+			}
+		}
+	}
+
+	topics := req.Topics
+	if topics == 0 {
+		topics = textanalytics.SuggestTopics(len(answers), maxTopics)
+	}
+	result := textanalytics.Analyze(answers, textanalytics.Options{
+		Keywords: req.Keywords,
+		NGrams:   req.NGrams,
+		Topics:   topics,
+	})
+	analytics := toTextAnalytics(result)
+	for i, topic := range result.Topics {
+		for _, member := range topic.Members[:min(topicExamples, len(topic.Members))] {
+			analytics.Topics[i].Examples = append(analytics.Topics[i].Examples, answers[member])
+		}
+	}
+	return &models.QuestionTextAnalytics{
+		SurveyID:      surveyID,
+		QuestionID:    questionID,
+		AnswerCount:   len(answers),
+		TextAnalytics: *analytics,
+	}, nil
+}
+
+// toTextAnalytics converts an analysis result without the members of its topics
+func toTextAnalytics(result textanalytics.Result) *models.TextAnalytics {
+	analytics := &models.TextAnalytics{
+		Keywords: make([]models.TextKeyword, len(result.Keywords)),
+		Bigrams:  toTextNGrams(result.Bigrams),
+		Trigrams: toTextNGrams(result.Trigrams),
+		Topics:   make([]models.TextTopic, len(result.Topics)),
+	}
+	for i, keyword := range result.Keywords {
+		analytics.Keywords[i] = models.TextKeyword{Term: keyword.Term, Score: keyword.Score}
+	}
+	for i, topic := range result.Topics {
+		analytics.Topics[i] = models.TextTopic{Keywords: topic.Keywords, Size: len(topic.Members)}
+	}
+	return analytics
+}
+
+func toTextNGrams(grams []textanalytics.NGram) []models.TextNGram {
+	if len(grams) == 0 {
+		return nil
+	}
+	result := make([]models.TextNGram, len(grams))
+	for i, gram := range grams {
+		result[i] = models.TextNGram{Text: gram.Text, Count: gram.Count}
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"osp/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestService_AnalyzeQuestion(t *testing.T) {
+	survey, choiceID, _, textID := crossTabSurvey()
+	answers := []string{
+		"The projector was broken again",
+		"Broken projector and no slides",
+		"Great teacher, very clear examples",
+		"The teacher gave clear examples",
+		"",
+	}
+	var submissions []*models.Submission
+	for _, answer := range answers {
+		submissions = append(submissions, &models.Submission{
+			ID:        bson.NewObjectID(),
+			SurveyID:  survey.ID,
+			Responses: []models.SubmissionResponse{{QuestionID: textID, Answer: answer}},
+		})
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockSurveyRepo := new(MockSurveyRepository)
+		mockSubmissionRepo := new(MockSubmissionRepository)
+		service := NewTextAnalyticsService(mockSurveyRepo, mockSubmissionRepo)
+		mockSurveyRepo.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		mockSubmissionRepo.On("GetAllSubmissions", mock.Anything, survey.ID, mock.Anything).Return(submissions, nil)
+
+		analytics, err := service.AnalyzeQuestion(context.Background(), survey.ID, textID, &models.TextAnalyticsRequest{Keywords: 5, NGrams: 5, Topics: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, analytics.AnswerCount)
+		assert.Len(t, analytics.Keywords, 5)
+		assert.Contains(t, analytics.Bigrams, models.TextNGram{Text: "clear examples", Count: 2})
+		assert.Len(t, analytics.Topics, 2)
+		for _, topic := range analytics.Topics {
+			assert.Equal(t, 2, topic.Size)
+			assert.Len(t, topic.Examples, 2)
+			assert.Empty(t, topic.Answers)
+		}
+	})
+
+	t.Run("SuggestsTopics", func(t *testing.T) {
+		mockSurveyRepo := new(MockSurveyRepository)
+		mockSubmissionRepo := new(MockSubmissionRepository)
+		service := NewTextAnalyticsService(mockSurveyRepo, mockSubmissionRepo)
+		mockSurveyRepo.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		mockSubmissionRepo.On("GetAllSubmissions", mock.Anything, survey.ID, mock.Anything).Return(submissions, nil)
+
+		analytics, err := service.AnalyzeQuestion(context.Background(), survey.ID, textID, &models.TextAnalyticsRequest{Keywords: 5})
+
+		assert.NoError(t, err)
+		assert.Len(t, analytics.Topics, 1)
+		assert.Equal(t, 4, analytics.Topics[0].Size)
+		assert.Len(t, analytics.Topics[0].Examples, topicExamples)
+	})
+
+	t.Run("NotTextbox", func(t *testing.T) {
+		mockSurveyRepo := new(MockSurveyRepository)
+		mockSubmissionRepo := new(MockSubmissionRepository)
+		service := NewTextAnalyticsService(mockSurveyRepo, mockSubmissionRepo)
+		mockSurveyRepo.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+
+		_, err := service.AnalyzeQuestion(context.Background(), survey.ID, choiceID, &models.TextAnalyticsRequest{Keywords: 5})
+
+		assert.ErrorIs(t, err, ErrInvalidTextAnalytics)
+		mockSubmissionRepo.AssertNotCalled(t, "GetAllSubmissions", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("UnknownQuestion", func(t *testing.T) {
+		mockSurveyRepo := new(MockSurveyRepository)
+		service := NewTextAnalyticsService(mockSurveyRepo, new(MockSubmissionRepository))
+		mockSurveyRepo.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+
+		_, err := service.AnalyzeQuestion(context.Background(), survey.ID, bson.NewObjectID(), &models.TextAnalyticsRequest{Keywords: 5})
+
+		assert.ErrorIs(t, err, ErrInvalidTextAnalytics)
+	})
+
+	t.Run("SubmissionsError", func(t *testing.T) {
+		mockSurveyRepo := new(MockSurveyRepository)
+		mockSubmissionRepo := new(MockSubmissionRepository)
+		service := NewTextAnalyticsService(mockSurveyRepo, mockSubmissionRepo)
+		mockSurveyRepo.On("GetByID", mock.Anything, survey.ID).Return(survey, nil)
+		mockSubmissionRepo.On("GetAllSubmissions", mock.Anything, survey.ID, mock.Anything).Return(nil, errors.New("db error"))
+
+		_, err := service.AnalyzeQuestion(context.Background(), survey.ID, textID, &models.TextAnalyticsRequest{Keywords: 5})
+
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrInvalidTextAnalytics)
+	})
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"osp/internal/models"
+	"osp/internal/repositories"
+)
+
+// ErrInvalidUsageRange is returned when a usage report ends before it starts
+var ErrInvalidUsageRange = errors.New("invalid usage range")
+
+// defaultUsageDays is the length of a usage report without a start date
+const defaultUsageDays = 30
+
+type IUsageService interface {
+	GetUsage(ctx context.Context, filter models.UsageFilter) (*models.UsageReport, error)
+}
+
+type UsageService struct {
+	logRepo    repositories.ChatCompletionLogRepository
+	cacheStats CacheStatsReader // nil when the response cache is disabled
+	now        func() time.Time
+}
+
+func NewUsageService(logRepo repositories.ChatCompletionLogRepository, cacheStats CacheStatsReader) *UsageService {
+	return &UsageService{
+		logRepo:    logRepo,
+		cacheStats: cacheStats,
+		now:        time.Now,
+	}
+}
+
+// GetUsage reports LLM usage from From (inclusive) to To (exclusive). A zero To ends
+// the report after today and a zero From starts it defaultUsageDays earlier.
+func (s *UsageService) GetUsage(ctx context.Context, filter models.UsageFilter) (*models.UsageReport, error) {
+	if filter.To.IsZero() {
+		filter.To = s.now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.AddDate(0, 0, -defaultUsageDays)
+	}
+	if !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidUsageRange)
+	}
+
+	totals, err := s.logRepo.UsageTotals(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	daily, err := s.logRepo.DailyUsage(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	surveys, err := s.logRepo.SurveyUsage(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	insights, err := s.logRepo.InsightUsage(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.UsageReport{
+		From:     filter.From,
+		To:       filter.To,
+		Totals:   *totals,
+		Daily:    daily,
+		Surveys:  surveys,
+		Insights: insights,
+	}
+	// The cache is shared by all surveys, so its counters only describe the full report.
+	if s.cacheStats != nil && filter.SurveyID == nil {
+		cache, err := s.cacheStats.CacheStats(ctx, filter.From, filter.To)
+		if err != nil {
+			log.Printf("llm cache statistics unavailable: %v", err)
+		} else {
+			report.Cache = cache
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"osp/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockChatCompletionLogRepository struct {
+	mock.Mock
+}
+
+func (m *MockChatCompletionLogRepository) Create(ctx context.Context, entry *models.ChatCompletionRequestLog) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockChatCompletionLogRepository) Update(ctx context.Context, id bson.ObjectID, update bson.M) error {
+	args := m.Called(ctx, id, update)
+	return args.Error(0)
+}
+
+func (m *MockChatCompletionLogRepository) List(ctx context.Context, filter models.ChatCompletionLogFilter, offset, limit int64) ([]*models.ChatCompletionRequestLog, int64, error) {
+	args := m.Called(ctx, filter, offset, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*models.ChatCompletionRequestLog), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockChatCompletionLogRepository) GetByID(ctx context.Context, id bson.ObjectID) (*models.ChatCompletionRequestLog, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChatCompletionRequestLog), args.Error(1)
+}
+
+func (m *MockChatCompletionLogRepository) UsageTotals(ctx context.Context, filter models.UsageFilter) (*models.UsageTotals, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UsageTotals), args.Error(1)
+}
+
+func (m *MockChatCompletionLogRepository) DailyUsage(ctx context.Context, filter models.UsageFilter) ([]models.DailyUsage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DailyUsage), args.Error(1)
+}
+
+func (m *MockChatCompletionLogRepository) SurveyUsage(ctx context.Context, filter models.UsageFilter) ([]models.SurveyUsage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SurveyUsage), args.Error(1)
+}
+
+func (m *MockChatCompletionLogRepository) InsightUsage(ctx context.Context, filter models.UsageFilter) ([]models.InsightUsage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.InsightUsage), args.Error(1)
+}
+
+func TestUsageService_GetUsage(t *testing.T) {
+	t.Run("DefaultRange", func(t *testing.T) {
+		mockRepo := new(MockChatCompletionLogRepository)
+		service := NewUsageService(mockRepo, nil)
+		service.now = func() time.Time { return time.Date(2026, 3, 15, 10, 30, 0, 0, time.UTC) }
+
+		surveyID := bson.NewObjectID()
+		expected := models.UsageFilter{
+			From:     time.Date(2026, 2, 14, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC),
+			SurveyID: &surveyID,
+		}
+		totals := &models.UsageTotals{Requests: 3, TotalTokens: 1200, Cost: 0.0004}
+		daily := []models.DailyUsage{{Date: "2026-03-15", UsageTotals: *totals}}
+		insights := []models.InsightUsage{{InsightID: bson.NewObjectID(), SurveyID: &surveyID, UsageTotals: *totals}}
+		mockRepo.On("UsageTotals", mock.Anything, expected).Return(totals, nil)
+		mockRepo.On("DailyUsage", mock.Anything, expected).Return(daily, nil)
+		mockRepo.On("SurveyUsage", mock.Anything, expected).Return([]models.SurveyUsage{{SurveyID: surveyID, UsageTotals: *totals}}, nil)
+		mockRepo.On("InsightUsage", mock.Anything, expected).Return(insights, nil)
+
+		report, err := service.GetUsage(context.Background(), models.UsageFilter{SurveyID: &surveyID})
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected.From, report.From)
+		assert.Equal(t, expected.To, report.To)
+		assert.Equal(t, 3, report.Totals.Requests)
+		assert.Equal(t, daily, report.Daily)
+		assert.Equal(t, insights, report.Insights)
+		// The shared cache counters do not describe a single survey.
+		assert.Nil(t, report.Cache)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("CacheStats", func(t *testing.T) {
+		mockRepo := new(MockChatCompletionLogRepository)
+		mockStats := new(MockCacheStatsReader)
+		service := NewUsageService(mockRepo, mockStats)
+		from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)
+		mockRepo.On("UsageTotals", mock.Anything, mock.Anything).Return(&models.UsageTotals{}, nil)
+		mockRepo.On("DailyUsage", mock.Anything, mock.Anything).Return([]models.DailyUsage{}, nil)
+		mockRepo.On("SurveyUsage", mock.Anything, mock.Anything).Return([]models.SurveyUsage{}, nil)
+		mockRepo.On("InsightUsage", mock.Anything, mock.Anything).Return([]models.InsightUsage{}, nil)
+		stats := &models.CacheStats{Hits: 3, Misses: 1, Bypassed: 2, HitRate: 0.75}
+		mockStats.On("CacheStats", mock.Anything, from, to).Return(stats, nil)
+
+		report, err := service.GetUsage(context.Background(), models.UsageFilter{From: from, To: to})
+
+		assert.NoError(t, err)
+		assert.Equal(t, stats, report.Cache)
+	})
+
+	t.Run("CacheStatsUnavailable", func(t *testing.T) {
+		mockRepo := new(MockChatCompletionLogRepository)
+		mockStats := new(MockCacheStatsReader)
+		service := NewUsageService(mockRepo, mockStats)
+		mockRepo.On("UsageTotals", mock.Anything, mock.Anything).Return(&models.UsageTotals{}, nil)
+		mockRepo.On("DailyUsage", mock.Anything, mock.Anything).Return([]models.DailyUsage{}, nil)
+		mockRepo.On("SurveyUsage", mock.Anything, mock.Anything).Return([]models.SurveyUsage{}, nil)
+		mockRepo.On("InsightUsage", mock.Anything, mock.Anything).Return([]models.InsightUsage{}, nil)
+		mockStats.On("CacheStats", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("redis down"))
+
+		report, err := service.GetUsage(context.Background(), models.UsageFilter{})
+
+		assert.NoError(t, err)
+		assert.Nil(t, report.Cache)
+	})
+
+	t.Run("InvalidRange", func(t *testing.T) {
+		mockRepo := new(MockChatCompletionLogRepository)
+		service := NewUsageService(mockRepo, nil)
+
+		day := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+		_, err := service.GetUsage(context.Background(), models.UsageFilter{From: day, To: day})
+
+		assert.ErrorIs(t, err, ErrInvalidUsageRange)
+		mockRepo.AssertNotCalled(t, "UsageTotals", mock.Anything, mock.Anything)
+	})
+
+	t.Run("RepositoryError", func(t *testing.T) {
+		mockRepo := new(MockChatCompletionLogRepository)
+		service := NewUsageService(mockRepo, nil)
+		mockRepo.On("UsageTotals", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		_, err := service.GetUsage(context.Background(), models.UsageFilter{})
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,133 @@
+package stats
+
+import (
+	"math"
+)
+
+// ChiSquareResult is the outcome of a chi-square test of independence on a contingency table.
+type ChiSquareResult struct {
+	Statistic        float64 `bson:"statistic" json:"statistic"`
+	DegreesOfFreedom int     `bson:"degrees_of_freedom" json:"degrees_of_freedom"`
+	PValue           float64 `bson:"p_value" json:"p_value"`
+}
+
+// ChiSquareIndependence runs Pearson's chi-square test of independence.
+// Rows and columns whose total is zero carry no information and are ignored.
+func ChiSquareIndependence(table [][]int) ChiSquareResult {
+	rowTotals := make([]float64, len(table))
+	var colTotals []float64
+	total := 0.0
+	for i, row := range table {
+		if colTotals == nil {
+			colTotals = make([]float64, len(row))
+		}
+		for j, count := range row {
+			rowTotals[i] += float64(count)
+			colTotals[j] += float64(count)
+			total += float64(count)
+		}
+	}
+	if total == 0 {
+		return ChiSquareResult{PValue: 1}
+	}
+
+	statistic := 0.0
+	for i, row := range table {
+		if rowTotals[i] == 0 {
+			continue
+		}
+		for j, count := range row {
+			if colTotals[j] == 0 {
+				continue
+			}
+			expected := rowTotals[i] * colTotals[j] / total
+			diff := float64(count) - expected
+			statistic += diff * diff / expected
+		}
+	}
+
+	df := (nonZero(rowTotals) - 1) * (nonZero(colTotals) - 1)
+	if df <= 0 {
+		return ChiSquareResult{Statistic: statistic, PValue: 1}
+	}
+	return ChiSquareResult{
+		Statistic:        statistic,
+		DegreesOfFreedom: df,
+		PValue:           ChiSquareSurvival(statistic, df),
+	}
+}
+
+// ChiSquareSurvival returns P(X >= x) for a chi-square distribution with df degrees of freedom.
+func ChiSquareSurvival(x float64, df int) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return regularizedGammaQ(float64(df)/2, x/2)
+}
+
+func nonZero(values []float64) int {
+	n := 0
+	for _, v := range values {
+		if v != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// regularizedGammaQ computes the upper regularized incomplete gamma function Q(a, x).
+func regularizedGammaQ(a, x float64) float64 {
+	if x < a+1 {
+		return 1 - gammaSeries(a, x)
+	}
+	return gammaContinuedFraction(a, x)
+}
+
+const (
+	gammaMaxIterations = 500
+	gammaEpsilon       = 1e-14
+)
+
+// gammaSeries evaluates P(a, x) by its series representation.
+func gammaSeries(a, x float64) float64 {
+	lgamma, _ := math.Lgamma(a)
+	sum := 1 / a
+	term := sum
+	for n := 1; n < gammaMaxIterations; n++ {
+		term *= x / (a + float64(n))
+		sum += term
+		if math.Abs(term) < math.Abs(sum)*gammaEpsilon {
+			break
+		}
+	}
+	return sum * math.Exp(-x+a*math.Log(x)-lgamma)
+}
+
+// gammaContinuedFraction evaluates Q(a, x) by Lentz's continued fraction.
+func gammaContinuedFraction(a, x float64) float64 {
+	const tiny = 1e-300
+	lgamma, _ := math.Lgamma(a)
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < gammaMaxIterations; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < gammaEpsilon {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-lgamma) * h
+}
@@ -0,0 +1,38 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChiSquareSurvival(t *testing.T) {
+	assert.InDelta(t, 0.05, ChiSquareSurvival(3.841, 1), 1e-3)
+	assert.InDelta(t, 0.05, ChiSquareSurvival(5.991, 2), 1e-3)
+	assert.InDelta(t, 0.01, ChiSquareSurvival(23.209, 10), 1e-3)
+	assert.Equal(t, 1.0, ChiSquareSurvival(0, 3))
+}
+
+func TestChiSquareIndependence(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		result := ChiSquareIndependence([][]int{{10, 20}, {30, 40}})
+
+		assert.InDelta(t, 0.79365, result.Statistic, 1e-4)
+		assert.Equal(t, 1, result.DegreesOfFreedom)
+		assert.InDelta(t, 0.373, result.PValue, 1e-3)
+	})
+
+	t.Run("IgnoresEmptyRowsAndColumns", func(t *testing.T) {
+		result := ChiSquareIndependence([][]int{{10, 0, 20}, {0, 0, 0}, {30, 0, 40}})
+
+		assert.InDelta(t, 0.79365, result.Statistic, 1e-4)
+		assert.Equal(t, 1, result.DegreesOfFreedom)
+	})
+
+	t.Run("EmptyTable", func(t *testing.T) {
+		result := ChiSquareIndependence([][]int{{0, 0}, {0, 0}})
+
+		assert.Equal(t, 0.0, result.Statistic)
+		assert.Equal(t, 1.0, result.PValue)
+	})
+}
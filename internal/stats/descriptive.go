@@ -0,0 +1,36 @@
+package stats
+
+import "math"
+
+// Summary describes a numeric distribution.
+type Summary struct {
+	Count  int     `bson:"count" json:"count"`
+	Mean   float64 `bson:"mean" json:"mean"`
+	StdDev float64 `bson:"std_dev" json:"std_dev"`
+}
+
+// Summarize computes the count, mean and population standard deviation of a
+// frequency distribution mapping each value to the number of times it occurred.
+func Summarize(frequencies map[float64]int) Summary {
+	count := 0
+	sum := 0.0
+	for value, n := range frequencies {
+		count += n
+		sum += value * float64(n)
+	}
+	if count == 0 {
+		return Summary{}
+	}
+	mean := sum / float64(count)
+
+	variance := 0.0
+	for value, n := range frequencies {
+		diff := value - mean
+		variance += diff * diff * float64(n)
+	}
+	return Summary{
+		Count:  count,
+		Mean:   mean,
+		StdDev: math.Sqrt(variance / float64(count)),
+	}
+}
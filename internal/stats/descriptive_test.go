@@ -0,0 +1,21 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		summary := Summarize(map[float64]int{1: 1, 3: 2, 5: 1})
+
+		assert.Equal(t, 4, summary.Count)
+		assert.Equal(t, 3.0, summary.Mean)
+		assert.InDelta(t, 1.4142, summary.StdDev, 1e-4)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		assert.Equal(t, Summary{}, Summarize(map[float64]int{}))
+	})
+}
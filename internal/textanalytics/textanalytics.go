@@ -0,0 +1,348 @@
+package textanalytics
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+)
+
+// Keyword is a term with its TF-IDF weight summed over all documents
+type Keyword struct {
+	Term  string
+	Score float64
+}
+
+// NGram is a sequence of consecutive tokens and the number of documents containing it
+type NGram struct {
+	Text  string
+	Count int
+}
+
+// Topic is a cluster of documents
+type Topic struct {
+	Keywords []string // highest weighted terms of the centroid
+	Members  []int    // indexes of the documents, closest to the centroid first
+}
+
+// Options limits the result sizes; zero values skip that part of the analysis
+type Options struct {
+	Keywords int
+	NGrams   int // bigrams and trigrams, each
+	Topics   int
+}
+
+type Result struct {
+	Keywords []Keyword
+	Bigrams  []NGram
+	Trigrams []NGram
+	Topics   []Topic
+}
+
+// topicKeywords is the number of keywords describing a topic
+const topicKeywords = 3
+
+// Analyze tokenizes texts and extracts keywords, n-grams and topics
+func Analyze(texts []string, opts Options) Result {
+	docs := make([][]string, len(texts))
+	for i, text := range texts {
+		docs[i] = Tokenize(text)
+	}
+	vectors, vocabulary := TFIDF(docs)
+	var result Result
+	if opts.Keywords > 0 {
+		result.Keywords = Keywords(vectors, vocabulary, opts.Keywords)
+	}
+	if opts.NGrams > 0 {
+		result.Bigrams = NGrams(docs, 2, opts.NGrams)
+		result.Trigrams = NGrams(docs, 3, opts.NGrams)
+	}
+	if opts.Topics > 0 {
+		result.Topics = Cluster(vectors, vocabulary, opts.Topics)
+	}
+	return result
+}
+
+// SuggestTopics picks a number of topics for n documents, from 1 to limit
+func SuggestTopics(n, limit int) int {
+	k := int(math.Round(math.Sqrt(float64(n) / 2)))
+	return min(max(k, 1), limit)
+}
+
+// Vector is a sparse, L2-normalized TF-IDF vector indexed by term ID
+type Vector map[int]float64
+
+// TFIDF weighs the terms of each document by their frequency in the document and
+// their smoothed inverse document frequency. It returns one vector per document and
+// the term of each ID; documents without tokens get an empty vector.
+func TFIDF(docs [][]string) ([]Vector, []string) {
+	ids := make(map[string]int)
+	var vocabulary []string
+	df := []int{}
+	for _, doc := range docs {
+		seen := make(map[int]bool)
+		for _, token := range doc {
+			id, ok := ids[token]
+			if !ok {
+				id = len(vocabulary)
+				ids[token] = id
+				vocabulary = append(vocabulary, token)
+				df = append(df, 0)
+			}
+			if !seen[id] {
+				seen[id] = true
+				df[id]++
+			}
+		}
+	}
+
+	n := float64(len(docs))
+	vectors := make([]Vector, len(docs))
+	for i, doc := range docs {
+		v := make(Vector)
+		for _, token := range doc {
+			v[ids[token]]++
+		}
+		for id, tf := range v {
+			v[id] = tf / float64(len(doc)) * (math.Log((1+n)/(1+float64(df[id]))) + 1)
+		}
+		normalize(v)
+		vectors[i] = v
+	}
+	return vectors, vocabulary
+}
+
+// Keywords returns the n terms with the highest TF-IDF weight over all documents
+func Keywords(vectors []Vector, vocabulary []string, n int) []Keyword {
+	totals := make(map[int]float64)
+	for _, v := range vectors {
+		for id, w := range v {
+			totals[id] += w
+		}
+	}
+	keywords := make([]Keyword, 0, len(totals))
+	for id, score := range totals {
+		keywords = append(keywords, Keyword{Term: vocabulary[id], Score: score})
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Score != keywords[j].Score {
+			return keywords[i].Score > keywords[j].Score
+		}
+		return keywords[i].Term < keywords[j].Term
+	})
+	return keywords[:min(n, len(keywords))]
+}
+
+// NGrams returns the n most common sequences of size tokens that occur in at least
+// two documents, counting each document once
+func NGrams(docs [][]string, size, n int) []NGram {
+	counts := make(map[string]int)
+	for _, doc := range docs {
+		seen := make(map[string]bool)
+		for i := 0; i+size <= len(doc); i++ {
+			gram := strings.Join(doc[i:i+size], " ")
+			if !seen[gram] {
+				seen[gram] = true
+				counts[gram]++
+			}
+		}
+	}
+	var grams []NGram
+	for text, count := range counts {
+		if count > 1 {
+			grams = append(grams, NGram{Text: text, Count: count})
+		}
+	}
+	sort.Slice(grams, func(i, j int) bool {
+		if grams[i].Count != grams[j].Count {
+			return grams[i].Count > grams[j].Count
+		}
+		return grams[i].Text < grams[j].Text
+	})
+	return grams[:min(n, len(grams))]
+}
+
+const kmeansIterations = 50
+
+// Cluster groups the non-empty vectors into at most k topics with spherical k-means
+// (cosine similarity), seeded with k-means++ from a fixed seed so results are
+// reproducible. Topics are returned largest first.
+func Cluster(vectors []Vector, vocabulary []string, k int) []Topic {
+	var points []int
+	for i, v := range vectors {
+		if len(v) > 0 {
+			points = append(points, i)
+		}
+	}
+	k = min(k, len(points))
+	if k < 1 {
+		return nil
+	}
+
+	rng := rand.New(rand.NewPCG(1, uint64(len(points))))
+	centroids := seedCentroids(vectors, points, k, rng)
+	assignment := make([]int, len(points))
+	for iteration := 0; iteration < kmeansIterations; iteration++ {
+		changed := false
+		for p, doc := range points {
+			if best := nearest(vectors[doc], centroids); best != assignment[p] {
+				assignment[p] = best
+				changed = true
+			}
+		}
+		if !changed && iteration > 0 {
+			break
+		}
+		for c := range centroids {
+			centroid := make(Vector)
+			for p, doc := range points {
+				if assignment[p] == c {
+					for id, w := range vectors[doc] {
+						centroid[id] += w
+					}
+				}
+			}
+			// An emptied cluster keeps its previous centroid.
+			if len(centroid) > 0 {
+				normalize(centroid)
+				centroids[c] = centroid
+			}
+		}
+	}
+
+	topics := make([]Topic, len(centroids))
+	for p, doc := range points {
+		topics[assignment[p]].Members = append(topics[assignment[p]].Members, doc)
+	}
+	result := make([]Topic, 0, len(topics))
+	for c, topic := range topics {
+		if len(topic.Members) == 0 {
+			continue
+		}
+		centroid := centroids[c]
+		sort.SliceStable(topic.Members, func(i, j int) bool {
+			return dot(vectors[topic.Members[i]], centroid) > dot(vectors[topic.Members[j]], centroid)
+		})
+		topic.Keywords = topTerms(centroid, vocabulary, topicKeywords)
+		result = append(result, topic)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return len(result[i].Members) > len(result[j].Members) })
+	return result
+}
+
+// seedCentroids picks k distinct points, each next one with a probability
+// proportional to its squared cosine distance from the closest centroid so far
+func seedCentroids(vectors []Vector, points []int, k int, rng *rand.Rand) []Vector {
+	centroids := []Vector{copyVector(vectors[points[rng.IntN(len(points))]])}
+	for len(centroids) < k {
+		distances := make([]float64, len(points))
+		total := 0.0
+		for p, doc := range points {
+			d := 1 - dot(vectors[doc], centroids[nearest(vectors[doc], centroids)])
+			distances[p] = d * d
+			total += distances[p]
+		}
+		if total == 0 {
+			// All remaining points equal a centroid.
+			break
+		}
+		target := rng.Float64() * total
+		chosen := len(points) - 1
+		for p, d := range distances {
+			if target < d {
+				chosen = p
+				break
+			}
+			target -= d
+		}
+		centroids = append(centroids, copyVector(vectors[points[chosen]]))
+	}
+	return centroids
+}
+
+func nearest(v Vector, centroids []Vector) int {
+	best, bestSimilarity := 0, math.Inf(-1)
+	for c, centroid := range centroids {
+		if similarity := dot(v, centroid); similarity > bestSimilarity {
+			best, bestSimilarity = c, similarity
+		}
+	}
+	return best
+}
+
+func topTerms(v Vector, vocabulary []string, n int) []string {
+	ids := make([]int, 0, len(v))
+	for id := range v {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if v[ids[i]] != v[ids[j]] {
+			return v[ids[i]] > v[ids[j]]
+		}
+		return vocabulary[ids[i]] < vocabulary[ids[j]]
+	})
+	terms := make([]string, 0, n)
+	for _, id := range ids[:min(n, len(ids))] {
+		terms = append(terms, vocabulary[id])
+	}
+	return terms
+}
+
+func dot(a, b Vector) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	sum := 0.0
+	for id, w := range a {
+		sum += w * b[id]
+	}
+	return sum
+}
+
+func normalize(v Vector) {
+	norm := math.Sqrt(dot(v, v))
+	if norm == 0 {
+		return
+	}
+	for id := range v {
+		v[id] /= norm
+	}
+}
+
+func copyVector(v Vector) Vector {
+	c := make(Vector, len(v))
+	for id, w := range v {
+		c[id] = w
+	}
+	return c
+}
+
+// Rank returns the indexes of the n texts most similar to query by TF-IDF cosine
+// similarity, most similar first. Texts sharing no term with the query are left out.
+func Rank(query string, texts []string, n int) []int {
+	docs := make([][]string, len(texts)+1)
+	for i, text := range texts {
+		docs[i] = Tokenize(text)
+	}
+	// The query is weighted along with the texts, so its rare terms count most.
+	docs[len(texts)] = Tokenize(query)
+	vectors, _ := TFIDF(docs)
+	q := vectors[len(texts)]
+
+	type match struct {
+		index int
+		score float64
+	}
+	var matches []match
+	for i, v := range vectors[:len(texts)] {
+		if score := dot(v, q); score > 0 {
+			matches = append(matches, match{index: i, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	ranked := make([]int, 0, min(n, len(matches)))
+	for _, m := range matches[:min(n, len(matches))] {
+		ranked = append(ranked, m.index)
+	}
+	return ranked
+}
@@ -0,0 +1,81 @@
+package textanalytics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"pace", "fast", "labs", "great"}, Tokenize("The pace was TOO fast, but the labs were great!"))
+	assert.Equal(t, []string{"老師", "師講", "講解", "解清", "清楚"}, Tokenize("老師講解清楚"))
+	assert.Equal(t, []string{"課程", "有用"}, Tokenize("課程很有用"))
+	assert.Equal(t, []string{"week", "room", "b12"}, Tokenize("Week 5 of 2024, room B12, supercalifragilisticexpialidocious"))
+}
+
+var feedback = []string{
+	"The pace was too fast",
+	"Lectures moved at a fast pace",
+	"Pace too fast for beginners",
+	"Great labs and helpful tutors",
+	"The labs were great",
+	"Helpful tutors in the labs",
+	"!!!",
+}
+
+func TestAnalyze(t *testing.T) {
+	result := Analyze(feedback, Options{Keywords: 3, NGrams: 2, Topics: 2})
+
+	terms := make([]string, len(result.Keywords))
+	for i, keyword := range result.Keywords {
+		terms[i] = keyword.Term
+	}
+	assert.ElementsMatch(t, []string{"pace", "fast", "labs"}, terms)
+	assert.Equal(t, []NGram{{Text: "helpful tutors", Count: 2}, {Text: "pace fast", Count: 2}}, result.Bigrams)
+	assert.Empty(t, result.Trigrams)
+
+	assert.Len(t, result.Topics, 2)
+	var members [][]int
+	for _, topic := range result.Topics {
+		assert.Len(t, topic.Keywords, 3)
+		members = append(members, topic.Members)
+	}
+	// The empty answer is in no topic.
+	assert.ElementsMatch(t, [][]int{{0, 1, 2}, {3, 4, 5}}, sortedEach(members))
+}
+
+func TestCluster_Deterministic(t *testing.T) {
+	docs := make([][]string, len(feedback))
+	for i, text := range feedback {
+		docs[i] = Tokenize(text)
+	}
+	vectors, vocabulary := TFIDF(docs)
+
+	assert.Equal(t, Cluster(vectors, vocabulary, 2), Cluster(vectors, vocabulary, 2))
+	assert.Len(t, Cluster(vectors, vocabulary, 10), 6, "at most one topic per non-empty answer")
+	assert.Nil(t, Cluster(nil, nil, 3))
+}
+
+func TestSuggestTopics(t *testing.T) {
+	assert.Equal(t, 1, SuggestTopics(0, 5))
+	assert.Equal(t, 2, SuggestTopics(8, 5))
+	assert.Equal(t, 5, SuggestTopics(1000, 5))
+}
+
+func sortedEach(groups [][]int) [][]int {
+	for _, g := range groups {
+		for i := 1; i < len(g); i++ {
+			for j := i; j > 0 && g[j] < g[j-1]; j-- {
+				g[j], g[j-1] = g[j-1], g[j]
+			}
+		}
+	}
+	return groups
+}
+
+func TestRank(t *testing.T) {
+	assert.Equal(t, []int{5, 3, 4}, Rank("Were the tutors helpful in labs?", feedback, 5))
+	assert.Equal(t, []int{5}, Rank("helpful tutors", feedback, 1), "shorter answers with the same terms rank higher")
+	assert.Empty(t, Rank("projector", feedback, 5))
+	assert.Empty(t, Rank("the", feedback, 5))
+}
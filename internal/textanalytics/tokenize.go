@@ -0,0 +1,95 @@
+package textanalytics
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxTokenLength drops longer tokens such as URLs, which make poor keywords
+const maxTokenLength = 16
+
+// Tokenize lowercases text and splits it into words without stop words. Runs of
+// Chinese or Japanese characters, which have no spaces, become overlapping character
+// bigrams; bigrams with a stop character are dropped.
+func Tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var cjk []rune
+	flushWord := func() {
+		if len(word) > 1 && len(word) <= maxTokenLength && hasLetter(word) {
+			if w := strings.Trim(string(word), "'"); !stopWords[w] {
+				tokens = append(tokens, w)
+			}
+		}
+		word = word[:0]
+	}
+	flushCJK := func() {
+		for i := 0; i+1 < len(cjk); i++ {
+			if !stopChars[cjk[i]] && !stopChars[cjk[i+1]] {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'':
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r)
+}
+
+func hasLetter(word []rune) bool {
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+var stopWords = toSet(strings.Fields(`
+	a about above after again against all also am an and any are aren't as at be because been
+	before being below between both but by can can't could couldn't did didn't do does doesn't
+	doing don't down during each few for from further get got had hadn't has hasn't have haven't
+	having he her here hers herself him himself his how i i'd i'm i've if in into is isn't it it's
+	its itself just let's me more most mustn't my myself no nor not of off on once only or other
+	ought our ours ourselves out over own same she should shouldn't so some such than that that's
+	the their theirs them themselves then there there's these they they're this those through to
+	too under until up us very was wasn't we we're were weren't what when where which while who
+	whom why will with won't would wouldn't you you're your yours yourself yourselves lot really
+	much many would like also even still well one two get make made thing things
+`))
+
+var stopChars = toRuneSet("的了是我你他她它們们在和也都很就這这那一個个嗎吗呢吧啊")
+
+func toSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+func toRuneSet(chars string) map[rune]bool {
+	set := make(map[rune]bool, utf8.RuneCountInString(chars))
+	for _, r := range chars {
+		set[r] = true
+	}
+	return set
+}
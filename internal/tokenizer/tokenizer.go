@@ -0,0 +1,129 @@
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer counts the tokens a model would see for a text.
+type Tokenizer interface {
+	// Name identifies the tokenizer, so recorded token counts can be reproduced.
+	Name() string
+	Count(text string) int
+}
+
+// Estimator approximates BPE tokenizers such as cl100k / o200k without their
+// vocabularies. Latin words count one token per four characters, punctuation one
+// token per symbol, and CJK, kana and Hangul characters one token each, which
+// slightly overestimates real tokenizers.
+type Estimator struct{}
+
+func NewEstimator() *Estimator {
+	return &Estimator{}
+}
+
+func (e *Estimator) Name() string {
+	return "estimate-v1"
+}
+
+func (e *Estimator) Count(text string) int {
+	tokens := 0
+	word := 0 // runes in the current word
+	flush := func() {
+		tokens += (word + 3) / 4
+		word = 0
+	}
+	for _, r := range text {
+		switch {
+		case isIdeographic(r):
+			flush()
+			tokens++
+		case unicode.IsSpace(r):
+			flush()
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			word++
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+	return tokens
+}
+
+func isIdeographic(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// Split cuts text into pieces of at most budget tokens, preferring to cut between
+// words. Words longer than the budget (e.g. CJK text without spaces) are cut between
+// characters.
+func Split(t Tokenizer, text string, budget int) []string {
+	if budget < 1 || t.Count(text) <= budget {
+		return []string{text}
+	}
+
+	var pieces []string
+	var current strings.Builder
+	currentTokens := 0
+	emit := func() {
+		if piece := strings.TrimSpace(current.String()); piece != "" {
+			pieces = append(pieces, piece)
+		}
+		current.Reset()
+		currentTokens = 0
+	}
+	for _, word := range splitWords(text) {
+		tokens := t.Count(word)
+		if tokens > budget {
+			emit()
+			for _, part := range splitRunes(t, word, budget) {
+				current.WriteString(part)
+				currentTokens = t.Count(part)
+				if currentTokens >= budget {
+					emit()
+				}
+			}
+			continue
+		}
+		if currentTokens+tokens > budget {
+			emit()
+		}
+		current.WriteString(word)
+		currentTokens += tokens
+	}
+	emit()
+	return pieces
+}
+
+// splitWords cuts text after each run of whitespace, keeping the whitespace.
+func splitWords(text string) []string {
+	var words []string
+	start := 0
+	inSpace := false
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if inSpace && !space {
+			words = append(words, text[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	return append(words, text[start:])
+}
+
+// splitRunes cuts a single word into parts of at most budget tokens.
+func splitRunes(t Tokenizer, word string, budget int) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(word); {
+		_, size := utf8.DecodeRuneInString(word[i:])
+		if i > start && t.Count(word[start:i+size]) > budget {
+			parts = append(parts, word[start:i])
+			start = i
+		}
+		i += size
+	}
+	return append(parts, word[start:])
+}
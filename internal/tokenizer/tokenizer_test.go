@@ -0,0 +1,46 @@
+package tokenizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimator_Count(t *testing.T) {
+	e := NewEstimator()
+
+	assert.Equal(t, 0, e.Count(""))
+	assert.Equal(t, 2, e.Count("hello"))
+	assert.Equal(t, 5, e.Count("Great course!"))
+	assert.Equal(t, 5, e.Count("課程很好。"))
+	assert.Equal(t, 4, e.Count("좋아요 ok"))
+}
+
+func TestSplit(t *testing.T) {
+	e := NewEstimator()
+
+	t.Run("FitsBudget", func(t *testing.T) {
+		assert.Equal(t, []string{"short answer"}, Split(e, "short answer", 10))
+	})
+
+	t.Run("Words", func(t *testing.T) {
+		text := strings.Repeat("word ", 10)
+
+		pieces := Split(e, text, 3)
+
+		assert.Len(t, pieces, 4)
+		for _, piece := range pieces {
+			assert.LessOrEqual(t, e.Count(piece), 3)
+		}
+		assert.Equal(t, strings.TrimSpace(text), strings.Join(pieces, " "))
+	})
+
+	t.Run("LongWord", func(t *testing.T) {
+		text := strings.Repeat("好", 25)
+
+		pieces := Split(e, text, 10)
+
+		assert.Equal(t, []string{strings.Repeat("好", 10), strings.Repeat("好", 10), strings.Repeat("好", 5)}, pieces)
+	})
+}